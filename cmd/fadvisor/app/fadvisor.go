@@ -27,6 +27,8 @@ import (
 	_ "github.com/gocrane/fadvisor/pkg/cloudproviders/default"
 	_ "github.com/gocrane/fadvisor/pkg/cloudproviders/qcloud"
 	costcomparator "github.com/gocrane/fadvisor/pkg/cost-comparator"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/comparison"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/report"
 	exporter "github.com/gocrane/fadvisor/pkg/cost-exporter"
 	"github.com/gocrane/fadvisor/pkg/cost-exporter/cloudcost"
 	"github.com/gocrane/fadvisor/pkg/cost-exporter/store/prometheus"
@@ -118,6 +120,18 @@ func RunComparator(ctx context.Context, opts *options.Options) error {
 	if err = cloudProvider.WarmUp(); err != nil {
 		return err
 	}
+	var targetClouds []costcomparator.TargetCloud
+	for i := range opts.ComparatorOptions.Config.TargetProviders {
+		target := &opts.ComparatorOptions.Config.TargetProviders[i]
+		targetCloud, err := cloud.InitCloudProvider(target.CloudConfig(), cloud.NewProviderConfig(&target.Pricing), &k8sCache)
+		if err != nil {
+			return fmt.Errorf("failed to init target provider %v: %v", target.Name, err)
+		}
+		if err = targetCloud.WarmUp(); err != nil {
+			return fmt.Errorf("failed to warm up target provider %v: %v", target.Name, err)
+		}
+		targetClouds = append(targetClouds, costcomparator.TargetCloud{Name: target.Name, Provider: target.Provider, Cloud: targetCloud})
+	}
 	restConfig, err := util.NewK8sConfig(opts.ClientConfig, opts.MaxIdleConnsPerClient)
 	if err != nil {
 		return err
@@ -146,18 +160,56 @@ func RunComparator(ctx context.Context, opts *options.Options) error {
 	_, _, hybrid := initializationDataSource(opts, restConfig)
 
 	fmt.Println(opts.ComparatorOptions.Config)
-	comparator := costcomparator.NewComparator(opts.ComparatorOptions.Config,
-		dynamicKubeClient,
-		discoveryClient,
-		restMapper,
-		targetFetcher,
-		k8sCache,
-		cloudProvider,
-		hybrid)
-
-	comparator.Init()
-	comparator.DoAnalysis()
-	return nil
+	// a comparator is created for each analysis, it caches the data and query window of the analysis
+	newComparator := func() *costcomparator.Comparator {
+		return costcomparator.NewComparator(opts.ComparatorOptions.Config,
+			dynamicKubeClient,
+			discoveryClient,
+			restMapper,
+			targetFetcher,
+			k8sCache,
+			cloudProvider,
+			targetClouds,
+			hybrid)
+	}
+
+	// the prices cached by the analysis are persisted once after it
+	flushClouds := func() {
+		cloudProvider.Flush()
+		for _, targetCloud := range targetClouds {
+			targetCloud.Cloud.Flush()
+		}
+	}
+
+	comparisonOpts := opts.ComparatorOptions
+	if comparisonOpts.Controller || comparisonOpts.CostComparison != "" {
+		analyze := func(ctx context.Context, collector report.Reporter) error {
+			comparator := newComparator()
+			if err := comparator.Init(ctx); err != nil {
+				return err
+			}
+			defer flushClouds()
+			reporter, err := report.NewReporter(comparisonOpts.Config.OutputMode, comparisonOpts.Config.DataPath, comparisonOpts.Config.ClusterId)
+			if err != nil {
+				return err
+			}
+			return comparator.Analyze(report.Multi(reporter, collector))
+		}
+		controller := comparison.NewController(comparison.NewPublisher(dynamicKubeClient, kubeClient), analyze, comparisonOpts.ControllerResync, comparisonOpts.Config.TimeSpanSeconds)
+		if comparisonOpts.Controller {
+			controller.Run(ctx)
+			return nil
+		}
+		namespaceName := strings.Split(comparisonOpts.CostComparison, "/")
+		return controller.RunOnce(ctx, namespaceName[0], namespaceName[1])
+	}
+
+	comparator := newComparator()
+	if err := comparator.Init(ctx); err != nil {
+		return err
+	}
+	defer flushClouds()
+	return comparator.DoAnalysis()
 }
 
 func initializationDataSource(opts *options.Options, restConfig *rest.Config) (datasource.RealTime, datasource.History, datasource.Interface) {
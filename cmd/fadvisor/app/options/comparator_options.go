@@ -1,12 +1,17 @@
 package options
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/gocrane/fadvisor/pkg/cloud"
 	comparatorcfg "github.com/gocrane/fadvisor/pkg/cost-comparator/config"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/estimator"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/report"
 	"github.com/gocrane/fadvisor/pkg/datasource"
 )
 
@@ -20,18 +25,98 @@ type ComparatorOptions struct {
 	DataSourcePromConfig datasource.PromConfig
 	// DataSourceQMonitorConfig is the tencent cloud monitor datasource config
 	DataSourceQMonitorConfig datasource.QCloudMonitorConfig
+	// EstimatorProfilesFile is the json file of the estimator profiles
+	EstimatorProfilesFile string
+	// TargetProvidersFile is the json file of the target providers of the cross provider comparison
+	TargetProvidersFile string
+	// BinPackingCandidatesFile is the json file of the instance types the recommended workloads are packed on besides the cluster ones
+	BinPackingCandidatesFile string
+	// LabelSelector and ExcludeLabelSelector are the label selectors of the pods in and out of the comparator scope
+	LabelSelector        string
+	ExcludeLabelSelector string
+	// Controller runs the comparator as a controller of the CostComparisons
+	Controller       bool
+	ControllerResync time.Duration
+	// CostComparison is the namespace/name of the CostComparison the one-shot run publishes the results to
+	CostComparison string
 }
 
 func NewComparatorOptions() *ComparatorOptions {
-	return &ComparatorOptions{}
+	o := &ComparatorOptions{}
+	o.Config.VpaEstimator = estimator.DefaultVpaEstimatorConfig()
+	return o
 }
 
 func (o *ComparatorOptions) Complete() error {
+	o.Config.DataSource = o.DataSource
+	if o.EstimatorProfilesFile != "" {
+		profiles, err := estimator.LoadProfiles(o.EstimatorProfilesFile)
+		if err != nil {
+			return err
+		}
+		o.Config.EstimatorProfiles = profiles
+	}
+	if o.TargetProvidersFile != "" {
+		targets, err := comparatorcfg.LoadTargetProviders(o.TargetProvidersFile)
+		if err != nil {
+			return err
+		}
+		o.Config.TargetProviders = targets
+	}
+	if o.BinPackingCandidatesFile != "" {
+		candidates, err := comparatorcfg.LoadBinPackingCandidates(o.BinPackingCandidatesFile)
+		if err != nil {
+			return err
+		}
+		o.Config.BinPacking.Candidates = candidates
+	}
+	if o.LabelSelector != "" {
+		selector, err := labels.Parse(o.LabelSelector)
+		if err != nil {
+			return fmt.Errorf("invalid comparator label selector %v: %v", o.LabelSelector, err)
+		}
+		o.Config.Scope.Selector = selector
+	}
+	if o.ExcludeLabelSelector != "" {
+		selector, err := labels.Parse(o.ExcludeLabelSelector)
+		if err != nil {
+			return fmt.Errorf("invalid comparator exclude label selector %v: %v", o.ExcludeLabelSelector, err)
+		}
+		o.Config.Scope.ExcludeSelector = selector
+	}
 	return nil
 }
 
 func (o *ComparatorOptions) Validate() []error {
 	var errors []error
+	switch o.Config.Estimator {
+	case "", comparatorcfg.EstimatorStatistic, comparatorcfg.EstimatorVpa:
+	default:
+		errors = append(errors, fmt.Errorf("unknown comparator estimator %v", o.Config.Estimator))
+	}
+	if _, err := report.ParseOutputMode(o.Config.OutputMode); err != nil {
+		errors = append(errors, err)
+	}
+	switch o.Config.Savings.SortBy {
+	case "", comparatorcfg.SavingsSortBySavings, comparatorcfg.SavingsSortBySavingsRatio, comparatorcfg.SavingsSortByCurrentCost, comparatorcfg.SavingsSortByRecommendedCost:
+	default:
+		errors = append(errors, fmt.Errorf("unknown comparator savings sort by %v", o.Config.Savings.SortBy))
+	}
+	if err := o.Config.Scope.ValidateGlobs(); err != nil {
+		errors = append(errors, err)
+	}
+	if o.Config.Savings.TopN < 0 {
+		errors = append(errors, fmt.Errorf("comparator savings top n must not be negative"))
+	}
+	if o.CostComparison != "" && len(strings.Split(o.CostComparison, "/")) != 2 {
+		errors = append(errors, fmt.Errorf("cost comparison %v is not namespace/name", o.CostComparison))
+	}
+	if o.Controller && o.ControllerResync <= 0 {
+		errors = append(errors, fmt.Errorf("comparator controller resync must be positive"))
+	}
+	if o.Config.VpaEstimator.BucketGrowth <= 0 {
+		errors = append(errors, fmt.Errorf("vpa estimator bucket growth must be positive"))
+	}
 	return errors
 }
 
@@ -46,12 +131,48 @@ func (o *ComparatorOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.Int64Var(&o.Config.TimeSpanSeconds, "comparator-timespan-seconds", 3600, "")
 	fs.StringVar(&o.Config.ClusterName, "comparator-cluster-name", "default", "cluster name the comparator running base on")
 	fs.StringVar(&o.Config.ClusterId, "comparator-cluster-id", "default", "cluster id the comparator running base on")
+	fs.StringVar(&o.Config.ClusterLevel, "comparator-cluster-level", "", "level of the managed cluster the comparator running base on, such as L5, used to price the platform fee. if no specified, the fee is priced by the nodes number")
 	fs.Float64Var(&o.Config.Discount, "comparator-discount", 1.0, "discount used to compute costs")
-	fs.StringVar(&o.Config.OutputMode, "comparator-output-mode", "", "results output mode, includes stdout, csv. if no specified, both will output. including csv file and a table print")
+	fs.StringVar(&o.Config.OutputMode, "comparator-output-mode", "", "results output mode, a comma separated list of stdout, csv, json and html. if no specified, stdout and csv will output. including csv file and a table print")
 	fs.BoolVar(&o.Config.EnableContainerCheckpoint, "comparator-enable-container-ts-checkpoint", false, "enable container time series data checkpoint")
 	fs.BoolVar(&o.Config.EnableWorkloadTimeSeries, "comparator-enable-workload-ts", false, "enable workload time series fetching, it will fetch workload time series data")
 	fs.BoolVar(&o.Config.EnableWorkloadCheckpoint, "comparator-enable-workload-ts-checkpoint", false, "enable workload time series data checkpoint")
+	fs.BoolVar(&o.Config.EnableBatch, "comparator-enable-batch", false, "cost the jobs and cronjobs by the run time of their pods from kube-state-metrics, the monthly cost of a cronjob is projected by its schedule")
+	fs.Float64Var(&o.Config.CheckpointMinCoverage, "comparator-checkpoint-min-coverage", 0.9, "minimal fraction of the history window a checkpoint covers to be reused, the checkpoint of other cluster, datasource or step is always refused")
+	fs.BoolVar(&o.Config.CheckpointIncremental, "comparator-checkpoint-incremental", false, "fetch only the range after the checkpoint end and merge it to the checkpoint, the checkpoint is reused if it overlaps the history window")
+	fs.BoolVar(&o.Controller, "comparator-controller", false, "run the comparator as a controller, it analyzes the cluster for the CostComparisons periodically and stores the results in them")
+	fs.DurationVar(&o.ControllerResync, "comparator-controller-resync", time.Minute, "interval of the comparator controller checking the due CostComparisons")
+	fs.StringVar(&o.CostComparison, "comparator-cost-comparison", "", "namespace/name of the CostComparison the one-shot comparator stores the results in, such as a comparator CronJob")
+	fs.StringSliceVar(&o.Config.Scope.Namespaces, "comparator-namespaces", nil, "globs of the namespaces the comparator analyzes, such as team-*, all if no specified")
+	fs.StringSliceVar(&o.Config.Scope.ExcludeNamespaces, "comparator-exclude-namespaces", nil, "globs of the namespaces the comparator does not analyze, such as kube-*")
+	fs.StringVar(&o.LabelSelector, "comparator-label-selector", "", "label selector of the pods the comparator analyzes, all if no specified")
+	fs.StringVar(&o.ExcludeLabelSelector, "comparator-exclude-label-selector", "", "label selector of the pods the comparator does not analyze")
+	fs.StringSliceVar(&o.Config.Scope.Kinds, "comparator-kinds", nil, "kinds of the workloads the comparator analyzes, such as Deployment,StatefulSet, all if no specified")
+	fs.StringSliceVar(&o.Config.Scope.ExcludeKinds, "comparator-exclude-kinds", nil, "kinds of the workloads the comparator does not analyze")
 	fs.StringVar(&o.Config.DataPath, "comparator-data-path", ".", "data path of the report and checkpoint data stored")
+	fs.IntVar(&o.Config.Fetch.Concurrency, "comparator-fetch-concurrency", 10, "number of workloads or containers whose time series are fetched concurrently")
+	fs.DurationVar(&o.Config.Fetch.QueryTimeout, "comparator-fetch-query-timeout", time.Minute, "timeout of each time series query")
+	fs.IntVar(&o.Config.Fetch.Retries, "comparator-fetch-retries", 2, "retries of a failed time series query")
+	fs.DurationVar(&o.Config.Fetch.RetryBackoff, "comparator-fetch-retry-backoff", time.Second, "initial backoff of the query retries, it doubles every retry")
+	fs.DurationVar(&o.Config.Fetch.Deadline, "comparator-fetch-deadline", 0, "deadline of fetching all the time series, it is shared by the container, workload and batch phases, the analysis uses the partial data fetched if it exceeds. no deadline if zero")
+	fs.DurationVar(&o.Config.Fetch.ProgressInterval, "comparator-fetch-progress-interval", 30*time.Second, "interval of logging the fetching progress")
+	fs.StringVar(&o.Config.Estimator, "comparator-estimator", comparatorcfg.EstimatorStatistic, "estimator of the recommended resources, statistic or vpa. vpa estimates by the decaying histogram same as the vpa recommender")
+	fs.StringVar(&o.EstimatorProfilesFile, "comparator-estimator-profiles-file", "", "json file of the estimator profiles, a profile is selected for each workload by the annotation fadvisor.crane.io/estimator-profile, namespaces or label selector")
+	fs.StringVar(&o.TargetProvidersFile, "comparator-target-providers-file", "", "json file of the target cloud providers, the workloads are priced serverful and serverless on each target side by side with the baseline cloud")
+	fs.BoolVar(&o.Config.BinPacking.Enabled, "comparator-binpacking", false, "simulate packing the recommended workloads onto nodes and report the cheapest node mix, the workloads keep running on nodes instead of serverless")
+	fs.StringVar(&o.BinPackingCandidatesFile, "comparator-binpacking-candidates-file", "", "json file of the candidate instance types of the bin-packing besides the instance types of the cluster nodes")
+	fs.StringVar(&o.Config.Savings.SortBy, "comparator-savings-sort-by", comparatorcfg.SavingsSortBySavings, "column the workloads savings opportunities are sorted by descending, savings, savings-ratio, current-cost or recommended-cost")
+	fs.IntVar(&o.Config.Savings.TopN, "comparator-savings-top-n", 0, "number of the top workloads savings opportunities reported, all if zero")
+	fs.Float64Var(&o.Config.Savings.MinSavings, "comparator-savings-min", 0, "minimal savings of the workloads savings opportunities reported in the time span")
+	fs.Float64Var(&o.Config.Savings.MinSavingsRatio, "comparator-savings-min-ratio", 0, "minimal savings ratio to the current cost of the workloads savings opportunities reported")
+	fs.BoolVar(&o.Config.Sensitivity.Enabled, "comparator-sensitivity", false, "report the recommended cost, savings and risk of each percentile and margin fraction of the grid")
+	fs.Float64SliceVar(&o.Config.Sensitivity.Percentiles, "comparator-sensitivity-percentiles", []float64{0.9, 0.95, 0.99}, "percentiles of the sensitivity matrix")
+	fs.Float64SliceVar(&o.Config.Sensitivity.MarginFractions, "comparator-sensitivity-margin-fractions", []float64{1.0, 1.15, 1.25, 1.5}, "margin fractions of the sensitivity matrix, the percentile is multiplied by it")
+	fs.DurationVar(&o.Config.VpaEstimator.HalfLife, "comparator-vpa-half-life", o.Config.VpaEstimator.HalfLife, "half life of the sample weight of the vpa estimator")
+	fs.Float64Var(&o.Config.VpaEstimator.BucketGrowth, "comparator-vpa-bucket-growth", o.Config.VpaEstimator.BucketGrowth, "growth ratio of the histogram bucket size of the vpa estimator")
+	fs.DurationVar(&o.Config.VpaEstimator.ConfidenceInterval, "comparator-vpa-confidence-interval", o.Config.VpaEstimator.ConfidenceInterval, "history length of confidence 1 of the vpa estimator, the upper bound is widened by 1 + interval / history length")
+	fs.Float64Var(&o.Config.VpaEstimator.MinCpu, "comparator-vpa-min-cpu", o.Config.VpaEstimator.MinCpu, "minimal recommended cpu cores of the vpa estimator")
+	fs.Float64Var(&o.Config.VpaEstimator.MinMemory, "comparator-vpa-min-memory", o.Config.VpaEstimator.MinMemory, "minimal recommended memory bytes of the vpa estimator")
 
 	fs.StringVar(&o.DataSource, "datasource", "prom", "data source of the estimator, prom, qmonitor is available")
 	fs.StringVar(&o.DataSourcePromConfig.Address, "prometheus-address", "", "prometheus address")
@@ -33,6 +33,8 @@ type Options struct {
 	MetricUpdateInterval time.Duration
 
 	CustomPrice cloud.CustomPricing
+	// PlatformFeesFile is the json file of the platform fee models, it overrides the default fee models of the provider
+	PlatformFeesFile string
 
 	ComparatorMode    bool
 	ComparatorOptions *ComparatorOptions
@@ -55,12 +57,28 @@ func NewOptions() *Options {
 
 // Complete completes all the required options.
 func (o *Options) Complete() error {
+	if o.PlatformFeesFile != "" {
+		fees, err := cloud.LoadPlatformFees(o.PlatformFeesFile)
+		if err != nil {
+			return err
+		}
+		o.CustomPrice.PlatformFees = fees
+	}
+	mode, err := cloud.NormalizeNodeOverheadMode(o.CustomPrice.NodeOverheadMode)
+	if err != nil {
+		return err
+	}
+	o.CustomPrice.NodeOverheadMode = mode
 	return o.ComparatorOptions.Complete()
 }
 
 // Validate all required options.
 func (o *Options) Validate() []error {
-	return o.ComparatorOptions.Validate()
+	errs := o.ComparatorOptions.Validate()
+	if err := cloud.ValidatePodAllocationMode(o.CustomPrice.PodAllocationMode); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
 }
 
 func (o *Options) ApplyTo() {
@@ -112,6 +130,10 @@ func (o *Options) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&o.CustomPrice.Provider, "custom-price-provider", "default", "custom pricing config provider")
 	flags.Float64Var(&o.CustomPrice.CpuHourlyPrice, "custom-price-cpu", 0.031611, "cpu hourly unit price of one core")
 	flags.Float64Var(&o.CustomPrice.RamGBHourlyPrice, "custom-price-ram", 0.004237, "ram gb hourly unit price")
+	flags.Float64Var(&o.CustomPrice.WindowsLicenseCoreHourlyPrice, "custom-price-windows-license-core", 0, "windows license hourly unit price of one core, it is added to the default price of windows nodes, the price inquired from the cloud includes the license already")
+	flags.StringVar(&o.CustomPrice.PodAllocationMode, "custom-price-pod-allocation-mode", "request", "how the node cost is allocated to the pods in it, one of request, usage or max. the usage is the average usage in the history window of the comparator, the requests is used if the usage is unknown")
+	flags.StringVar(&o.PlatformFeesFile, "custom-price-platform-fees-file", "", "json file of the managed cluster fee models keyed by platform kind serverful or serverless, it overrides the default fee models of the provider")
+	flags.StringVar(&o.CustomPrice.NodeOverheadMode, "custom-price-node-overhead-mode", "platform", "how the cost of the node reserved resources is priced, platform charges it to the node overhead cost, allocatable inflates the unit prices of the pods")
 
 	flags.BoolVar(&o.ComparatorMode, "comparator-mode", false, "run as fadvisor cost comparator mode, it is an offline analysis tool")
 	o.ComparatorOptions.AddFlags(flags)
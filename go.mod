@@ -11,12 +11,14 @@ require (
 	github.com/olekukonko/tablewriter v0.0.4
 	github.com/prometheus/client_golang v1.11.0
 	github.com/prometheus/common v0.26.0
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/cobra v1.2.1
 	github.com/spf13/pflag v1.0.5
 	github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common v1.0.383
 	github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm v1.0.309
 	github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/monitor v1.0.371
 	github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tke v1.0.383
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 	gopkg.in/gcfg.v1 v1.2.3
 	gopkg.in/warnings.v0 v0.1.2 // indirect
 	k8s.io/api v0.22.3
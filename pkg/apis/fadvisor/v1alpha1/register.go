@@ -0,0 +1,12 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: Version}
+
+	// CostComparisonGVR is used by the dynamic client, the types have no generated clients
+	CostComparisonGVR = SchemeGroupVersion.WithResource(CostComparisonResource)
+)
@@ -0,0 +1,91 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	GroupName = "fadvisor.crane.io"
+	Version   = "v1alpha1"
+
+	CostComparisonKind     = "CostComparison"
+	CostComparisonResource = "costcomparisons"
+)
+
+// CostComparison runs the cost comparator analysis of the cluster on a schedule, the summary is stored in the status
+// and the per-workload results are stored in the referenced ConfigMaps
+type CostComparison struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CostComparisonSpec   `json:"spec,omitempty"`
+	Status CostComparisonStatus `json:"status,omitempty"`
+}
+
+type CostComparisonSpec struct {
+	// Interval is the interval of the analysis in the controller mode, default is 24h
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// Suspend stops the controller analyzing the comparison
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+type CostComparisonPhase string
+
+const (
+	CostComparisonSucceeded CostComparisonPhase = "Succeeded"
+	CostComparisonFailed    CostComparisonPhase = "Failed"
+)
+
+type CostComparisonStatus struct {
+	Phase   CostComparisonPhase `json:"phase,omitempty"`
+	Message string              `json:"message,omitempty"`
+	// LastRunTime is the time of the last analysis, succeeded or not
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+	// LastSuccessfulTime is the time of the last succeeded analysis, the summary and results are of it
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	Summary            *CostSummary `json:"summary,omitempty"`
+	// Results are the ConfigMaps of the per-workload results in the namespace of the comparison
+	Results []ResultReference `json:"results,omitempty"`
+}
+
+// CostSummary is the cost of the cluster in the time span of the analysis
+type CostSummary struct {
+	TimeSpanSeconds int64 `json:"timeSpanSeconds,omitempty"`
+	// Original is the cost of the current cluster
+	Original []Cost `json:"original,omitempty"`
+	// Serverless is the cost of migrating the workloads to serverless directly
+	Serverless []Cost `json:"serverless,omitempty"`
+	// Recommended is the cost of migrating the workloads to serverless by the recommended resources
+	Recommended []RecommendedCost `json:"recommended,omitempty"`
+}
+
+type Cost struct {
+	Type                   string  `json:"type"`
+	TotalCost              float64 `json:"totalCost"`
+	ServerfulCost          float64 `json:"serverfulCost"`
+	ServerlessCost         float64 `json:"serverlessCost"`
+	ServerfulPlatformCost  float64 `json:"serverfulPlatformCost"`
+	ServerlessPlatformCost float64 `json:"serverlessPlatformCost"`
+}
+
+type RecommendedCost struct {
+	Type         string  `json:"type"`
+	TotalCost    float64 `json:"totalCost"`
+	WorkloadCost float64 `json:"workloadCost"`
+	PlatformCost float64 `json:"platformCost"`
+}
+
+// ResultReference is a report section stored in ConfigMaps, a large section is split to several ConfigMaps
+type ResultReference struct {
+	Section    string   `json:"section"`
+	ConfigMaps []string `json:"configMaps"`
+}
+
+type CostComparisonList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []CostComparison `json:"items"`
+}
@@ -23,6 +23,8 @@ type Cache interface {
 	GetDeployments() []*appsv1.Deployment
 	GetPods() []*v1.Pod
 	GetNodes() []*v1.Node
+	// GetNode returns nil if the node is not found
+	GetNode(name string) *v1.Node
 	WaitForCacheSync(stopCh <-chan struct{})
 }
 
@@ -114,3 +116,12 @@ func (c *cache) GetNodes() []*v1.Node {
 	}
 	return nodeList
 }
+
+func (c *cache) GetNode(name string) *v1.Node {
+	node, err := c.nodeLister.Get(name)
+	if err != nil {
+		klog.V(4).Infof("Failed to GetNode %v in cache: %v", name, err)
+		return nil
+	}
+	return node
+}
@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -30,6 +31,8 @@ type Cloud interface {
 type CloudCacher interface {
 	WarmUp() error
 	Refresh()
+	// Flush persists the prices cached since the last flush, if the provider persists its cache
+	Flush()
 }
 
 type PodSpecConverter interface {
@@ -52,6 +55,47 @@ type CustomPricing struct {
 	Description      string  `json:"description"`
 	CpuHourlyPrice   float64 `json:"cpuHourlyPrice"`
 	RamGBHourlyPrice float64 `json:"ramGBHourlyPrice"`
+	// WindowsLicenseCoreHourlyPrice is the windows license price of one core per hour, it is added to the default price of windows nodes,
+	// the price inquired from the cloud includes the license of the image already
+	WindowsLicenseCoreHourlyPrice float64 `json:"windowsLicenseCoreHourlyPrice"`
+	// ArchUnitPrices overrides the cpu and ram unit price by instance family or cpu architecture, key is such as SA3 or arm64
+	ArchUnitPrices map[string]UnitPrice `json:"archUnitPrices,omitempty"`
+	// PodAllocationMode is how the node cost is allocated to the pods in it, request, usage or max, default is request.
+	// the usage is the average usage of the pod in the history window, it is known by the comparator only, the requests is used if it is unknown
+	PodAllocationMode string `json:"podAllocationMode"`
+	// NodeOverheadMode is how the cost of the node resources reserved for system and kubelet is priced, platform or allocatable, default is platform.
+	// capacity is an alias of platform
+	// platform charges the overhead to the platform bucket of the node, allocatable inflates the unit prices of the pods by capacity / allocatable
+	NodeOverheadMode string `json:"nodeOverheadMode"`
+	// PlatformFees overrides the default managed cluster fee model of the provider, key is the platform kind, serverful or serverless
+	PlatformFees map[string]PlatformFeeModel `json:"platformFees,omitempty"`
+}
+
+type UnitPrice struct {
+	CpuHourlyPrice   float64 `json:"cpuHourlyPrice"`
+	RamGBHourlyPrice float64 `json:"ramGBHourlyPrice"`
+}
+
+// UnitPrices return the cpu and ram unit price of the instance family and architecture, the instance family has priority.
+// use the default unit price if neither is configured.
+func (cp *CustomPricing) UnitPrices(family, arch string) (float64, float64) {
+	for _, key := range []string{family, arch} {
+		if key == "" {
+			continue
+		}
+		if price, ok := cp.ArchUnitPrices[key]; ok {
+			return price.CpuHourlyPrice, price.RamGBHourlyPrice
+		}
+	}
+	return cp.CpuHourlyPrice, cp.RamGBHourlyPrice
+}
+
+// LicenseHourlyPrice return the operating system license price per hour of a node with the cpu cores
+func (cp *CustomPricing) LicenseHourlyPrice(os string, cpu float64) float64 {
+	if strings.EqualFold(os, OperatingSystemWindows) {
+		return cp.WindowsLicenseCoreHourlyPrice * cpu
+	}
+	return 0
 }
 
 type PriceConfig struct {
@@ -64,19 +108,52 @@ const (
 	DefaultCloud ProviderKind = "default"
 )
 
+const (
+	OperatingSystemLinux   = "linux"
+	OperatingSystemWindows = "windows"
+
+	ArchAMD64 = "amd64"
+	ArchARM64 = "arm64"
+)
+
 func DetectRegion(node *v1.Node) string {
 	regionStr, _ := util.GetRegion(node.Labels)
 	provider := DetectProvider(node)
 	if provider == TencentCloud {
-		if regionStruct, ok := qcloud.ShortName2region[regionStr]; ok {
-			return regionStruct.Region
-		} else {
-			return ""
-		}
+		region, _ := qcloud.ResolveNodeRegion(node.Name, regionStr)
+		return region
 	}
 	return regionStr
 }
 
+// DetectOperatingSystem return the operating system of the node, default is linux
+func DetectOperatingSystem(node *v1.Node) string {
+	if node == nil {
+		return OperatingSystemLinux
+	}
+	if os, ok := util.GetOperatingSystem(node.Labels); ok && os != "" {
+		return strings.ToLower(os)
+	}
+	if node.Status.NodeInfo.OperatingSystem != "" {
+		return strings.ToLower(node.Status.NodeInfo.OperatingSystem)
+	}
+	return OperatingSystemLinux
+}
+
+// DetectArch return the cpu architecture of the node, default is amd64
+func DetectArch(node *v1.Node) string {
+	if node == nil {
+		return ArchAMD64
+	}
+	if arch, ok := util.GetArch(node.Labels); ok && arch != "" {
+		return strings.ToLower(arch)
+	}
+	if node.Status.NodeInfo.Architecture != "" {
+		return strings.ToLower(node.Status.NodeInfo.Architecture)
+	}
+	return ArchAMD64
+}
+
 func DetectProvider(node *v1.Node) ProviderKind {
 	provider := node.Spec.ProviderID
 	if strings.Contains(provider, "qcloud") {
@@ -98,6 +175,18 @@ func (pc *PriceConfig) UpdateConfigFromConfigMap(priceConf map[string]string) (*
 	defer pc.lock.Unlock()
 	for k, v := range priceConf {
 		kUpper := strings.Title(k)
+		switch kUpper {
+		case "PodAllocationMode":
+			if err := ValidatePodAllocationMode(v); err != nil {
+				return pc.customPricing, err
+			}
+		case "NodeOverheadMode":
+			mode, err := NormalizeNodeOverheadMode(v)
+			if err != nil {
+				return pc.customPricing, err
+			}
+			v = mode
+		}
 		err := SetCustomPricing(pc.customPricing, kUpper, v)
 		if err != nil {
 			return pc.customPricing, err
@@ -127,15 +216,24 @@ func SetCustomPricing(obj *CustomPricing, name string, value string) error {
 
 	structFieldType := structFieldValue.Type()
 	val := reflect.ValueOf(value)
-	if structFieldType != val.Type() {
-		return fmt.Errorf("provided value type didn't match custom pricing field type")
-	}
+	// configmap values are all string, parse the price
 	if structFieldValue.Kind() == reflect.Float64 || structFieldValue.Kind() == reflect.Float32 {
 		t, err := strconv.ParseFloat(value, 64)
 		if err != nil {
 			return err
 		}
-		val = reflect.ValueOf(t)
+		val = reflect.ValueOf(t).Convert(structFieldType)
+	}
+	// map such as ArchUnitPrices is json
+	if structFieldValue.Kind() == reflect.Map {
+		ptr := reflect.New(structFieldType)
+		if err := json.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+			return err
+		}
+		val = ptr.Elem()
+	}
+	if structFieldType != val.Type() {
+		return fmt.Errorf("provided value type didn't match custom pricing field type")
 	}
 	structFieldValue.Set(val)
 	return nil
@@ -0,0 +1,82 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// PlatformFeeTier is the hourly fee of the cluster which manages no more than Nodes nodes, a tier with zero price is free
+type PlatformFeeTier struct {
+	Nodes       int32   `json:"nodes"`
+	PriceHourly float64 `json:"priceHourly"`
+}
+
+// PlatformFeeModel is the managed cluster fee of a platform, such as tke or eks
+type PlatformFeeModel struct {
+	// Tiers is ordered by Nodes ascending, the cluster fee is the first tier which can manage the nodes of the cluster
+	Tiers []PlatformFeeTier `json:"tiers,omitempty"`
+	// ClusterLevels is the hourly fee of the cluster level, such as L5, it has priority over the Tiers if the cluster level is specified
+	ClusterLevels map[string]float64 `json:"clusterLevels,omitempty"`
+	// NodeHourlyPrice is the fee of each node per hour, it is added to the cluster fee
+	NodeHourlyPrice float64 `json:"nodeHourlyPrice,omitempty"`
+}
+
+// Price return the platform fee per hour of the cluster
+func (m *PlatformFeeModel) Price(cp PlatformParameter) *Prices {
+	clusterFee := 0.
+	if level, ok := m.levelPrice(cp.ClusterLevel); ok {
+		clusterFee = level
+	} else if len(m.Tiers) > 0 {
+		clusterFee = m.Tiers[len(m.Tiers)-1].PriceHourly
+		if cp.Nodes == nil {
+			clusterFee = m.Tiers[0].PriceHourly
+		} else {
+			for _, tier := range m.Tiers {
+				if *cp.Nodes <= tier.Nodes {
+					clusterFee = tier.PriceHourly
+					break
+				}
+			}
+		}
+	}
+	nodesFee := 0.
+	if cp.Nodes != nil {
+		nodesFee = m.NodeHourlyPrice * float64(*cp.Nodes)
+	}
+	// the fee model has no discount, the discount price is the total price
+	total := clusterFee + nodesFee
+	return &Prices{
+		TotalPrice:    total,
+		DiscountPrice: &total,
+	}
+}
+
+func (m *PlatformFeeModel) levelPrice(level *string) (float64, bool) {
+	if level == nil || *level == "" {
+		return 0, false
+	}
+	price, ok := m.ClusterLevels[*level]
+	return price, ok
+}
+
+// PlatformFeeModel return the configured fee model of the platform, or the default fee model of the provider if not configured
+func (cp *CustomPricing) PlatformFeeModel(platform PlatformKind, defaultModel PlatformFeeModel) PlatformFeeModel {
+	if model, ok := cp.PlatformFees[string(platform)]; ok {
+		return model
+	}
+	return defaultModel
+}
+
+// LoadPlatformFees load the platform fee models from the json file, key is the platform kind, serverful or serverless
+func LoadPlatformFees(path string) (map[string]PlatformFeeModel, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fees := make(map[string]PlatformFeeModel)
+	if err := json.Unmarshal(content, &fees); err != nil {
+		return nil, fmt.Errorf("failed to parse platform fees %v: %v", path, err)
+	}
+	return fees, nil
+}
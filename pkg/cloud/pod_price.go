@@ -0,0 +1,219 @@
+package cloud
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+
+	"github.com/gocrane/fadvisor/pkg/consts"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+const (
+	PodAllocationRequest = "request"
+	PodAllocationUsage   = "usage"
+	PodAllocationMax     = "max"
+
+	NodeOverheadPlatform    = "platform"
+	NodeOverheadAllocatable = "allocatable"
+	// NodeOverheadCapacity is the former name of NodeOverheadPlatform, it is kept as an alias
+	NodeOverheadCapacity = "capacity"
+)
+
+// ValidatePodAllocationMode returns error if the mode is unknown
+func ValidatePodAllocationMode(mode string) error {
+	switch strings.ToLower(mode) {
+	case "", PodAllocationRequest, PodAllocationUsage, PodAllocationMax:
+		return nil
+	default:
+		return fmt.Errorf("unknown pod allocation mode %v, must be %v, %v or %v", mode, PodAllocationRequest, PodAllocationUsage, PodAllocationMax)
+	}
+}
+
+// NormalizeNodeOverheadMode returns the node overhead mode of the mode or its alias, default is platform. It returns error if the mode is unknown
+func NormalizeNodeOverheadMode(mode string) (string, error) {
+	switch strings.ToLower(mode) {
+	case "", NodeOverheadPlatform, NodeOverheadCapacity:
+		return NodeOverheadPlatform, nil
+	case NodeOverheadAllocatable:
+		return NodeOverheadAllocatable, nil
+	default:
+		return "", fmt.Errorf("unknown node overhead mode %v, must be %v or %v", mode, NodeOverheadPlatform, NodeOverheadAllocatable)
+	}
+}
+
+// NodeOverheadChargedToPods returns true if the node overhead cost is in the pod prices, so it is not a cost of its own
+func (cp *CustomPricing) NodeOverheadChargedToPods() bool {
+	return strings.ToLower(cp.NodeOverheadMode) == NodeOverheadAllocatable
+}
+
+// NodePodSpec convert the pod in the real node to spec, the requests is not rounded to the serverless pod specification
+func NodePodSpec(pod *v1.Pod) spec.CloudPodSpec {
+	reqs, lims := resourcehelper.PodRequestsAndLimits(pod)
+	return spec.CloudPodSpec{
+		PodRef:   pod,
+		Cpu:      reqs[v1.ResourceCPU],
+		Mem:      reqs[v1.ResourceMemory],
+		CpuLimit: lims[v1.ResourceCPU],
+		MemLimit: lims[v1.ResourceMemory],
+		GoodsNum: 1,
+		TimeSpan: 3600,
+		QoSClass: qos.GetPodQOS(pod),
+	}
+}
+
+// PodAllocatedResources return the cpu cores and ram bytes of the pod used to allocate the node cost.
+// the requests is used if the usage is unknown
+func PodAllocatedResources(mode string, podSpec spec.CloudPodSpec) (float64, float64) {
+	cpu := float64(podSpec.Cpu.MilliValue()) / 1000.
+	mem := float64(podSpec.Mem.Value())
+	if podSpec.Usage == nil {
+		return cpu, mem
+	}
+	cpuUsage := float64(podSpec.Usage.Cpu().MilliValue()) / 1000.
+	memUsage := float64(podSpec.Usage.Memory().Value())
+	switch strings.ToLower(mode) {
+	case PodAllocationUsage:
+		return cpuUsage, memUsage
+	case PodAllocationMax:
+		return math.Max(cpu, cpuUsage), math.Max(mem, memUsage)
+	default:
+		return cpu, mem
+	}
+}
+
+// PodPriceOnNode breaks down the node price to the pod by the cpu and ram unit price of the node.
+// the os license cost of the node is allocated by cpu
+func PodPriceOnNode(cfg *CustomPricing, podSpec spec.CloudPodSpec, node *v1.Node, nodePrice *Node) (*Pod, error) {
+	if nodePrice == nil {
+		return nil, fmt.Errorf("node price is null")
+	}
+	cpuHourlyCost, err := parsePrice(nodePrice.CpuHourlyCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cpu hourly cost of node %v: %v", nodePrice.ProviderID, err)
+	}
+	ramGBHourlyCost, err := parsePrice(nodePrice.RamGBHourlyCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ram hourly cost of node %v: %v", nodePrice.ProviderID, err)
+	}
+	license, err := parsePrice(nodePrice.LicenseHourlyCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse license hourly cost of node %v: %v", nodePrice.ProviderID, err)
+	}
+	nodeCpu, err := parsePrice(nodePrice.Cpu)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cpu of node %v: %v", nodePrice.ProviderID, err)
+	}
+	licenseCoreHourlyCost := 0.
+	if nodeCpu > 0 {
+		licenseCoreHourlyCost = license / nodeCpu
+	}
+
+	// the unit price is by capacity, divide the node cost by allocatable so the pods pay for the reserved resources too
+	if node != nil && cfg.NodeOverheadChargedToPods() {
+		cpuRatio := overheadRatio(node, v1.ResourceCPU)
+		ramRatio := overheadRatio(node, v1.ResourceMemory)
+		cpuHourlyCost *= cpuRatio
+		licenseCoreHourlyCost *= cpuRatio
+		ramGBHourlyCost *= ramRatio
+	}
+
+	cpu, ram := PodAllocatedResources(cfg.PodAllocationMode, podSpec)
+	podLicense := licenseCoreHourlyCost * cpu
+	cost := cpuHourlyCost*cpu + ramGBHourlyCost*ram/consts.GB + podLicense
+
+	return &Pod{
+		BaseInstancePrice: BaseInstancePrice{
+			Cost:              fmt.Sprintf("%v", cost),
+			Cpu:               fmt.Sprintf("%v", cpu),
+			CpuHourlyCost:     fmt.Sprintf("%v", cpuHourlyCost),
+			Ram:               fmt.Sprintf("%v", ram/consts.GB),
+			RamBytes:          fmt.Sprintf("%v", ram),
+			RamGBHourlyCost:   fmt.Sprintf("%v", ramGBHourlyCost),
+			UsesDefaultPrice:  nodePrice.UsesDefaultPrice,
+			DefaultCpuPrice:   nodePrice.DefaultCpuPrice,
+			DefaultRamPrice:   nodePrice.DefaultRamPrice,
+			LicenseHourlyCost: fmt.Sprintf("%v", podLicense),
+			UsageType:         nodePrice.UsageType,
+			InstanceType:      nodePrice.InstanceType,
+			Region:            nodePrice.Region,
+			ProviderID:        nodePrice.ProviderID,
+			OS:                nodePrice.OS,
+			Arch:              nodePrice.Arch,
+		},
+	}, nil
+}
+
+// overheadRatio return capacity / allocatable of the resource, 1 if unknown
+func overheadRatio(node *v1.Node, name v1.ResourceName) float64 {
+	capacity, ok := node.Status.Capacity[name]
+	if !ok {
+		return 1
+	}
+	allocatable, ok := node.Status.Allocatable[name]
+	if !ok || allocatable.IsZero() {
+		return 1
+	}
+	return float64(capacity.MilliValue()) / float64(allocatable.MilliValue())
+}
+
+func parsePrice(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(price) {
+		return 0, nil
+	}
+	return price, nil
+}
+
+// NodeOverheadHourlyCost return the cost of the capacity minus allocatable resources of the node by the unit price of the node
+func NodeOverheadHourlyCost(node *v1.Node, nodePrice *Node) float64 {
+	if node == nil || nodePrice == nil || node.Status.Allocatable == nil {
+		return 0
+	}
+	cpuHourlyCost, _ := parsePrice(nodePrice.CpuHourlyCost)
+	ramGBHourlyCost, _ := parsePrice(nodePrice.RamGBHourlyCost)
+	license, _ := parsePrice(nodePrice.LicenseHourlyCost)
+	nodeCpu, _ := parsePrice(nodePrice.Cpu)
+	if nodeCpu > 0 {
+		cpuHourlyCost += license / nodeCpu
+	}
+	cpu := reservedResource(node, v1.ResourceCPU) / 1000.
+	ram := reservedResource(node, v1.ResourceMemory) / 1000. / consts.GB
+	return cpu*cpuHourlyCost + ram*ramGBHourlyCost
+}
+
+// SetNodeOverheadCost fill the OverheadHourlyCost of the node price
+func SetNodeOverheadCost(node *v1.Node, nodePrice *Node) {
+	if nodePrice == nil {
+		return
+	}
+	nodePrice.OverheadHourlyCost = fmt.Sprintf("%v", NodeOverheadHourlyCost(node, nodePrice))
+}
+
+// reservedResource return the milli value of capacity minus allocatable of the resource
+func reservedResource(node *v1.Node, name v1.ResourceName) float64 {
+	capacity, ok := node.Status.Capacity[name]
+	if !ok {
+		return 0
+	}
+	allocatable, ok := node.Status.Allocatable[name]
+	if !ok {
+		return 0
+	}
+	reserved := float64(capacity.MilliValue() - allocatable.MilliValue())
+	if reserved < 0 {
+		return 0
+	}
+	return reserved
+}
@@ -23,7 +23,7 @@ type NodePricer interface {
 type PodPricer interface {
 	// ServerlessPodPrice means this is a serverless pod instance, such as TencentCloud EKS pod, or AliCloud ECI
 	ServerlessPodPrice(spec spec.CloudPodSpec) (*Pod, error)
-	// PodPrice means this pod is in the non-serverless real node. the node is not virtual kubelet. not used now
+	// PodPrice means this pod is in the non-serverless real node. the node is not virtual kubelet, its price is broken down from the node price
 	PodPrice(spec spec.CloudPodSpec) (*Pod, error)
 }
 
@@ -35,7 +35,7 @@ const (
 )
 
 type PlatformParameter struct {
-	// cluster nodes number
+	// cluster nodes number, the real nodes of the serverful platform or the virtual nodes of the serverless platform
 	Nodes        *int32
 	ClusterLevel *string
 	Platform     PlatformKind
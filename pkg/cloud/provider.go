@@ -27,11 +27,13 @@ type PriceItem struct {
 }
 
 type Price struct {
-	InstanceType string     `json:"instanceType"`
-	ChargeType   string     `json:"chargeType"`
-	Memory       string     `json:"memory"`
-	VCpu         string     `json:"vcpu"`
-	CvmPrice     *PriceItem `json:"cvmPrice,omitempty"`
+	InstanceType   string     `json:"instanceType"`
+	InstanceFamily string     `json:"instanceFamily,omitempty"`
+	Arch           string     `json:"arch,omitempty"`
+	ChargeType     string     `json:"chargeType"`
+	Memory         string     `json:"memory"`
+	VCpu           string     `json:"vcpu"`
+	CvmPrice       *PriceItem `json:"cvmPrice,omitempty"`
 }
 
 // cross cloud pricing
@@ -98,11 +100,17 @@ type BaseInstancePrice struct {
 	DefaultCpuPrice string `json:"defaultCpuPrice"`
 	// Used to compute an implicit RAM GB/Hr price when RAM pricing is not provided.
 	DefaultRamPrice string `json:"defaultRamPrice"`
+	// LicenseHourlyCost is the operating system license cost included in Cost, cpu and ram hourly cost exclude it
+	LicenseHourlyCost string `json:"licenseHourlyCost,omitempty"`
+	// OverheadHourlyCost is the cost of the node resources reserved for system, kubelet and eviction, which pods can not use
+	OverheadHourlyCost string `json:"overheadHourlyCost,omitempty"`
 	// Default or ChargeType
 	UsageType    string `json:"usageType"`
 	InstanceType string `json:"instanceType,omitempty"`
 	Region       string `json:"region,omitempty"`
 	ProviderID   string `json:"providerID,omitempty"`
+	OS           string `json:"os,omitempty"`
+	Arch         string `json:"arch,omitempty"`
 }
 
 type Node struct {
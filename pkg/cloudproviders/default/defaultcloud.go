@@ -2,6 +2,7 @@ package defaultcloud
 
 import (
 	"fmt"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
@@ -28,36 +29,121 @@ func NewDefaultCloud(config *cloud.PriceConfig, cache cache.Cache) cloud.Cloud {
 	}
 }
 
+// Pod2ServerlessSpec convert the pod to a serverless pod of its requests, there is no serverless specification to round to by default.
+// daemonset pods are not migrated to serverless, so the number is zero
 func (tc *DefaultCloud) Pod2ServerlessSpec(pod *v1.Pod) spec.CloudPodSpec {
-	panic("implement me")
+	podSpec := cloud.NodePodSpec(pod)
+	refs := pod.GetOwnerReferences()
+	if len(refs) > 0 && strings.ToLower(refs[0].Kind) == "daemonset" {
+		podSpec.GoodsNum = 0
+		return podSpec
+	}
+	podSpec.Serverless = true
+	return podSpec
 }
 
 func (tc *DefaultCloud) NodePrice(spec spec.CloudNodeSpec) (*cloud.Node, error) {
-	panic("implement me")
+	cfg, err := tc.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("provider config is null")
+	}
+	cpu := float64(spec.Cpu.MilliValue()) / 1000.
+	mem := float64(spec.Mem.Value())
+	providerID := ""
+	if spec.NodeRef != nil {
+		providerID = spec.NodeRef.Spec.ProviderID
+	}
+	return defaultNodePrice(cfg, cpu, mem, spec.OS, spec.Arch, spec.InstanceType, providerID), nil
 }
 
+// ServerlessPodPrice prices the serverless pods by the cpu and ram unit price of custom pricing
 func (tc *DefaultCloud) ServerlessPodPrice(spec spec.CloudPodSpec) (*cloud.Pod, error) {
-	panic("implement me")
+	cfg, err := tc.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("provider config is null")
+	}
+	cpu := float64(spec.Cpu.MilliValue()) / 1000.
+	mem := float64(spec.Mem.Value())
+	cost := (cfg.CpuHourlyPrice*cpu + cfg.RamGBHourlyPrice*mem/consts.GB) * float64(spec.GoodsNum)
+	return &cloud.Pod{
+		BaseInstancePrice: cloud.BaseInstancePrice{
+			Cost:             fmt.Sprintf("%v", cost),
+			Cpu:              fmt.Sprintf("%v", cpu),
+			CpuHourlyCost:    fmt.Sprintf("%v", cfg.CpuHourlyPrice),
+			Ram:              fmt.Sprintf("%v", mem/consts.GB),
+			RamBytes:         fmt.Sprintf("%v", mem),
+			RamGBHourlyCost:  fmt.Sprintf("%v", cfg.RamGBHourlyPrice),
+			DefaultCpuPrice:  fmt.Sprintf("%v", cfg.CpuHourlyPrice),
+			DefaultRamPrice:  fmt.Sprintf("%v", cfg.RamGBHourlyPrice),
+			UsesDefaultPrice: true,
+		},
+	}, nil
 }
 
+// PodPrice breaks down the price of the node the pod is scheduled on to the pod
 func (tc *DefaultCloud) PodPrice(spec spec.CloudPodSpec) (*cloud.Pod, error) {
-	panic("implement me")
+	cfg, err := tc.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("provider config is null")
+	}
+	if spec.PodRef == nil {
+		return nil, fmt.Errorf("pod is null")
+	}
+	node := tc.cache.GetNode(spec.PodRef.Spec.NodeName)
+	if node == nil {
+		return nil, fmt.Errorf("node %v of pod %v not found", spec.PodRef.Spec.NodeName, klog.KObj(spec.PodRef))
+	}
+	nodePrice, err := tc.computeNodeBreakdownCost(cfg, node)
+	if err != nil {
+		return nil, err
+	}
+	return cloud.PodPriceOnNode(cfg, spec, node, nodePrice)
 }
 
+// PlatformPrice is free by default, configure the platform fees of custom pricing if the cluster is a managed one
 func (tc *DefaultCloud) PlatformPrice(cp cloud.PlatformParameter) *cloud.Prices {
-	panic("implement me")
+	cfg, err := tc.GetConfig()
+	if err != nil || cfg == nil {
+		return &cloud.Prices{}
+	}
+	model := cfg.PlatformFeeModel(cp.Platform, cloud.PlatformFeeModel{})
+	return model.Price(cp)
 }
 
 func (tc *DefaultCloud) Pod2Spec(pod *v1.Pod) spec.CloudPodSpec {
-	panic("implement me")
+	return cloud.NodePodSpec(pod)
 }
 
 func (tc *DefaultCloud) Node2Spec(node *v1.Node) spec.CloudNodeSpec {
-	panic("implement me")
+	insType, _ := util.GetInstanceType(node.Labels)
+	region, _ := util.GetRegion(node.Labels)
+	zone, _ := util.GetZone(node.Labels)
+	return spec.CloudNodeSpec{
+		NodeRef:        node,
+		Cpu:            node.Status.Capacity[v1.ResourceCPU],
+		Mem:            node.Status.Capacity[v1.ResourceMemory],
+		ChargeType:     "Default",
+		InstanceType:   insType,
+		Zone:           zone,
+		Region:         region,
+		OS:             cloud.DetectOperatingSystem(node),
+		Arch:           cloud.DetectArch(node),
+		InstanceFamily: util.GetInstanceFamily(insType),
+	}
 }
 
+// IsServerlessPod is false, there is no virtual node by default
 func (tc *DefaultCloud) IsServerlessPod(pod *v1.Pod) bool {
-	panic("implement me")
+	return false
 }
 
 func (tc *DefaultCloud) OnNodeDelete(node *v1.Node) error {
@@ -83,6 +169,9 @@ func (tc *DefaultCloud) WarmUp() error {
 func (tc *DefaultCloud) Refresh() {
 }
 
+func (tc *DefaultCloud) Flush() {
+}
+
 // UpdateConfigFromConfigMap update CustomPricing from configmap
 func (tc *DefaultCloud) UpdateConfigFromConfigMap(conf map[string]string) (*cloud.CustomPricing, error) {
 	return tc.priceConfig.UpdateConfigFromConfigMap(conf)
@@ -94,30 +183,41 @@ func (tc *DefaultCloud) GetConfig() (*cloud.CustomPricing, error) {
 }
 
 func (tc *DefaultCloud) getDefaultNodePrice(cfg *cloud.CustomPricing, node *v1.Node) (*cloud.Node, error) {
-	usageType := "Default"
 	insType, _ := util.GetInstanceType(node.Labels)
-	region := cfg.Region
 	cpuCores := node.Status.Capacity[v1.ResourceCPU]
 	memory := node.Status.Capacity[v1.ResourceMemory]
 	cpu := float64(cpuCores.Value())
 	mem := float64(memory.Value())
+	nodePrice := defaultNodePrice(cfg, cpu, mem, cloud.DetectOperatingSystem(node), cloud.DetectArch(node), insType, node.Spec.ProviderID)
+	cloud.SetNodeOverheadCost(node, nodePrice)
+	return nodePrice, nil
+}
+
+// defaultNodePrice use the unit price of cpu and ram of the instance family or arch, plus the os license price of the cores
+func defaultNodePrice(cfg *cloud.CustomPricing, cpu, mem float64, os, arch, insType, providerID string) *cloud.Node {
+	usageType := "Default"
+	license := cfg.LicenseHourlyPrice(os, cpu)
+	cpuPrice, ramPrice := cfg.UnitPrices(util.GetInstanceFamily(insType), arch)
 	return &cloud.Node{
 		BaseInstancePrice: cloud.BaseInstancePrice{
-			Cost:             fmt.Sprintf("%v", cfg.CpuHourlyPrice*cpu+cfg.RamGBHourlyPrice*mem/consts.GB),
-			Cpu:              fmt.Sprintf("%v", cpu),
-			CpuHourlyCost:    fmt.Sprintf("%v", cfg.CpuHourlyPrice),
-			Ram:              fmt.Sprintf("%v", mem/consts.GB),
-			RamBytes:         fmt.Sprintf("%v", mem),
-			RamGBHourlyCost:  fmt.Sprintf("%v", cfg.RamGBHourlyPrice),
-			DefaultCpuPrice:  fmt.Sprintf("%v", cfg.CpuHourlyPrice),
-			DefaultRamPrice:  fmt.Sprintf("%v", cfg.RamGBHourlyPrice),
-			UsageType:        usageType,
-			UsesDefaultPrice: true,
-			InstanceType:     insType,
-			ProviderID:       node.Spec.ProviderID,
-			Region:           region,
+			Cost:              fmt.Sprintf("%v", cpuPrice*cpu+ramPrice*mem/consts.GB+license),
+			Cpu:               fmt.Sprintf("%v", cpu),
+			CpuHourlyCost:     fmt.Sprintf("%v", cpuPrice),
+			Ram:               fmt.Sprintf("%v", mem/consts.GB),
+			RamBytes:          fmt.Sprintf("%v", mem),
+			RamGBHourlyCost:   fmt.Sprintf("%v", ramPrice),
+			DefaultCpuPrice:   fmt.Sprintf("%v", cfg.CpuHourlyPrice),
+			DefaultRamPrice:   fmt.Sprintf("%v", cfg.RamGBHourlyPrice),
+			LicenseHourlyCost: fmt.Sprintf("%v", license),
+			UsageType:         usageType,
+			UsesDefaultPrice:  true,
+			InstanceType:      insType,
+			ProviderID:        providerID,
+			Region:            cfg.Region,
+			OS:                os,
+			Arch:              arch,
 		},
-	}, nil
+	}
 }
 
 func (tc *DefaultCloud) GetNodesCost() (map[string]*cloud.Node, error) {
@@ -162,21 +262,33 @@ func (tc *DefaultCloud) GetPodsCost() (map[string]*cloud.Pod, error) {
 	for _, node := range nodeList {
 		nodesMap[node.Name] = node
 	}
+	// node breakdown price is shared by the pods in it
+	nodePrices := make(map[string]*cloud.Node)
 	podList := tc.cache.GetPods()
 	for _, pod := range podList {
 		key := klog.KObj(pod).String()
 
 		nodeName := pod.Spec.NodeName
-		node := nodesMap[nodeName]
+		node, ok := nodesMap[nodeName]
+		if !ok {
+			klog.V(4).Infof("pod is not scheduled or its node is not found, pod: %v, node: %v", klog.KObj(pod), nodeName)
+			continue
+		}
 
-		nodePrice, err := tc.computeNodeBreakdownCost(cfg, node)
+		nodePrice, ok := nodePrices[nodeName]
+		if !ok {
+			nodePrice, err = tc.computeNodeBreakdownCost(cfg, node)
+			if err != nil {
+				klog.Errorf("Failed to computeNodeBreakdownCost pod: %v, node: %v", klog.KObj(pod), klog.KObj(node))
+				continue
+			}
+			nodePrices[nodeName] = nodePrice
+		}
+		podPrice, err := cloud.PodPriceOnNode(cfg, cloud.NodePodSpec(pod), node, nodePrice)
 		if err != nil {
-			klog.Errorf("Failed to computeNodeBreakdownCost pod: %v, node: %v", klog.KObj(pod), klog.KObj(node))
+			klog.Errorf("Failed to compute pod price pod: %v, node: %v: %v", klog.KObj(pod), klog.KObj(node), err)
 			continue
 		}
-		podPrice := &cloud.Pod{
-			BaseInstancePrice: nodePrice.BaseInstancePrice,
-		}
 		pods[key] = podPrice
 	}
 	return pods, nil
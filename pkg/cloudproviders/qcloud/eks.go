@@ -14,8 +14,13 @@ type Pod2EKSSpecConverter interface {
 type EKSPlatform struct {
 }
 
-// no platform cost now for eks
-func (ep *EKSPlatform) PlatformCost(cp cloud.PlatformParameter) *cloud.Prices {
+// no platform cost for eks by default
+func (ep *EKSPlatform) PlatformCost(cfg *cloud.CustomPricing, cp cloud.PlatformParameter) *cloud.Prices {
+	if cfg != nil {
+		if model, ok := cfg.PlatformFees[string(cloud.ServerlessKind)]; ok {
+			return model.Price(cp)
+		}
+	}
 	return &cloud.Prices{
 		TotalPrice:    0,
 		DiscountPrice: pointer.Float64(0),
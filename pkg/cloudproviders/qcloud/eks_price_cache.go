@@ -0,0 +1,304 @@
+package qcloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tke "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tke/v20180525"
+	"golang.org/x/sync/singleflight"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/consts"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+const (
+	defaultEKSPriceCacheTTL = 24 * time.Hour
+	// eksPriceCacheFlushInterval is the delay of persisting the new prices, the prices set in the interval are written once
+	eksPriceCacheFlushInterval = time.Minute
+)
+
+// eksPriceKey is the normalised spec of one eks pod, the price is linear to the pods number so GoodsNum is not part of the key
+type eksPriceKey struct {
+	Cpu float64 `json:"cpu"`
+	// Mem is in GB
+	Mem float64 `json:"mem"`
+	// Type is the cpu type or gpu type
+	Type     string  `json:"type,omitempty"`
+	Gpu      float64 `json:"gpu,omitempty"`
+	Zone     string  `json:"zone,omitempty"`
+	PodType  string  `json:"podType,omitempty"`
+	TimeSpan uint64  `json:"timeSpan"`
+}
+
+func newEKSPriceKey(spec spec.CloudPodSpec) eksPriceKey {
+	return eksPriceKey{
+		Cpu:      roundSpec(float64(spec.Cpu.MilliValue()) / 1000.),
+		Mem:      roundSpec(float64(spec.Mem.Value()) / consts.GB),
+		Type:     spec.MachineArch,
+		Gpu:      roundSpec(float64(spec.Gpu.MilliValue()) / 1000.),
+		Zone:     spec.Zone,
+		PodType:  spec.PodChargeType,
+		TimeSpan: spec.TimeSpan,
+	}
+}
+
+// roundSpec drops the float error of the quantity conversion, such as 0.2500000001
+func roundSpec(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+// request return the price request of one pod with the spec
+func (k eksPriceKey) request() *tke.GetPriceRequest {
+	req := tke.NewGetPriceRequest()
+	cpu, mem, timeSpan, goodsNum := k.Cpu, k.Mem, k.TimeSpan, uint64(1)
+	req.Cpu = &cpu
+	req.Mem = &mem
+	req.TimeSpan = &timeSpan
+	req.GoodsNum = &goodsNum
+	if k.Type != "" {
+		t := k.Type
+		req.Type = &t
+	}
+	if k.Gpu != 0 {
+		gpu := k.Gpu
+		req.Gpu = &gpu
+	}
+	if k.PodType != "" {
+		podType := k.PodType
+		req.PodType = &podType
+	}
+	if k.Zone != "" {
+		zone := k.Zone
+		req.Zone = &zone
+	}
+	return req
+}
+
+// eksPriceEntry is the price of one pod, unit is cent
+type eksPriceEntry struct {
+	Key       eksPriceKey `json:"key"`
+	Cost      float64     `json:"cost"`
+	TotalCost float64     `json:"totalCost"`
+	Expire    time.Time   `json:"expire"`
+}
+
+// eksSpecEntry is the eks specification the api converted, key is the cpu type and resource requirements of the containers
+type eksSpecEntry struct {
+	Key    string            `json:"key"`
+	Cpu    resource.Quantity `json:"cpu"`
+	Mem    resource.Quantity `json:"mem"`
+	Expire time.Time         `json:"expire"`
+}
+
+// eksPriceCacheFile is the content of the cache file, the file of the early versions is a list of the prices
+type eksPriceCacheFile struct {
+	Prices []*eksPriceEntry `json:"prices"`
+	Specs  []*eksSpecEntry  `json:"specs"`
+}
+
+// EKSPriceCache memoises the eks pod prices by the normalised spec, because the comparator prices a lot of workloads with the same spec.
+// It is the store of the eks specifications the api converted too, see sdktke.EKSSpecStore.
+// The entries are persisted to the file if it is specified, so the later runs can reuse them before they expire.
+// The new entries are flushed to the file by a timer or by Flush, the concurrent lookups of the same missed key are coalesced.
+type EKSPriceCache struct {
+	lock       sync.Mutex
+	ttl        time.Duration
+	file       string
+	entries    map[eksPriceKey]*eksPriceEntry
+	specs      map[string]*eksSpecEntry
+	hits       int
+	misses     int
+	dirty      bool
+	flushTimer *time.Timer
+	inflight   singleflight.Group
+}
+
+// NewEKSPriceCache return a cache with the ttl, default ttl is used if ttl is not positive. entries are loaded from the file if it exists
+func NewEKSPriceCache(ttl time.Duration, file string) *EKSPriceCache {
+	if ttl <= 0 {
+		ttl = defaultEKSPriceCacheTTL
+	}
+	c := &EKSPriceCache{
+		ttl:     ttl,
+		file:    file,
+		entries: make(map[eksPriceKey]*eksPriceEntry),
+		specs:   make(map[string]*eksSpecEntry),
+	}
+	if file != "" {
+		if err := c.load(); err != nil {
+			klog.Warningf("Failed to load eks price cache %v: %v", file, err)
+		}
+	}
+	return c
+}
+
+func (c *EKSPriceCache) Get(key eksPriceKey) (*eksPriceEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.Expire) {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return entry, true
+}
+
+func (c *EKSPriceCache) Set(key eksPriceKey, cost, totalCost float64) *eksPriceEntry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry := &eksPriceEntry{Key: key, Cost: cost, TotalCost: totalCost, Expire: time.Now().Add(c.ttl)}
+	c.entries[key] = entry
+	c.markDirty()
+	return entry
+}
+
+// GetSpec return the eks specification of the key the api converted
+func (c *EKSPriceCache) GetSpec(key string) (v1.ResourceList, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, ok := c.specs[key]
+	if !ok || time.Now().After(entry.Expire) {
+		return nil, false
+	}
+	return v1.ResourceList{v1.ResourceCPU: entry.Cpu.DeepCopy(), v1.ResourceMemory: entry.Mem.DeepCopy()}, true
+}
+
+func (c *EKSPriceCache) SetSpec(key string, spec v1.ResourceList) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.specs[key] = &eksSpecEntry{Key: key, Cpu: spec.Cpu().DeepCopy(), Mem: spec.Memory().DeepCopy(), Expire: time.Now().Add(c.ttl)}
+	c.markDirty()
+}
+
+// markDirty schedules a flush if the file is specified, the caller should hold the lock
+func (c *EKSPriceCache) markDirty() {
+	if c.file == "" {
+		return
+	}
+	c.dirty = true
+	if c.flushTimer == nil {
+		c.flushTimer = time.AfterFunc(eksPriceCacheFlushInterval, func() {
+			if err := c.Flush(); err != nil {
+				klog.Warningf("Failed to save eks price cache %v: %v", c.file, err)
+			}
+		})
+	}
+}
+
+// GetOrFetch return the cached price of the key, or the price fetched by fetch if missed. the concurrent fetches of a key share one call
+func (c *EKSPriceCache) GetOrFetch(key eksPriceKey, fetch func() (float64, float64, error)) (*eksPriceEntry, error) {
+	if entry, ok := c.Get(key); ok {
+		return entry, nil
+	}
+	v, err, _ := c.inflight.Do(fmt.Sprintf("%+v", key), func() (interface{}, error) {
+		cost, totalCost, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		return c.Set(key, cost, totalCost), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*eksPriceEntry), nil
+}
+
+// Flush writes the entries to the file if any is set since the last flush
+func (c *EKSPriceCache) Flush() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.flushTimer != nil {
+		c.flushTimer.Stop()
+		c.flushTimer = nil
+	}
+	if !c.dirty || c.file == "" {
+		return nil
+	}
+	if err := c.save(); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// Stats return the hits and misses of the cache
+func (c *EKSPriceCache) Stats() (int, int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *EKSPriceCache) load() error {
+	content, err := ioutil.ReadFile(c.file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var cacheFile eksPriceCacheFile
+	if err := json.Unmarshal(content, &cacheFile); err != nil {
+		if legacyErr := json.Unmarshal(content, &cacheFile.Prices); legacyErr != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	for _, entry := range cacheFile.Prices {
+		if now.Before(entry.Expire) {
+			c.entries[entry.Key] = entry
+		}
+	}
+	for _, entry := range cacheFile.Specs {
+		if now.Before(entry.Expire) {
+			c.specs[entry.Key] = entry
+		}
+	}
+	klog.V(4).Infof("Loaded %v eks prices and %v eks specs from %v", len(c.entries), len(c.specs), c.file)
+	return nil
+}
+
+// save writes a temp file and renames it, so a crash will not leave a broken cache file
+func (c *EKSPriceCache) save() error {
+	cacheFile := eksPriceCacheFile{
+		Prices: make([]*eksPriceEntry, 0, len(c.entries)),
+		Specs:  make([]*eksSpecEntry, 0, len(c.specs)),
+	}
+	for _, entry := range c.entries {
+		cacheFile.Prices = append(cacheFile.Prices, entry)
+	}
+	for _, entry := range c.specs {
+		cacheFile.Specs = append(cacheFile.Specs, entry)
+	}
+	content, err := json.Marshal(cacheFile)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(c.file), filepath.Base(c.file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.file); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to rename %v: %v", tmp.Name(), err)
+	}
+	return nil
+}
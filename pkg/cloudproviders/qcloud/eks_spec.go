@@ -0,0 +1,164 @@
+package qcloud
+
+import (
+	"fmt"
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+
+	"github.com/gocrane/fadvisor/pkg/consts"
+)
+
+// EKSSpecRow is the memory GiB options of the eks pod with the cpu cores
+type EKSSpecRow struct {
+	Cpu  float64
+	Mems []float64
+}
+
+// EKSSpecLadder is the eks pod specifications of each cpu type, the rows are ordered by cpu ascending
+type EKSSpecLadder map[string][]EKSSpecRow
+
+// memRange return the memory options from min to max by step
+func memRange(min, max, step float64) []float64 {
+	var mems []float64
+	for m := min; m <= max; m += step {
+		mems = append(mems, m)
+	}
+	return mems
+}
+
+// memRatios return the memory options of the cpu by the memory GiB per core
+func memRatios(cpu float64, ratios ...float64) []float64 {
+	var mems []float64
+	for _, r := range ratios {
+		mems = append(mems, cpu*r)
+	}
+	return mems
+}
+
+var (
+	// https://cloud.tencent.com/document/product/457/44174
+	defaultEKSSpecLadder = EKSSpecLadder{
+		EKSCpuTypeValue_Intel: {
+			{0.25, []float64{0.5, 1, 2}},
+			{0.5, []float64{1, 2, 3, 4}},
+			{1, memRange(1, 8, 1)},
+			{2, memRange(4, 16, 1)},
+			{4, memRange(8, 32, 1)},
+			{8, memRange(16, 32, 1)},
+			{12, memRange(24, 48, 1)},
+			{16, memRange(32, 64, 1)},
+			{32, memRange(64, 128, 1)},
+		},
+		"amd": {
+			{1, memRatios(1, 1, 2, 4, 8)},
+			{2, memRatios(2, 1, 2, 4, 8)},
+			{4, memRatios(4, 1, 2, 4, 8)},
+			{8, memRatios(8, 1, 2, 4, 8)},
+			{16, memRatios(16, 1, 2, 4, 8)},
+			{32, memRatios(32, 1, 2, 4, 8)},
+			{64, memRatios(64, 1, 2, 4)},
+		},
+	}
+)
+
+// defaultEKSMemPerCore is the memory GiB per core if the pod do not specify the memory
+const defaultEKSMemPerCore = 2
+
+// EKSSpecConverter rounds the resources of the pod up to the eks specification by the spec ladder locally, so it works offline.
+// The api GetPodSpecification converts the pods the ladder can not, such as the gpu pods, if api is specified.
+// If validate is true, the api is used to validate the local result too, the api result is used if they are different.
+type EKSSpecConverter struct {
+	ladder   EKSSpecLadder
+	api      Pod2EKSSpecConverter
+	validate bool
+}
+
+// NewEKSSpecConverter return a converter with the default ladder, api is optional
+func NewEKSSpecConverter(api Pod2EKSSpecConverter, validate bool) *EKSSpecConverter {
+	return &EKSSpecConverter{
+		ladder:   defaultEKSSpecLadder,
+		api:      api,
+		validate: validate,
+	}
+}
+
+func (c *EKSSpecConverter) Pod2EKSSpecConverter(pod *v1.Pod) (v1.ResourceList, error) {
+	spec, err := c.convert(pod)
+	if c.api == nil || (err == nil && !c.validate) {
+		return spec, err
+	}
+	apiSpec, apiErr := c.api.Pod2EKSSpecConverter(pod)
+	if err != nil {
+		if apiErr != nil {
+			return nil, fmt.Errorf("%v, and failed to convert by api: %v", err, apiErr)
+		}
+		klog.V(4).Infof("Failed to convert eks spec of pod %v locally, use the api spec: %v", klog.KObj(pod), err)
+		return apiSpec, nil
+	}
+	if apiErr != nil {
+		klog.Warningf("Failed to validate eks spec of pod %v by api, use the local spec: %v", klog.KObj(pod), apiErr)
+		return spec, nil
+	}
+	if apiSpec.Cpu().Cmp(*spec.Cpu()) != 0 || apiSpec.Memory().Cmp(*spec.Memory()) != 0 {
+		klog.Warningf("EKS spec of pod %v is different, local: %v/%v, api: %v/%v, use the api spec", klog.KObj(pod),
+			spec.Cpu().String(), spec.Memory().String(), apiSpec.Cpu().String(), apiSpec.Memory().String())
+	}
+	return apiSpec, nil
+}
+
+func (c *EKSSpecConverter) convert(pod *v1.Pod) (v1.ResourceList, error) {
+	// the specified eks spec has priority
+	if ok, cpu := EKSPodCpuValue(pod); ok {
+		if ok, mem := EKSPodMemValue(pod); ok {
+			cpuQuantity, err := resource.ParseQuantity(cpu)
+			if err != nil {
+				return nil, fmt.Errorf("invalid eks cpu annotation %v: %v", cpu, err)
+			}
+			memQuantity, err := resource.ParseQuantity(mem)
+			if err != nil {
+				return nil, fmt.Errorf("invalid eks mem annotation %v: %v", mem, err)
+			}
+			return v1.ResourceList{v1.ResourceCPU: cpuQuantity, v1.ResourceMemory: memQuantity}, nil
+		}
+	}
+
+	cpuType := EKSPodCpuType(pod)
+	if cpuType == "" {
+		cpuType = EKSCpuTypeValue_Intel
+	}
+	if ok, gpuType := EKSPodGpuType(pod); ok {
+		return nil, fmt.Errorf("no spec ladder of gpu type %v", gpuType)
+	}
+	rows, ok := c.ladder[cpuType]
+	if !ok {
+		return nil, fmt.Errorf("no spec ladder of cpu type %v", cpuType)
+	}
+
+	// the larger one of requests and limits
+	reqs, lims := resourcehelper.PodRequestsAndLimits(pod)
+	cpu := math.Max(float64(reqs.Cpu().MilliValue()), float64(lims.Cpu().MilliValue())) / 1000.
+	mem := math.Max(float64(reqs.Memory().Value()), float64(lims.Memory().Value())) / consts.GB
+
+	for _, row := range rows {
+		if row.Cpu < cpu {
+			continue
+		}
+		need := mem
+		if need == 0 {
+			need = row.Cpu * defaultEKSMemPerCore
+		}
+		for _, m := range row.Mems {
+			if m >= need {
+				return v1.ResourceList{
+					v1.ResourceCPU:    *resource.NewMilliQuantity(int64(row.Cpu*1000), resource.DecimalSI),
+					v1.ResourceMemory: *resource.NewQuantity(int64(m*consts.GB), resource.BinarySI),
+				}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no eks spec of cpu type %v can hold cpu %v and mem %vGiB", cpuType, cpu, mem)
+}
@@ -137,6 +137,13 @@ func (pc *TencentCloud) Refresh() {
 	return
 }
 
+// Flush persists the eks pod prices cached since the last flush
+func (tc *TencentCloud) Flush() {
+	if err := tc.eksPriceCache.Flush(); err != nil {
+		klog.Warningf("Failed to save eks price cache %v: %v", tc.eksPriceCache.file, err)
+	}
+}
+
 func (pc *TencentCloud) GetInstancePrice(instanceid string) *sdkcvm.QCloudInstancePrice {
 	pc.instanceLock.RLock()
 	defer pc.instanceLock.RUnlock()
@@ -10,12 +10,12 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
 
 	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
-	tke "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/tke/v20180525"
 
 	"github.com/gocrane/fadvisor/pkg/cache"
 	qcloudsdk "github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud"
@@ -31,14 +31,85 @@ import (
 type CloudConfig struct {
 	Credentials   `name:"credentials" value:"optional"`
 	ClientProfile `name:"clientProfile" value:"optional"`
+	// RegionOverrides maps the node region label value to the region, it has priority over the static region table and the regions discovered, such as
+	// [region "xyz"]
+	// region=ap-xyz
+	RegionOverrides map[string]*RegionOverride `gcfg:"region"`
+	EKSPriceCache   EKSPriceCacheConfig        `gcfg:"eksPriceCache"`
+	EKSSpec         EKSSpecConfig              `gcfg:"eksSpec"`
+	Governor        GovernorConfig             `gcfg:"governor"`
+	// GovernorServices overrides the token bucket of the service, such as
+	// [governorService "cvm"]
+	// qps=5
+	// burst=1
+	GovernorServices map[string]*GovernorServiceConfig `gcfg:"governorService"`
 }
 
-// Credentials use user defined SecretId and SecretKey
+// GovernorConfig is the request governor shared by all the tencent cloud api clients, the zero fields use the default, such as
+// [governor]
+// qps=15
+// burst=21
+// actionQps=5
+// actionBurst=10
+// failureThreshold=5
+// openDuration=60s
+// baseBackoff=2s
+// maxBackoff=32s
+type GovernorConfig struct {
+	Qps              float64
+	Burst            int
+	ActionQps        float64
+	ActionBurst      int
+	FailureThreshold int
+	OpenDuration     string
+	BaseBackoff      string
+	MaxBackoff       string
+}
+
+type GovernorServiceConfig struct {
+	Qps   float64
+	Burst int
+}
+
+// EKSSpecConfig is the eks pod spec converter, such as
+// [eksSpec]
+// validate=true
+type EKSSpecConfig struct {
+	// Validate compares the local spec ladder with the api GetPodSpecification, the api spec is used if they are different.
+	// The pods the ladder can not convert are converted by the api whether it is true or not
+	Validate bool
+}
+
+// EKSPriceCacheConfig is the eks pod price cache, such as
+// [eksPriceCache]
+// ttl=24h
+// file=/data/eks-price-cache.json
+type EKSPriceCacheConfig struct {
+	// Ttl is the duration of the cached price, default is 24h
+	Ttl string
+	// File persists the cached prices if specified
+	File string
+}
+
+type RegionOverride struct {
+	Region     string
+	RegionName string
+}
+
+// Credentials use user defined SecretId and SecretKey, or other credential Source, see credential.Config
 type Credentials struct {
 	ClusterId string
 	AppId     string
 	SecretId  string
 	SecretKey string
+	// Source is static, env, file, sts or instancerole, default is static which use SecretId and SecretKey
+	Source              string
+	SecretDir           string
+	RoleArn             string
+	RoleSessionName     string
+	RoleDurationSeconds int64
+	InstanceRoleName    string
+	MetadataEndpoint    string
 }
 
 type ClientProfile struct {
@@ -49,6 +120,8 @@ type ClientProfile struct {
 	Region                string
 	DomainSuffix          string
 	Scheme                string
+	// Endpoint overrides the domain of all the cloud api services
+	Endpoint string
 }
 
 type qcloudKey struct {
@@ -58,6 +131,7 @@ type qcloudKey struct {
 	ProviderID     string
 	Zone           string
 	ChargeType     string
+	OS             string
 }
 
 func (k *qcloudKey) GPUType() string {
@@ -80,17 +154,14 @@ func (k *qcloudKey) Features() string {
 	// note: tke node zone is zoneId, convert it to zone
 	//zoneId, _ := util.GetZone(k.Labels)
 
-	key := k.Zone + "," + instanceType + "," + k.ChargeType
+	key := k.Zone + "," + instanceType + "," + k.ChargeType + "," + k.OS
 	return key
 }
 
 func (k *qcloudKey) Region() string {
 	regionShortName, _ := util.GetRegion(k.Labels)
-	region, ok := qcloudsdk.ShortName2region[regionShortName]
-	if !ok {
-		return ""
-	}
-	return region.Region
+	region, _ := qcloudsdk.ResolveRegion(regionShortName)
+	return region
 }
 
 var _ cloud.Cloud = &TencentCloud{}
@@ -114,6 +185,7 @@ type TencentCloud struct {
 	instances map[string]*sdkcvm.QCloudInstancePrice
 
 	eksPlatformer *EKSPlatform
+	eksPriceCache *EKSPriceCache
 	tkePlatformer *TKEPlatform
 	eksConverter  Pod2EKSSpecConverter
 }
@@ -129,20 +201,25 @@ func NewTencentCloud(qcloudConf *qcloudsdk.QCloudClientConfig, config *cloud.Pri
 		standardPricing: make(map[string]*cvm.InstanceTypeQuotaItem),
 		instances:       make(map[string]*sdkcvm.QCloudInstancePrice),
 		eksPlatformer:   &EKSPlatform{},
-		tkePlatformer:   &TKEPlatform{},
-		eksConverter:    tkeClient,
+		eksPriceCache:   NewEKSPriceCache(defaultEKSPriceCacheTTL, ""),
+		tkePlatformer:   NewTKEPlatform(),
+		eksConverter:    NewEKSSpecConverter(tkeClient, false),
 	}
 }
 
 func (q *TencentCloud) PlatformPrice(cp cloud.PlatformParameter) *cloud.Prices {
+	cfg, err := q.priceConfig.GetConfig()
+	if err != nil {
+		klog.Warningf("Failed to get custom pricing, use the default platform fee model: %v", err)
+	}
 	switch cp.Platform {
 	case cloud.ServerfulKind:
-		return q.tkePlatformer.PlatformCost(cp)
+		return q.tkePlatformer.PlatformCost(cfg, cp)
 	case cloud.ServerlessKind:
-		return q.eksPlatformer.PlatformCost(cp)
+		return q.eksPlatformer.PlatformCost(cfg, cp)
 	default:
-		klog.Warningf("unknown platform kind, only support serverless/serverful", cp.Platform)
-		return q.tkePlatformer.PlatformCost(cp)
+		klog.Warningf("unknown platform kind %v, only support serverless/serverful", cp.Platform)
+		return q.tkePlatformer.PlatformCost(cfg, cp)
 	}
 }
 
@@ -158,28 +235,28 @@ func (tc *TencentCloud) NodePrice(spec spec.CloudNodeSpec) (*cloud.Node, error)
 	return newCnode, nil
 }
 
-func CloudPodSpec2EKSPriceRequest(spec spec.CloudPodSpec) *tke.GetPriceRequest {
-	cpu := float64(spec.Cpu.MilliValue()) / 1000.0
-	mem := float64(spec.Mem.Value()) / consts.GB
-	req := tke.NewGetPriceRequest()
-	req.Cpu = &cpu
-	req.Mem = &mem
-	req.TimeSpan = &spec.TimeSpan
-	req.GoodsNum = &spec.GoodsNum
-	if spec.MachineArch != "" {
-		req.Type = &spec.MachineArch
-	}
-	if spec.PodChargeType != "" {
-		req.PodType = &spec.PodChargeType
-	}
-	if spec.Zone != "" {
-		req.Zone = &spec.Zone
+// eksPodPrice return the discount cost and total cost of the pods of the spec, unit is cent
+func (tc *TencentCloud) eksPodPrice(spec spec.CloudPodSpec) (float64, float64, error) {
+	key := newEKSPriceKey(spec)
+	entry, err := tc.eksPriceCache.GetOrFetch(key, func() (float64, float64, error) {
+		price, err := tc.tke.GetEKSPodPrice(key.request())
+		if err != nil {
+			return 0, 0, err
+		}
+		// do not cache a zero price of an empty response
+		if price.Response == nil || price.Response.Cost == nil || price.Response.TotalCost == nil {
+			return 0, 0, fmt.Errorf("no eks price of %+v in the response", key)
+		}
+		return float64(*price.Response.Cost), float64(*price.Response.TotalCost), nil
+	})
+	if err != nil {
+		return 0, 0, err
 	}
-	return req
+	return entry.Cost * float64(spec.GoodsNum), entry.TotalCost * float64(spec.GoodsNum), nil
 }
 
 func (tc *TencentCloud) ServerlessPodPrice(spec spec.CloudPodSpec) (*cloud.Pod, error) {
-	price, err := tc.tke.GetEKSPodPrice(CloudPodSpec2EKSPriceRequest(spec))
+	discountCost, cost, err := tc.eksPodPrice(spec)
 	if err != nil {
 		return nil, err
 	}
@@ -191,12 +268,9 @@ func (tc *TencentCloud) ServerlessPodPrice(spec spec.CloudPodSpec) (*cloud.Pod,
 	cpu := float64(spec.Cpu.MilliValue()) / 1000.
 	ram := float64(spec.Mem.Value())
 
-	var cost, discountCost float64
 	// price unit is cent
-	if price.Response != nil && price.Response.Cost != nil {
-		discountCost = float64(*price.Response.Cost) / 100.
-		cost = float64(*price.Response.TotalCost) / 100.
-	}
+	discountCost = discountCost / 100.
+	cost = cost / 100.
 	newCnode := &cloud.Pod{
 		BaseInstancePrice: cloud.BaseInstancePrice{
 			DiscountedCost: fmt.Sprintf("%f", discountCost),
@@ -284,8 +358,30 @@ func (tc *TencentCloud) ServerlessPodPrice(spec spec.CloudPodSpec) (*cloud.Pod,
 	return newCnode, nil
 }
 
+// PodPrice breaks down the price of the node the pod is scheduled on to the pod
 func (tc *TencentCloud) PodPrice(spec spec.CloudPodSpec) (*cloud.Pod, error) {
-	panic("implement me")
+	cfg, err := tc.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("provider config is null")
+	}
+	if spec.PodRef == nil {
+		return nil, fmt.Errorf("pod is null")
+	}
+	node := tc.cache.GetNode(spec.PodRef.Spec.NodeName)
+	if node == nil {
+		return nil, fmt.Errorf("node %v of pod %v not found", spec.PodRef.Spec.NodeName, klog.KObj(spec.PodRef))
+	}
+	if tc.IsVirtualNode(node) {
+		return nil, fmt.Errorf("pod %v is in virtual node %v, use ServerlessPodPrice instead", klog.KObj(spec.PodRef), node.Name)
+	}
+	nodePrice, err := tc.computeNodeBreakdownCost(cfg, node)
+	if err != nil {
+		return nil, err
+	}
+	return cloud.PodPriceOnNode(cfg, spec, node, nodePrice)
 }
 
 func (tc *TencentCloud) IsVirtualNode(node *v1.Node) bool {
@@ -367,7 +463,7 @@ func (tc *TencentCloud) Pod2ServerlessSpec(pod *v1.Pod) spec.CloudPodSpec {
 	} else {
 		resourceList, err := tc.eksConverter.Pod2EKSSpecConverter(pod)
 		if err != nil {
-			klog.Errorf("Failed to convert pod %v to eks spec: %v, use default sum way", klog.KObj(pod), err)
+			klog.Errorf("Failed to convert pod %v to eks spec by the ladder or the api: %v, use default sum way, the cost maybe underestimated", klog.KObj(pod), err)
 		}
 		for name, value := range resourceList {
 			reqs[name] = value
@@ -411,7 +507,7 @@ func (tc *TencentCloud) Pod2Spec(pod *v1.Pod) spec.CloudPodSpec {
 		if qosClass != v1.PodQOSBestEffort {
 			resourceList, err := tc.eksConverter.Pod2EKSSpecConverter(pod)
 			if err != nil {
-				klog.Errorf("Failed to convert pod %v to eks spec: %v, use default sum way", klog.KObj(pod), err)
+				klog.Errorf("Failed to convert pod %v to eks spec by the ladder or the api: %v, use default sum way, the cost maybe underestimated", klog.KObj(pod), err)
 			}
 			for name, value := range resourceList {
 				reqs[name] = value
@@ -461,14 +557,17 @@ func (tc *TencentCloud) Node2Spec(node *v1.Node) spec.CloudNodeSpec {
 	}
 
 	return spec.CloudNodeSpec{
-		NodeRef:      node,
-		Cpu:          cpuCores,
-		Mem:          memory,
-		ChargeType:   usageType,
-		InstanceType: insType,
-		Zone:         zone,
-		Region:       region,
-		VirtualNode:  tc.IsVirtualNode(node),
+		NodeRef:        node,
+		Cpu:            cpuCores,
+		Mem:            memory,
+		ChargeType:     usageType,
+		InstanceType:   insType,
+		Zone:           zone,
+		Region:         region,
+		OS:             cloud.DetectOperatingSystem(node),
+		Arch:           cloud.DetectArch(node),
+		InstanceFamily: util.GetInstanceFamily(insType),
+		VirtualNode:    tc.IsVirtualNode(node),
 	}
 }
 
@@ -484,8 +583,8 @@ func (tc *TencentCloud) GetConfig() (*cloud.CustomPricing, error) {
 
 func (tc *TencentCloud) getNodeRegion(node *v1.Node) string {
 	regionShortName, _ := util.GetRegion(node.Labels)
-	if regionStruct, ok := qcloudsdk.ShortName2region[regionShortName]; ok {
-		return regionStruct.Region
+	if region, ok := qcloudsdk.ResolveNodeRegion(node.Name, regionShortName); ok {
+		return region
 	} else {
 		return regionShortName
 	}
@@ -499,21 +598,28 @@ func (tc *TencentCloud) getDefaultNodePrice(cfg *cloud.CustomPricing, node *v1.N
 	memory := node.Status.Capacity[v1.ResourceMemory]
 	cpu := float64(cpuCores.Value())
 	mem := float64(memory.Value())
+	os := cloud.DetectOperatingSystem(node)
+	arch := cloud.DetectArch(node)
+	license := cfg.LicenseHourlyPrice(os, cpu)
+	cpuPrice, ramPrice := cfg.UnitPrices(util.GetInstanceFamily(insType), arch)
 	return &cloud.Node{
 		BaseInstancePrice: cloud.BaseInstancePrice{
-			Cost:             fmt.Sprintf("%v", cfg.CpuHourlyPrice*cpu+cfg.RamGBHourlyPrice*mem/consts.GB),
-			Cpu:              fmt.Sprintf("%v", cpu),
-			CpuHourlyCost:    fmt.Sprintf("%v", cfg.CpuHourlyPrice),
-			Ram:              fmt.Sprintf("%v", mem/consts.GB),
-			RamBytes:         fmt.Sprintf("%v", mem),
-			RamGBHourlyCost:  fmt.Sprintf("%v", cfg.RamGBHourlyPrice),
-			DefaultCpuPrice:  fmt.Sprintf("%v", cfg.CpuHourlyPrice),
-			DefaultRamPrice:  fmt.Sprintf("%v", cfg.RamGBHourlyPrice),
-			UsageType:        usageType,
-			UsesDefaultPrice: true,
-			InstanceType:     insType,
-			ProviderID:       node.Spec.ProviderID,
-			Region:           region,
+			Cost:              fmt.Sprintf("%v", cpuPrice*cpu+ramPrice*mem/consts.GB+license),
+			Cpu:               fmt.Sprintf("%v", cpu),
+			CpuHourlyCost:     fmt.Sprintf("%v", cpuPrice),
+			Ram:               fmt.Sprintf("%v", mem/consts.GB),
+			RamBytes:          fmt.Sprintf("%v", mem),
+			RamGBHourlyCost:   fmt.Sprintf("%v", ramPrice),
+			DefaultCpuPrice:   fmt.Sprintf("%v", cfg.CpuHourlyPrice),
+			DefaultRamPrice:   fmt.Sprintf("%v", cfg.RamGBHourlyPrice),
+			LicenseHourlyCost: fmt.Sprintf("%v", license),
+			UsageType:         usageType,
+			UsesDefaultPrice:  true,
+			InstanceType:      insType,
+			ProviderID:        node.Spec.ProviderID,
+			Region:            region,
+			OS:                os,
+			Arch:              arch,
 		},
 	}, nil
 }
@@ -561,6 +667,9 @@ func (tc *TencentCloud) getCloudInstancePrice(node *v1.Node) (*cloud.Node, error
 	if instance.InstanceType != nil {
 		insType = *instance.InstanceType
 	}
+	// the price is inquired with the image of the instance, so it includes the os license already. the configured license price is
+	// only added to the default price of the nodes without inquired price
+	os := cloud.DetectOperatingSystem(node)
 
 	if usageType == qcloudsdk.INSTANCECHARGETYPE_PREPAID {
 		// prepaid original price is for one month.
@@ -578,6 +687,8 @@ func (tc *TencentCloud) getCloudInstancePrice(node *v1.Node) (*cloud.Node, error
 				InstanceType:    insType,
 				Region:          region,
 				ProviderID:      node.Spec.ProviderID,
+				OS:              os,
+				Arch:            cloud.DetectArch(node),
 			},
 		}, nil
 	} else if usageType == qcloudsdk.INSTANCECHARGETYPE_POSTPAID_BY_HOUR {
@@ -594,6 +705,8 @@ func (tc *TencentCloud) getCloudInstancePrice(node *v1.Node) (*cloud.Node, error
 				InstanceType:    insType,
 				Region:          region,
 				ProviderID:      node.Spec.ProviderID,
+				OS:              os,
+				Arch:            cloud.DetectArch(node),
 			},
 		}, nil
 	} else if usageType == qcloudsdk.INSTANCECHARGETYPE_SPOTPAID {
@@ -611,6 +724,8 @@ func (tc *TencentCloud) getCloudInstancePrice(node *v1.Node) (*cloud.Node, error
 				InstanceType:    insType,
 				Region:          region,
 				ProviderID:      node.Spec.ProviderID,
+				OS:              os,
+				Arch:            cloud.DetectArch(node),
 			},
 		}, nil
 	} else {
@@ -714,15 +829,14 @@ func (tc *TencentCloud) computeNodeBreakdownCost(cfg *cloud.CustomPricing, node
 	if !cnodePrice.UsesDefaultPrice {
 		klog.V(3).Infof("Need to calculating node price... node: %v, key: %v", node.Name, tc.GetKey(node).Features())
 
-		defaultCPU := cfg.CpuHourlyPrice
+		// the cpu to ram price ratio maybe different for arm, amd or intel instances
+		defaultCPU, defaultRAM := cfg.UnitPrices(util.GetInstanceFamily(newCnode.InstanceType), cloud.DetectArch(node))
 
 		if math.IsNaN(defaultCPU) {
 			klog.V(3).Infof("DefaultCPU parsed as NaN. Setting to 0. node: %v, key: %v", node.Name, tc.GetKey(node).Features())
 			defaultCPU = 0
 		}
 
-		defaultRAM := cfg.RamGBHourlyPrice
-
 		if math.IsNaN(defaultRAM) {
 			klog.V(3).Infof("DefaultRAM parsed as NaN. Setting to 0. node: %v, key: %v", node.Name, tc.GetKey(node).Features())
 			defaultRAM = 0
@@ -767,6 +881,13 @@ func (tc *TencentCloud) computeNodeBreakdownCost(cfg *cloud.CustomPricing, node
 			klog.V(3).Infof("nodePrice is NaN. Setting to 0. node: %v, key: %v", node.Name, tc.GetKey(node).Features())
 			nodePrice = 0
 		}
+		// the license cost is broken out, do not breakdown it to cpu and ram
+		if newCnode.LicenseHourlyCost != "" {
+			license, err := strconv.ParseFloat(newCnode.LicenseHourlyCost, 64)
+			if err == nil && !math.IsNaN(license) {
+				nodePrice -= license
+			}
+		}
 
 		ramPrice := nodePrice / ramMultiple
 		if math.IsNaN(ramPrice) {
@@ -789,6 +910,7 @@ func (tc *TencentCloud) computeNodeBreakdownCost(cfg *cloud.CustomPricing, node
 
 		klog.V(3).Infof("Computed Node Cost cost: %v, node: %v, key: %v", node.Name, newCnode.RamGBHourlyCost, tc.GetKey(node).Features())
 	}
+	cloud.SetNodeOverheadCost(node, &newCnode)
 	return &newCnode, nil
 }
 
@@ -808,25 +930,37 @@ func (tc *TencentCloud) GetPodsCost() (map[string]*cloud.Pod, error) {
 	for _, node := range nodeList {
 		nodesMap[node.Name] = node
 	}
+	// node breakdown price is shared by the pods in it
+	nodePrices := make(map[string]*cloud.Node)
 	podList := tc.cache.GetPods()
 	for _, pod := range podList {
 		key := klog.KObj(pod).String()
 
 		nodeName := pod.Spec.NodeName
-		node := nodesMap[nodeName]
+		node, ok := nodesMap[nodeName]
+		if !ok {
+			klog.V(4).Infof("pod is not scheduled or its node is not found, pod: %v, node: %v", klog.KObj(pod), nodeName)
+			continue
+		}
 		if tc.IsVirtualNode(node) {
 			klog.V(3).Infof("pod is in virtual node, ignore temporarily pod: %v, node: %v", klog.KObj(pod), klog.KObj(node))
 			continue
 		}
 
-		nodePrice, err := tc.computeNodeBreakdownCost(cfg, node)
+		nodePrice, ok := nodePrices[nodeName]
+		if !ok {
+			nodePrice, err = tc.computeNodeBreakdownCost(cfg, node)
+			if err != nil {
+				klog.Errorf("Failed to computeNodeBreakdownCost pod: %v, node: %v", klog.KObj(pod), klog.KObj(node))
+				continue
+			}
+			nodePrices[nodeName] = nodePrice
+		}
+		podPrice, err := cloud.PodPriceOnNode(cfg, cloud.NodePodSpec(pod), node, nodePrice)
 		if err != nil {
-			klog.Errorf("Failed to computeNodeBreakdownCost pod: %v, node: %v", klog.KObj(pod), klog.KObj(node))
+			klog.Errorf("Failed to compute pod price pod: %v, node: %v: %v", klog.KObj(pod), klog.KObj(node), err)
 			continue
 		}
-		podPrice := &cloud.Pod{
-			BaseInstancePrice: nodePrice.BaseInstancePrice,
-		}
 		pods[key] = podPrice
 	}
 	return pods, nil
@@ -836,6 +970,7 @@ func (tc *TencentCloud) GetKey(node *v1.Node) *qcloudKey {
 	key := &qcloudKey{
 		Labels:     node.Labels,
 		ProviderID: node.Spec.ProviderID,
+		OS:         cloud.DetectOperatingSystem(node),
 	}
 
 	insID := key.ID()
@@ -863,11 +998,14 @@ func (tc *TencentCloud) GetNodesPricing() (map[string]*cloud.Price, error) {
 	results := make(map[string]*cloud.Price)
 	for id, insPrice := range tc.instances {
 		qPrice := insPrice.Price.InstancePrice
+		family := util.GetInstanceFamily(*insPrice.Instance.InstanceType)
 		results[id] = &cloud.Price{
-			InstanceType: *insPrice.Instance.InstanceType,
-			ChargeType:   *insPrice.Instance.InstanceChargeType,
-			VCpu:         fmt.Sprintf("%v", *insPrice.Instance.CPU),
-			Memory:       fmt.Sprintf("%v", *insPrice.Instance.Memory),
+			InstanceType:   *insPrice.Instance.InstanceType,
+			InstanceFamily: family,
+			Arch:           instanceFamilyArch(family),
+			ChargeType:     *insPrice.Instance.InstanceChargeType,
+			VCpu:           fmt.Sprintf("%v", *insPrice.Instance.CPU),
+			Memory:         fmt.Sprintf("%v", *insPrice.Instance.Memory),
 			CvmPrice: &cloud.PriceItem{
 				UnitPrice:                   qPrice.UnitPrice,
 				ChargeUnit:                  qPrice.ChargeUnit,
@@ -893,3 +1031,13 @@ func (tc *TencentCloud) GetNodesPricing() (map[string]*cloud.Price, error) {
 	}
 	return results, nil
 }
+
+// armInstanceFamilies are the cvm instance families with arm cpu
+var armInstanceFamilies = sets.NewString("SR1")
+
+func instanceFamilyArch(family string) string {
+	if armInstanceFamilies.Has(family) {
+		return cloud.ArchARM64
+	}
+	return cloud.ArchAMD64
+}
@@ -0,0 +1,648 @@
+package qcloud
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+
+	"github.com/gocrane/fadvisor/pkg/cache"
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	qcloudsdk "github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud"
+	qcloudfake "github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/fake"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+func newFakeNode() *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "10.0.0.1",
+			Labels: map[string]string{
+				v1.LabelInstanceTypeStable: "S5.LARGE8",
+				v1.LabelTopologyRegion:     "gz",
+			},
+		},
+		Spec: v1.NodeSpec{
+			ProviderID: "qcloud:///100003/ins-fake0001",
+		},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+}
+
+func newFakeTencentCloud(t *testing.T, objects ...runtime.Object) (*TencentCloud, *qcloudfake.Server) {
+	server, err := qcloudfake.NewServerWithFixtures("testdata/fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTencentCloudWithServer(t, server, objects...), server
+}
+
+func newTencentCloudWithServer(t *testing.T, server *qcloudfake.Server, objects ...runtime.Object) *TencentCloud {
+	stopCh := make(chan struct{})
+	t.Cleanup(func() {
+		close(stopCh)
+		server.Close()
+	})
+	c := cache.NewCache(fake.NewSimpleClientset(objects...))
+	c.WaitForCacheSync(stopCh)
+
+	priceConfig := cloud.NewProviderConfig(&cloud.CustomPricing{CpuHourlyPrice: 0.1, RamGBHourlyPrice: 0.05})
+	return NewTencentCloud(server.ClientConfig("ap-guangzhou"), priceConfig, c).(*TencentCloud)
+}
+
+func mustParseFloat(t *testing.T, s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		t.Fatalf("failed to parse %v: %v", s, err)
+	}
+	return f
+}
+
+func TestTencentCloudNodesCost(t *testing.T) {
+	tc, server := newFakeTencentCloud(t, newFakeNode())
+	if err := tc.WarmUp(); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, err := tc.GetNodesCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, ok := nodes["10.0.0.1"]
+	if !ok {
+		t.Fatalf("expect node cost, got %+v", nodes)
+	}
+	if node.UsageType != "POSTPAID_BY_HOUR" || node.InstanceType != "S5.LARGE8" {
+		t.Errorf("unexpected node %+v", node.BaseInstancePrice)
+	}
+	if cost := mustParseFloat(t, node.Cost); cost != 0.8 {
+		t.Errorf("expect node cost 0.8, got %v", cost)
+	}
+
+	calls := server.Calls("DescribeInstances")
+	if len(calls) != 1 {
+		t.Fatalf("expect 1 DescribeInstances call, got %v", len(calls))
+	}
+	if ids, ok := calls[0].Params["InstanceIds"].([]interface{}); !ok || len(ids) != 1 || ids[0] != "ins-fake0001" {
+		t.Errorf("unexpected DescribeInstances params %+v", calls[0].Params)
+	}
+	if region := calls[0].Region; region != "ap-guangzhou" {
+		t.Errorf("expect region ap-guangzhou, got %v", region)
+	}
+}
+
+func TestTencentCloudInquiryPrice(t *testing.T) {
+	tc, _ := newFakeTencentCloud(t)
+	instances, err := tc.cvm.GetCVMInstances([]*string{common.StringPtr("ins-fake0001")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prices, err := tc.cvm.GetCVMInstancesInquiryPrice(instances)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prices) != 1 || prices[0].Price.InstancePrice == nil || *prices[0].Price.InstancePrice.UnitPriceDiscount != 0.6 {
+		t.Errorf("unexpected inquiry prices %+v", prices)
+	}
+}
+
+func TestTencentCloudServerlessPodPrice(t *testing.T) {
+	tc, server := newFakeTencentCloud(t)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "c1",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("800m"),
+							v1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	podSpec := tc.Pod2ServerlessSpec(pod)
+	// 800m is rounded up to the 1 core row, 1Gi fits its smallest memory
+	if podSpec.Cpu.Cmp(resource.MustParse("1")) != 0 || podSpec.Mem.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("expect eks spec 1c1Gi, got cpu %v mem %v", podSpec.Cpu.String(), podSpec.Mem.String())
+	}
+
+	price, err := tc.ServerlessPodPrice(podSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost := mustParseFloat(t, price.Cost); cost != 0.25 {
+		t.Errorf("expect cost 0.25, got %v", cost)
+	}
+	if cost := mustParseFloat(t, price.DiscountedCost); cost != 0.2 {
+		t.Errorf("expect discounted cost 0.2, got %v", cost)
+	}
+
+	calls := server.Calls("GetPrice")
+	if len(calls) != 1 || calls[0].Params["Cpu"] != 1.0 || calls[0].Params["Mem"] != 1.0 {
+		t.Errorf("unexpected GetPrice calls %+v", calls)
+	}
+	if calls := server.Calls("GetPodSpecification"); len(calls) != 0 {
+		t.Errorf("expect the eks spec converted offline, got %v GetPodSpecification calls", len(calls))
+	}
+}
+
+func TestTencentCloudApiError(t *testing.T) {
+	server := qcloudfake.NewServer()
+	server.ScriptError("GetPrice", "ResourceNotFound", "no such spec")
+	tc := newTencentCloudWithServer(t, server)
+
+	if _, err := tc.ServerlessPodPrice(tc.Pod2ServerlessSpec(&v1.Pod{})); err == nil {
+		t.Errorf("expect error of GetPrice")
+	}
+	// no fixture for the action
+	if _, err := tc.cvm.GetCVMInstances([]*string{common.StringPtr("ins-fake0001")}); err == nil {
+		t.Errorf("expect error of DescribeInstances")
+	}
+}
+
+func TestTencentCloudDiscoverRegions(t *testing.T) {
+	tc, server := newFakeTencentCloud(t)
+	if err := server.ScriptResponse("DescribeRegions", map[string]interface{}{
+		"TotalCount": 1,
+		"RegionSet":  []map[string]string{{"Region": "ap-newregion", "RegionName": "new region", "RegionState": "AVAILABLE"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// the first fixture of DescribeRegions is ap-guangzhou
+	resolver := qcloudsdk.NewRegionResolver()
+	if err := tc.cvm.DiscoverRegions(resolver); err != nil {
+		t.Fatal(err)
+	}
+	if err := tc.cvm.DiscoverRegions(resolver); err != nil {
+		t.Fatal(err)
+	}
+	if info, ok := resolver.Resolve("ap-newregion"); !ok || info.RegionName != "new region" {
+		t.Errorf("expect discovered region ap-newregion, got %+v", info)
+	}
+}
+
+func TestBuildClientConfigRegionOverrides(t *testing.T) {
+	defer qcloudsdk.DefaultRegionResolver().SetOverrides(nil)
+	config := `[credentials]
+secretId=id
+secretKey=key
+[clientProfile]
+region=ap-guangzhou
+[region "xyz"]
+region=ap-xyz
+`
+	if _, err := buildClientConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+	node := newFakeNode()
+	node.Labels[v1.LabelTopologyRegion] = "xyz"
+	tc, _ := newFakeTencentCloud(t)
+	if region := tc.getNodeRegion(node); region != "ap-xyz" {
+		t.Errorf("expect region ap-xyz, got %v", region)
+	}
+}
+
+func TestGovernorConfig(t *testing.T) {
+	config := `[governor]
+qps=20
+actionBurst=2
+openDuration=30s
+[governorService "cvm"]
+qps=3
+[governorService "cbs"]
+qps=1
+burst=1
+`
+	cfg, err := loadCloudConfig(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	governorConfig, err := newGovernorConfig(cfg.Governor, cfg.GovernorServices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := qcloudsdk.DefaultGovernorConfig()
+	expect.QPS = 20
+	expect.ActionBurst = 2
+	expect.OpenDuration = 30 * time.Second
+	expect.ServiceLimits["cvm"] = qcloudsdk.RateLimit{QPS: 3, Burst: 1}
+	expect.ServiceLimits["cbs"] = qcloudsdk.RateLimit{QPS: 1, Burst: 1}
+	if !reflect.DeepEqual(governorConfig, expect) {
+		t.Errorf("expect governor config %+v, got %+v", expect, governorConfig)
+	}
+
+	for _, invalid := range []string{"[governor]\nmaxBackoff=abc\n", "[governorService \"cbs\"]\nqps=1\n"} {
+		cfg, err := loadCloudConfig(strings.NewReader(invalid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newGovernorConfig(cfg.Governor, cfg.GovernorServices); err == nil {
+			t.Errorf("expect error of governor config %q", invalid)
+		}
+	}
+}
+
+func TestTencentCloudWindowsLicenseCost(t *testing.T) {
+	node := newFakeNode()
+	node.Labels[v1.LabelOSStable] = "windows"
+	tc, _ := newFakeTencentCloud(t, node)
+	if _, err := tc.UpdateConfigFromConfigMap(map[string]string{"windowsLicenseCoreHourlyPrice": "0.02"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the instance is not priced before warming up, the license is added to the default price
+	nodes, err := tc.GetNodesCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	price := nodes["10.0.0.1"]
+	if price == nil || price.OS != "windows" {
+		t.Fatalf("expect windows node cost, got %+v", price)
+	}
+	license := mustParseFloat(t, price.LicenseHourlyCost)
+	if math.Abs(license-0.08) > 1e-9 {
+		t.Errorf("expect license cost 0.08, got %v", license)
+	}
+	if cost := mustParseFloat(t, price.Cost); math.Abs(cost-0.88) > 1e-9 {
+		t.Errorf("expect default node cost 0.88, got %v", cost)
+	}
+
+	// the inquired price of the instance includes the license of its image, it is not charged again
+	if err := tc.WarmUp(); err != nil {
+		t.Fatal(err)
+	}
+	nodes, err = tc.GetNodesCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	price = nodes["10.0.0.1"]
+	if price == nil || price.OS != "windows" {
+		t.Fatalf("expect windows node cost, got %+v", price)
+	}
+	if cost := mustParseFloat(t, price.Cost); math.Abs(cost-0.8) > 1e-9 {
+		t.Errorf("expect inquired node cost 0.8, got %v", cost)
+	}
+	if price.LicenseHourlyCost != "" {
+		t.Errorf("expect no license cost of the inquired price, got %v", price.LicenseHourlyCost)
+	}
+	breakdown := mustParseFloat(t, price.CpuHourlyCost)*4 + mustParseFloat(t, price.RamGBHourlyCost)*8
+	if math.Abs(breakdown-0.8) > 1e-5 {
+		t.Errorf("expect cpu and ram cost 0.8, got %v", breakdown)
+	}
+	if os := tc.Node2Spec(node).OS; os != "windows" {
+		t.Errorf("expect node spec os windows, got %v", os)
+	}
+}
+
+func TestTencentCloudPodPrice(t *testing.T) {
+	node := newFakeNode()
+	node.Status.Allocatable = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec: v1.PodSpec{
+			NodeName: node.Name,
+			Containers: []v1.Container{
+				{
+					Name: "c1",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("1"),
+							v1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	tc, _ := newFakeTencentCloud(t, node, pod)
+	if err := tc.WarmUp(); err != nil {
+		t.Fatal(err)
+	}
+
+	// node cost 0.8 is broken down to cpu 0.1 and ram 0.05
+	nodes, err := tc.GetNodesCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price := nodes[node.Name]; price == nil || math.Abs(mustParseFloat(t, price.OverheadHourlyCost)-0.4) > 1e-5 {
+		t.Errorf("expect node overhead cost 0.4, got %+v", price)
+	}
+
+	pods, err := tc.GetPodsCost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price := pods["default/pod1"]; price == nil || math.Abs(mustParseFloat(t, price.Cost)-0.2) > 1e-5 {
+		t.Errorf("expect pod cost 0.2 by requests, got %+v", price)
+	}
+
+	if _, err := tc.UpdateConfigFromConfigMap(map[string]string{"podAllocationMode": "limit"}); err == nil {
+		t.Errorf("expect unknown pod allocation mode rejected")
+	}
+
+	if _, err := tc.UpdateConfigFromConfigMap(map[string]string{"nodeOverheadMode": "reserved"}); err == nil {
+		t.Errorf("expect unknown node overhead mode rejected")
+	}
+
+	podSpec := cloud.NodePodSpec(pod)
+	podSpec.Usage = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("2"),
+		v1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	cases := []struct {
+		allocation string
+		overhead   string
+		expected   float64
+	}{
+		{allocation: cloud.PodAllocationRequest, overhead: cloud.NodeOverheadPlatform, expected: 0.2},
+		{allocation: cloud.PodAllocationUsage, overhead: cloud.NodeOverheadPlatform, expected: 0.25},
+		{allocation: cloud.PodAllocationMax, overhead: cloud.NodeOverheadPlatform, expected: 0.3},
+		{allocation: cloud.PodAllocationRequest, overhead: cloud.NodeOverheadAllocatable, expected: 0.4},
+		{allocation: cloud.PodAllocationRequest, overhead: cloud.NodeOverheadCapacity, expected: 0.2},
+	}
+	for _, c := range cases {
+		if _, err := tc.UpdateConfigFromConfigMap(map[string]string{"podAllocationMode": c.allocation, "nodeOverheadMode": c.overhead}); err != nil {
+			t.Fatal(err)
+		}
+		price, err := tc.PodPrice(podSpec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost := mustParseFloat(t, price.Cost); math.Abs(cost-c.expected) > 1e-5 {
+			t.Errorf("expect pod cost %v by %v and %v, got %v", c.expected, c.allocation, c.overhead, cost)
+		}
+	}
+}
+
+func TestTencentCloudPlatformPrice(t *testing.T) {
+	tc, _ := newFakeTencentCloud(t)
+	nodes := int32(10)
+	level := "L100"
+
+	if price := tc.PlatformPrice(cloud.PlatformParameter{Nodes: &nodes, Platform: cloud.ServerfulKind}); price.TotalPrice != 0.40 {
+		t.Errorf("expect default tke fee 0.40 of 10 nodes, got %v", price.TotalPrice)
+	}
+	if price := tc.PlatformPrice(cloud.PlatformParameter{Nodes: &nodes, ClusterLevel: &level, Platform: cloud.ServerfulKind}); price.TotalPrice != 1.22 {
+		t.Errorf("expect default tke fee 1.22 of level L100, got %v", price.TotalPrice)
+	}
+	if price := tc.PlatformPrice(cloud.PlatformParameter{Nodes: &nodes, Platform: cloud.ServerlessKind}); price.TotalPrice != 0 {
+		t.Errorf("expect no eks fee by default, got %v", price.TotalPrice)
+	}
+
+	fees := `{"serverful": {"tiers": [{"nodes": 5, "priceHourly": 0}, {"nodes": 50, "priceHourly": 0.5}], "nodeHourlyPrice": 0.01}, "serverless": {"tiers": [{"nodes": 0, "priceHourly": 0.2}]}}`
+	if _, err := tc.UpdateConfigFromConfigMap(map[string]string{"platformFees": fees}); err != nil {
+		t.Fatal(err)
+	}
+	if price := tc.PlatformPrice(cloud.PlatformParameter{Nodes: &nodes, Platform: cloud.ServerfulKind}); math.Abs(price.TotalPrice-0.6) > 1e-9 {
+		t.Errorf("expect configured tke fee 0.6 of 10 nodes, got %v", price.TotalPrice)
+	}
+	free := int32(3)
+	if price := tc.PlatformPrice(cloud.PlatformParameter{Nodes: &free, Platform: cloud.ServerfulKind}); math.Abs(price.TotalPrice-0.03) > 1e-9 {
+		t.Errorf("expect free tier with node fee 0.03 of 3 nodes, got %v", price.TotalPrice)
+	}
+	if price := tc.PlatformPrice(cloud.PlatformParameter{Platform: cloud.ServerlessKind}); price.TotalPrice != 0.2 || price.DiscountPrice == nil || *price.DiscountPrice != 0.2 {
+		t.Errorf("expect configured eks fee 0.2 and discount price 0.2, got %+v", price)
+	}
+}
+
+func TestTencentCloudServerlessPodPriceCache(t *testing.T) {
+	tc, server := newFakeTencentCloud(t)
+	tc.eksPriceCache = NewEKSPriceCache(time.Hour, filepath.Join(t.TempDir(), "eks-price-cache.json"))
+	tc.tke.SetSpecStore(tc.eksPriceCache)
+	tc.eksConverter = NewEKSSpecConverter(tc.tke, true)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "c1",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("800m")},
+					},
+				},
+			},
+		},
+	}
+
+	podSpec := tc.Pod2ServerlessSpec(pod)
+	_ = tc.Pod2ServerlessSpec(pod.DeepCopy())
+	if calls := server.Calls("GetPodSpecification"); len(calls) != 1 {
+		t.Errorf("expect 1 GetPodSpecification call for the same requirements, got %v", len(calls))
+	}
+
+	for _, goodsNum := range []uint64{1, 3} {
+		podSpec.GoodsNum = goodsNum
+		price, err := tc.ServerlessPodPrice(podSpec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost := mustParseFloat(t, price.Cost); math.Abs(cost-0.25*float64(goodsNum)) > 1e-9 {
+			t.Errorf("expect cost %v of %v pods, got %v", 0.25*float64(goodsNum), goodsNum, cost)
+		}
+	}
+	if calls := server.Calls("GetPrice"); len(calls) != 1 || calls[0].Params["GoodsNum"] != 1.0 {
+		t.Errorf("expect 1 GetPrice call of one pod, got %+v", calls)
+	}
+	if hits, misses := tc.eksPriceCache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("expect 1 hit and 1 miss, got %v and %v", hits, misses)
+	}
+
+	// the prices are persisted by flush, and reused
+	if reloaded := NewEKSPriceCache(time.Hour, tc.eksPriceCache.file); len(reloaded.entries) != 0 {
+		t.Errorf("expect no prices persisted before flush, got %v", len(reloaded.entries))
+	}
+	tc.Flush()
+	reloaded := NewEKSPriceCache(time.Hour, tc.eksPriceCache.file)
+	if entry, ok := reloaded.Get(newEKSPriceKey(podSpec)); !ok || entry.TotalCost != 25 {
+		t.Errorf("expect persisted price 25, got %+v", entry)
+	}
+
+	// the specs the api converted are persisted too, the next run converts the pod without the api
+	next, nextServer := newFakeTencentCloud(t)
+	next.eksPriceCache = reloaded
+	next.tke.SetSpecStore(reloaded)
+	next.eksConverter = NewEKSSpecConverter(next.tke, true)
+	if nextSpec := next.Pod2ServerlessSpec(pod); nextSpec.Cpu.Cmp(podSpec.Cpu) != 0 || nextSpec.Mem.Cmp(podSpec.Mem) != 0 {
+		t.Errorf("expect persisted spec %v/%v, got %v/%v", podSpec.Cpu.String(), podSpec.Mem.String(), nextSpec.Cpu.String(), nextSpec.Mem.String())
+	}
+	if calls := nextServer.Calls("GetPodSpecification"); len(calls) != 0 {
+		t.Errorf("expect no GetPodSpecification call with the persisted spec, got %v", len(calls))
+	}
+}
+
+func TestTencentCloudServerlessPodPriceEmptyResponse(t *testing.T) {
+	server := qcloudfake.NewServer()
+	if err := server.ScriptResponse("GetPrice", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.ScriptResponse("GetPrice", map[string]interface{}{"Cost": 20, "TotalCost": 25}); err != nil {
+		t.Fatal(err)
+	}
+	tc := newTencentCloudWithServer(t, server)
+	podSpec := spec.CloudPodSpec{Cpu: resource.MustParse("1"), Mem: resource.MustParse("2Gi"), GoodsNum: 1}
+	if _, err := tc.ServerlessPodPrice(podSpec); err == nil {
+		t.Errorf("expect error of the empty price response")
+	}
+	price, err := tc.ServerlessPodPrice(podSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost := mustParseFloat(t, price.Cost); cost != 0.25 {
+		t.Errorf("expect the empty price not cached and cost 0.25, got %v", cost)
+	}
+}
+
+func TestEKSSpecConverter(t *testing.T) {
+	newPod := func(annotations map[string]string, requests, limits v1.ResourceList) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default", Annotations: annotations},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{Name: "c1", Resources: v1.ResourceRequirements{Requests: requests, Limits: limits}},
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		name    string
+		pod     *v1.Pod
+		cpu     string
+		mem     string
+		wantErr bool
+	}{
+		{
+			name: "cpu only",
+			pod:  newPod(nil, v1.ResourceList{v1.ResourceCPU: resource.MustParse("800m")}, nil),
+			cpu:  "1",
+			mem:  "2Gi",
+		},
+		{
+			name: "limits larger than requests",
+			pod: newPod(nil, v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("1Gi")},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("3"), v1.ResourceMemory: resource.MustParse("5Gi")}),
+			cpu: "4",
+			mem: "8Gi",
+		},
+		{
+			name: "memory rounded up in the cpu row",
+			pod:  newPod(nil, v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m"), v1.ResourceMemory: resource.MustParse("600Mi")}, nil),
+			cpu:  "250m",
+			mem:  "1Gi",
+		},
+		{
+			name: "memory exceeds the cpu row",
+			pod:  newPod(nil, v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("6Gi")}, nil),
+			cpu:  "1",
+			mem:  "6Gi",
+		},
+		{
+			name: "amd",
+			pod: newPod(map[string]string{EKSAnnoCpuType: "amd"},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("5Gi")}, nil),
+			cpu: "2",
+			mem: "8Gi",
+		},
+		{
+			name: "specified by annotations",
+			pod: newPod(map[string]string{EKSAnnoCpuQuantity: "2", EKSAnnoMemQuantity: "4Gi"},
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")}, nil),
+			cpu: "2",
+			mem: "4Gi",
+		},
+		{
+			name:    "too large",
+			pod:     newPod(nil, v1.ResourceList{v1.ResourceCPU: resource.MustParse("64")}, nil),
+			wantErr: true,
+		},
+		{
+			name:    "gpu",
+			pod:     newPod(map[string]string{EKSAnnoGpuType: "V100"}, v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, nil),
+			wantErr: true,
+		},
+	}
+
+	converter := NewEKSSpecConverter(nil, false)
+	for _, tc := range testCases {
+		spec, err := converter.Pod2EKSSpecConverter(tc.pod)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%v: expect error, got %v", tc.name, spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", tc.name, err)
+			continue
+		}
+		if spec.Cpu().Cmp(resource.MustParse(tc.cpu)) != 0 || spec.Memory().Cmp(resource.MustParse(tc.mem)) != 0 {
+			t.Errorf("%v: expect %v/%v, got %v/%v", tc.name, tc.cpu, tc.mem, spec.Cpu().String(), spec.Memory().String())
+		}
+	}
+}
+
+func TestEKSSpecConverterValidator(t *testing.T) {
+	tc, server := newFakeTencentCloud(t)
+	converter := NewEKSSpecConverter(tc.tke, true)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "c1",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m")},
+					},
+				},
+			},
+		},
+	}
+
+	// the local ladder gives 250m/0.5Gi, the api spec 1/2Gi is trusted
+	spec, err := converter.Pod2EKSSpecConverter(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Cpu().Cmp(resource.MustParse("1")) != 0 || spec.Memory().Cmp(resource.MustParse("2Gi")) != 0 {
+		t.Errorf("expect the api spec 1/2Gi, got %v/%v", spec.Cpu().String(), spec.Memory().String())
+	}
+	if calls := server.Calls("GetPodSpecification"); len(calls) != 1 {
+		t.Errorf("expect 1 GetPodSpecification call, got %v", len(calls))
+	}
+
+	// the api converts the gpu pods the ladder can not without validation, the local spec is used for the others
+	converter = NewEKSSpecConverter(tc.tke, false)
+	if spec, err = converter.Pod2EKSSpecConverter(pod); err != nil || spec.Cpu().Cmp(resource.MustParse("250m")) != 0 {
+		t.Errorf("expect the local spec 250m, got %v, err: %v", spec.Cpu().String(), err)
+	}
+	pod.Annotations = map[string]string{EKSAnnoGpuType: "V100"}
+	if spec, err = converter.Pod2EKSSpecConverter(pod); err != nil || spec.Cpu().Cmp(resource.MustParse("1")) != 0 {
+		t.Errorf("expect the api spec 1 of the gpu pod, got %v, err: %v", spec.Cpu().String(), err)
+	}
+	if calls := server.Calls("GetPodSpecification"); len(calls) != 2 {
+		t.Errorf("expect 2 GetPodSpecification calls, got %v", len(calls))
+	}
+}
@@ -7,7 +7,6 @@ import (
 
 	gcfg "gopkg.in/gcfg.v1"
 
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/klog/v2"
 
 	"github.com/gocrane/fadvisor/pkg/cache"
@@ -18,11 +17,20 @@ import (
 )
 
 func registerTencent(cloudConfig io.Reader, priceConfig *cloud.PriceConfig, cache *cache.Cache) (cloud.Cloud, error) {
-	var qcloudClientConfig *qcloudsdk.QCloudClientConfig
-	var err error
-	if qcloudClientConfig, err = buildClientConfig(cloudConfig); err != nil {
+	cfg, err := loadCloudConfig(cloudConfig)
+	if err != nil {
 		return nil, err
 	}
+	qcloudClientConfig, err := newClientConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	eksPriceCacheTTL := defaultEKSPriceCacheTTL
+	if cfg.EKSPriceCache.Ttl != "" {
+		if eksPriceCacheTTL, err = time.ParseDuration(cfg.EKSPriceCache.Ttl); err != nil {
+			return nil, fmt.Errorf("invalid eks price cache ttl %v: %v", cfg.EKSPriceCache.Ttl, err)
+		}
+	}
 	if qcloudClientConfig.Region == "" {
 		if cache == nil {
 			return nil, fmt.Errorf("client cache should not be empty")
@@ -39,15 +47,45 @@ func registerTencent(cloudConfig io.Reader, priceConfig *cloud.PriceConfig, cach
 	}
 	klog.V(4).Infof("Cloud config detail: %+v", qcloudClientConfig.QCloudClientProfile)
 	p := NewTencentCloud(qcloudClientConfig, priceConfig, *cache)
+	p.(*TencentCloud).eksPriceCache = NewEKSPriceCache(eksPriceCacheTTL, cfg.EKSPriceCache.File)
+	// the specs the api converted are persisted with the prices
+	p.(*TencentCloud).tke.SetSpecStore(p.(*TencentCloud).eksPriceCache)
+	p.(*TencentCloud).eksConverter = NewEKSSpecConverter(p.(*TencentCloud).tke, cfg.EKSSpec.Validate)
+	// the static region table maybe out of date, it is not fatal if failed
+	if err := p.(*TencentCloud).cvm.DiscoverRegions(qcloudsdk.DefaultRegionResolver()); err != nil {
+		klog.Warningf("Failed to discover regions, use the static region table: %v", err)
+	}
 	return p, nil
 }
 
-func buildClientConfig(cloudConfig io.Reader) (*qcloudsdk.QCloudClientConfig, error) {
+func loadCloudConfig(cloudConfig io.Reader) (*CloudConfig, error) {
 	var cfg CloudConfig
 	if err := gcfg.FatalOnly(gcfg.ReadInto(&cfg, cloudConfig)); err != nil {
 		klog.Errorf("Failed to read TencentCloud configuration file: %v", err)
 		return nil, err
 	}
+	return &cfg, nil
+}
+
+func buildClientConfig(cloudConfig io.Reader) (*qcloudsdk.QCloudClientConfig, error) {
+	cfg, err := loadCloudConfig(cloudConfig)
+	if err != nil {
+		return nil, err
+	}
+	return newClientConfig(cfg)
+}
+
+func newClientConfig(cfg *CloudConfig) (*qcloudsdk.QCloudClientConfig, error) {
+	setRegionOverrides(cfg.RegionOverrides)
+
+	governorConfig, err := newGovernorConfig(cfg.Governor, cfg.GovernorServices)
+	if err != nil {
+		return nil, err
+	}
+	// the governor is shared with the other tencent cloud api clients, such as the qcloud monitor datasource
+	governor := qcloudsdk.SharedRequestGovernor()
+	governor.SetConfig(governorConfig)
+
 	qccp := qcloudsdk.QCloudClientProfile{
 		Debug:           cfg.Debug,
 		DefaultLanguage: cfg.DefaultLanguage,
@@ -56,11 +94,30 @@ func buildClientConfig(cloudConfig io.Reader) (*qcloudsdk.QCloudClientConfig, er
 		Region:          cfg.Region,
 		DomainSuffix:    cfg.DomainSuffix,
 		Scheme:          cfg.Scheme,
+		Endpoint:        cfg.Endpoint,
 	}
 
-	cred := credential.NewQCloudCredential(cfg.ClusterId, cfg.AppId, cfg.SecretId, cfg.SecretKey, 1*time.Hour)
+	cred, err := credential.NewCredentialFromConfig(credential.Config{
+		ClusterId:           cfg.ClusterId,
+		AppId:               cfg.AppId,
+		SecretId:            cfg.SecretId,
+		SecretKey:           cfg.SecretKey,
+		Source:              cfg.Source,
+		SecretDir:           cfg.SecretDir,
+		RoleArn:             cfg.RoleArn,
+		RoleSessionName:     cfg.RoleSessionName,
+		RoleDurationSeconds: cfg.RoleDurationSeconds,
+		InstanceRoleName:    cfg.InstanceRoleName,
+		MetadataEndpoint:    cfg.MetadataEndpoint,
+		Region:              cfg.Region,
+		DomainSuffix:        cfg.DomainSuffix,
+		Scheme:              cfg.Scheme,
+	})
+	if err != nil {
+		return nil, err
+	}
 	qcc := &qcloudsdk.QCloudClientConfig{
-		RateLimiter:         flowcontrol.NewTokenBucketRateLimiter(5, 1),
+		Governor:            governor,
 		DefaultRetryCnt:     consts.MAXRETRY,
 		QCloudClientProfile: qccp,
 		Credential:          cred,
@@ -68,6 +125,74 @@ func buildClientConfig(cloudConfig io.Reader) (*qcloudsdk.QCloudClientConfig, er
 	return qcc, nil
 }
 
+// newGovernorConfig overlays the configured fields on the default governor config
+func newGovernorConfig(cfg GovernorConfig, services map[string]*GovernorServiceConfig) (qcloudsdk.GovernorConfig, error) {
+	config := qcloudsdk.DefaultGovernorConfig()
+	if cfg.Qps > 0 {
+		config.QPS = float32(cfg.Qps)
+	}
+	if cfg.Burst > 0 {
+		config.Burst = cfg.Burst
+	}
+	if cfg.ActionQps > 0 {
+		config.ActionQPS = float32(cfg.ActionQps)
+	}
+	if cfg.ActionBurst > 0 {
+		config.ActionBurst = cfg.ActionBurst
+	}
+	if cfg.FailureThreshold > 0 {
+		config.FailureThreshold = cfg.FailureThreshold
+	}
+	durations := []struct {
+		name  string
+		value string
+		d     *time.Duration
+	}{
+		{"openDuration", cfg.OpenDuration, &config.OpenDuration},
+		{"baseBackoff", cfg.BaseBackoff, &config.BaseBackoff},
+		{"maxBackoff", cfg.MaxBackoff, &config.MaxBackoff},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			return config, fmt.Errorf("invalid governor %v %v", d.name, d.value)
+		}
+		*d.d = v
+	}
+	for service, s := range services {
+		if s == nil {
+			continue
+		}
+		limit := config.ServiceLimits[service]
+		if s.Qps > 0 {
+			limit.QPS = float32(s.Qps)
+		}
+		if s.Burst > 0 {
+			limit.Burst = s.Burst
+		}
+		if limit.QPS <= 0 || limit.Burst <= 0 {
+			return config, fmt.Errorf("governor service %v should have both qps and burst", service)
+		}
+		config.ServiceLimits[service] = limit
+	}
+	return config, nil
+}
+
+func setRegionOverrides(overrides map[string]*RegionOverride) {
+	regions := make(map[string]qcloudsdk.RegionInfo, len(overrides))
+	for name, override := range overrides {
+		if override == nil || override.Region == "" {
+			klog.Warningf("Ignore region override %v without region", name)
+			continue
+		}
+		regions[name] = qcloudsdk.RegionInfo{Region: override.Region, RegionName: override.RegionName}
+	}
+	qcloudsdk.DefaultRegionResolver().SetOverrides(regions)
+}
+
 func init() {
 	cloud.RegisterCloudProvider(cloud.TencentCloud, registerTencent)
 }
@@ -1,6 +1,8 @@
 package qcloud
 
 import (
+	"fmt"
+
 	"github.com/gocrane/fadvisor/pkg/cloud"
 )
 
@@ -25,24 +27,30 @@ var (
 	}
 )
 
-type TKEPlatform struct {
-}
-
-func (tp *TKEPlatform) PlatformCost(cp cloud.PlatformParameter) *cloud.Prices {
-	if cp.Nodes == nil {
-		return &cloud.Prices{
-			TotalPrice: defaultClusterPriceModel[0].PriceHourly,
-		}
+// defaultTKEFeeModel is built from defaultClusterPriceModel, the cluster level is L + max nodes, such as L5
+func defaultTKEFeeModel() cloud.PlatformFeeModel {
+	model := cloud.PlatformFeeModel{
+		ClusterLevels: make(map[string]float64, len(defaultClusterPriceModel)),
 	}
-	clusterRealNodes := *cp.Nodes
 	for _, unit := range defaultClusterPriceModel {
-		if clusterRealNodes <= unit.Nodes {
-			return &cloud.Prices{
-				TotalPrice: unit.PriceHourly,
-			}
-		}
+		model.Tiers = append(model.Tiers, cloud.PlatformFeeTier{Nodes: unit.Nodes, PriceHourly: unit.PriceHourly})
+		model.ClusterLevels[fmt.Sprintf("L%d", unit.Nodes)] = unit.PriceHourly
 	}
-	return &cloud.Prices{
-		TotalPrice: defaultClusterPriceModel[len(defaultClusterPriceModel)-1].PriceHourly,
+	return model
+}
+
+type TKEPlatform struct {
+	defaultModel cloud.PlatformFeeModel
+}
+
+func NewTKEPlatform() *TKEPlatform {
+	return &TKEPlatform{defaultModel: defaultTKEFeeModel()}
+}
+
+func (tp *TKEPlatform) PlatformCost(cfg *cloud.CustomPricing, cp cloud.PlatformParameter) *cloud.Prices {
+	model := tp.defaultModel
+	if cfg != nil {
+		model = cfg.PlatformFeeModel(cloud.ServerfulKind, tp.defaultModel)
 	}
+	return model.Price(cp)
 }
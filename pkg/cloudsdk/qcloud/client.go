@@ -3,8 +3,6 @@ package qcloud
 import (
 	"time"
 
-	"k8s.io/client-go/util/flowcontrol"
-
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/regions"
 
 	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/credential"
@@ -53,13 +51,9 @@ var QcloudRegions = []string{
 	regions.Toronto,
 }
 
-var ShortName2region = map[string]struct {
-	RegionId        int
-	Region          string
-	RegionName      string
-	Area            string
-	RegionShortName string
-}{
+// ShortName2region is the static region table, key is the region short name in the node label, such as gz.
+// it may be out of date, use ResolveRegion which merges the regions discovered from cloud api.
+var ShortName2region = map[string]RegionInfo{
 	"gz": {
 		RegionId:        1,
 		Region:          "ap-guangzhou",
@@ -314,13 +308,7 @@ var ShortName2region = map[string]struct {
 	},
 }
 
-var Region2shortNameRegion = map[string]struct {
-	RegionId        int
-	Region          string
-	RegionName      string
-	Area            string
-	RegionShortName string
-}{
+var Region2shortNameRegion = map[string]RegionInfo{
 	"ap-guangzhou": {
 		RegionId:        1,
 		Region:          "ap-guangzhou",
@@ -583,15 +571,25 @@ type QCloudClientProfile struct {
 	Region          string
 	DomainSuffix    string
 	Scheme          string
+	// Endpoint overrides the domain of all the services if it is specified, such as a local fake server
+	Endpoint string
 }
 
 type QCloudClientConfig struct {
-	RateLimiter     flowcontrol.RateLimiter
+	// Governor governs the requests of the clients, SharedRequestGovernor is used if it is nil
+	Governor        *RequestGovernor
 	DefaultRetryCnt int
 	Credential      credential.QCloudCredential
 	QCloudClientProfile
 }
 
+func (qcc *QCloudClientConfig) RequestGovernor() *RequestGovernor {
+	if qcc.Governor == nil {
+		return SharedRequestGovernor()
+	}
+	return qcc.Governor
+}
+
 const (
 	//默认值：POSTPAID_BY_HOUR
 	INSTANCECHARGETYPE_PREPAID          = "PREPAID"          //包年包月
@@ -1 +1,161 @@
 package credential
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+func writeSecret(t *testing.T, dir, id, key string) {
+	if err := ioutil.WriteFile(filepath.Join(dir, SecretFileSecretId), []byte(id+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, SecretFileSecretKey), []byte(key), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileCredentialRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fadvisor-credential")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeSecret(t, dir, "id1", "key1")
+
+	cred, err := NewCredentialFromConfig(Config{Source: SourceFile, SecretDir: dir, SecretReloadInterval: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cred.(*RefreshingCredential).now = func() time.Time { return now }
+
+	c := cred.GetQCloudCredential()
+	if c.SecretId != "id1" || c.SecretKey != "key1" || c.Token != "" {
+		t.Fatalf("unexpected credential %+v", c)
+	}
+
+	writeSecret(t, dir, "id2", "key2")
+	if c := cred.GetQCloudCredential(); c.SecretId != "id1" {
+		t.Errorf("expect cached credential before reload interval, got %+v", c)
+	}
+	now = now.Add(2 * time.Minute)
+	if c := cred.GetQCloudCredential(); c.SecretId != "id2" || c.SecretKey != "key2" {
+		t.Errorf("expect rotated credential, got %+v", c)
+	}
+
+	// a broken secret keeps the last credential
+	os.Remove(filepath.Join(dir, SecretFileSecretKey))
+	now = now.Add(2 * time.Minute)
+	if c := cred.GetQCloudCredential(); c.SecretId != "id2" {
+		t.Errorf("expect last credential kept, got %+v", c)
+	}
+}
+
+func TestInstanceRoleCredential(t *testing.T) {
+	expired := time.Now().Add(time.Hour)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cam/security-credentials/":
+			fmt.Fprint(w, "fadvisor-role")
+		case "/cam/security-credentials/fadvisor-role":
+			requests++
+			fmt.Fprintf(w, `{"TmpSecretId":"tmp-id-%d","TmpSecretKey":"tmp-key","Token":"token","ExpiredTime":%d,"Code":"Success"}`, requests, expired.Unix())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cred, err := NewCredentialFromConfig(Config{Source: SourceInstanceRole, MetadataEndpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cred.(*RefreshingCredential).now = func() time.Time { return now }
+
+	c := cred.GetQCloudCredential()
+	if c.SecretId != "tmp-id-1" || c.SecretKey != "tmp-key" || c.Token != "token" {
+		t.Fatalf("unexpected credential %+v", c)
+	}
+	if c := cred.GetQCloudCredential(); c.SecretId != "tmp-id-1" {
+		t.Errorf("expect cached credential, got %+v", c)
+	}
+
+	// refreshed before it expires
+	now = expired.Add(-DefaultRefreshAhead)
+	if c := cred.GetQCloudCredential(); c.SecretId != "tmp-id-2" {
+		t.Errorf("expect refreshed credential, got %+v", c)
+	}
+}
+
+// blockingProvider blocks the retrieval until release is closed, and counts the retrievals
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+	calls   int32
+}
+
+func (p *blockingProvider) Retrieve() (*common.Credential, time.Time, error) {
+	if atomic.AddInt32(&p.calls, 1) == 1 {
+		close(p.started)
+	}
+	<-p.release
+	return &common.Credential{SecretId: "id", SecretKey: "key"}, time.Time{}, nil
+}
+
+func TestRefreshingCredentialRetrieveWithoutLock(t *testing.T) {
+	provider := &blockingProvider{started: make(chan struct{}), release: make(chan struct{})}
+	cred := NewRefreshingCredential(provider, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c := cred.GetQCloudCredential(); c.SecretId != "id" {
+				t.Errorf("expect retrieved credential, got %+v", c)
+			}
+		}()
+	}
+	<-provider.started
+
+	// the lock is not held by the blocked retrieval
+	done := make(chan struct{})
+	go func() {
+		cred.lock.Lock()
+		cred.lock.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expect the lock not held when retrieving")
+	}
+
+	close(provider.release)
+	wg.Wait()
+	calls := atomic.LoadInt32(&provider.calls)
+	if c := cred.GetQCloudCredential(); c.SecretId != "id" || atomic.LoadInt32(&provider.calls) != calls {
+		t.Errorf("expect cached credential without retrieval, got %+v", c)
+	}
+}
+
+func TestUnknownCredentialSource(t *testing.T) {
+	if _, err := NewCredentialFromConfig(Config{Source: "unknown"}); err == nil {
+		t.Errorf("expect error for unknown source")
+	}
+	if _, err := NewCredentialFromConfig(Config{Source: SourceSTS}); err == nil {
+		t.Errorf("expect error for sts source without role arn")
+	}
+}
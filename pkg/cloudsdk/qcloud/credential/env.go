@@ -0,0 +1,43 @@
+package credential
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+const (
+	EnvSecretId     = "TENCENTCLOUD_SECRET_ID"
+	EnvSecretKey    = "TENCENTCLOUD_SECRET_KEY"
+	EnvSessionToken = "TENCENTCLOUD_SESSION_TOKEN"
+)
+
+// EnvProvider retrieves the credential from environment variables
+type EnvProvider struct {
+	secretIdEnv  string
+	secretKeyEnv string
+	tokenEnv     string
+}
+
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{
+		secretIdEnv:  EnvSecretId,
+		secretKeyEnv: EnvSecretKey,
+		tokenEnv:     EnvSessionToken,
+	}
+}
+
+func (p *EnvProvider) Retrieve() (*common.Credential, time.Time, error) {
+	secretId := os.Getenv(p.secretIdEnv)
+	secretKey := os.Getenv(p.secretKeyEnv)
+	if secretId == "" || secretKey == "" {
+		return nil, time.Time{}, fmt.Errorf("environment variable %v or %v is empty", p.secretIdEnv, p.secretKeyEnv)
+	}
+	return &common.Credential{
+		SecretId:  secretId,
+		SecretKey: secretKey,
+		Token:     os.Getenv(p.tokenEnv),
+	}, time.Time{}, nil
+}
@@ -0,0 +1,72 @@
+package credential
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+const (
+	SecretFileSecretId  = "secretId"
+	SecretFileSecretKey = "secretKey"
+	SecretFileToken     = "token"
+
+	DefaultSecretReloadInterval = time.Minute
+)
+
+// FileProvider retrieves the credential from a mounted kubernetes secret directory,
+// each key of the secret is a file. The credential expires after reloadInterval, so a rotated secret is re-read.
+type FileProvider struct {
+	dir            string
+	reloadInterval time.Duration
+}
+
+func NewFileProvider(dir string, reloadInterval time.Duration) *FileProvider {
+	if reloadInterval <= 0 {
+		reloadInterval = DefaultSecretReloadInterval
+	}
+	return &FileProvider{
+		dir:            dir,
+		reloadInterval: reloadInterval,
+	}
+}
+
+func (p *FileProvider) Retrieve() (*common.Credential, time.Time, error) {
+	secretId, err := p.readKey(SecretFileSecretId, true)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	secretKey, err := p.readKey(SecretFileSecretKey, true)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	token, err := p.readKey(SecretFileToken, false)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return &common.Credential{
+		SecretId:  secretId,
+		SecretKey: secretKey,
+		Token:     token,
+	}, time.Now().Add(p.reloadInterval), nil
+}
+
+func (p *FileProvider) readKey(key string, required bool) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(p.dir, key))
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return "", nil
+		}
+		return "", err
+	}
+	value := strings.TrimSpace(string(content))
+	if value == "" && required {
+		return "", fmt.Errorf("secret file %v is empty", filepath.Join(p.dir, key))
+	}
+	return value, nil
+}
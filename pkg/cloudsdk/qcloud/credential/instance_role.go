@@ -0,0 +1,91 @@
+package credential
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+const (
+	// https://cloud.tencent.com/document/product/213/4934
+	DefaultMetadataEndpoint = "http://metadata.tencentyun.com/latest/meta-data/"
+
+	metadataRolePath = "cam/security-credentials/"
+	metadataTimeout  = 10 * time.Second
+)
+
+type instanceRoleResponse struct {
+	TmpSecretId  string `json:"TmpSecretId"`
+	TmpSecretKey string `json:"TmpSecretKey"`
+	ExpiredTime  int64  `json:"ExpiredTime"`
+	Token        string `json:"Token"`
+	Code         string `json:"Code"`
+}
+
+// InstanceRoleProvider retrieves the temporary credential of the cam role bound to the cvm instance from the metadata endpoint
+type InstanceRoleProvider struct {
+	endpoint string
+	roleName string
+	client   *http.Client
+}
+
+func NewInstanceRoleProvider(endpoint, roleName string) *InstanceRoleProvider {
+	if endpoint == "" {
+		endpoint = DefaultMetadataEndpoint
+	}
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint = endpoint + "/"
+	}
+	return &InstanceRoleProvider{
+		endpoint: endpoint,
+		roleName: roleName,
+		client:   &http.Client{Timeout: metadataTimeout},
+	}
+}
+
+func (p *InstanceRoleProvider) get(path string) ([]byte, error) {
+	resp, err := p.client.Get(p.endpoint + path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no cam role bound to the instance, path: %v", path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata endpoint returned status %v, path: %v", resp.StatusCode, path)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (p *InstanceRoleProvider) Retrieve() (*common.Credential, time.Time, error) {
+	roleName := p.roleName
+	if roleName == "" {
+		body, err := p.get(metadataRolePath)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		roleName = strings.TrimSpace(string(body))
+	}
+	body, err := p.get(metadataRolePath + roleName)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	resp := &instanceRoleResponse{}
+	if err = json.Unmarshal(body, resp); err != nil {
+		return nil, time.Time{}, err
+	}
+	if resp.Code != "Success" {
+		return nil, time.Time{}, fmt.Errorf("get credential of role %v from metadata endpoint failed, code: %v", roleName, resp.Code)
+	}
+	return &common.Credential{
+		SecretId:  resp.TmpSecretId,
+		SecretKey: resp.TmpSecretKey,
+		Token:     resp.Token,
+	}, time.Unix(resp.ExpiredTime, 0), nil
+}
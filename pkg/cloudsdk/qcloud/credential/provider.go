@@ -0,0 +1,195 @@
+package credential
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// SourceStatic use the SecretId and SecretKey in the config file, it is the default source
+	SourceStatic = "static"
+	// SourceEnv use the credential in environment variables TENCENTCLOUD_SECRET_ID, TENCENTCLOUD_SECRET_KEY and TENCENTCLOUD_SESSION_TOKEN
+	SourceEnv = "env"
+	// SourceFile use the credential in a mounted secret directory, it is re-read periodically so the secret can be rotated
+	SourceFile = "file"
+	// SourceSTS assume a cam role by sts AssumeRole, the temporary credential is refreshed before it expires
+	SourceSTS = "sts"
+	// SourceInstanceRole use the cam role bound to the cvm or tke node by the instance metadata endpoint
+	SourceInstanceRole = "instancerole"
+
+	// DefaultRefreshAhead is how long before the temporary credential expires it is refreshed
+	DefaultRefreshAhead = 5 * time.Minute
+	// DefaultRoleDurationSeconds is the lifetime of the temporary credential assumed by sts if RoleDurationSeconds is not specified
+	DefaultRoleDurationSeconds = 3600
+)
+
+// Provider retrieves a credential and the time it expires, zero expiration means it never expires
+type Provider interface {
+	Retrieve() (*common.Credential, time.Time, error)
+}
+
+// Config is the credential config of the qcloud clients
+type Config struct {
+	ClusterId string
+	AppId     string
+	SecretId  string
+	SecretKey string
+	// Source of the credential, static, env, file, sts or instancerole. default is static
+	Source string
+	// SecretDir is the mounted secret directory for file source, it has files named secretId, secretKey and optional token
+	SecretDir string
+	// SecretReloadInterval is the interval to re-read SecretDir, default is one minute
+	SecretReloadInterval time.Duration
+	// RoleArn, RoleSessionName and RoleDurationSeconds are used by sts source
+	RoleArn             string
+	RoleSessionName     string
+	RoleDurationSeconds int64
+	// InstanceRoleName is the role bound to the instance, it is detected from metadata endpoint if empty
+	InstanceRoleName string
+	// MetadataEndpoint is the instance metadata endpoint, default is http://metadata.tencentyun.com/latest/meta-data/
+	MetadataEndpoint string
+	// Region, DomainSuffix and Scheme of the sts endpoint
+	Region       string
+	DomainSuffix string
+	Scheme       string
+}
+
+// NewCredentialFromConfig return a QCloudCredential of the source in the config
+func NewCredentialFromConfig(cfg Config) (QCloudCredential, error) {
+	switch strings.ToLower(cfg.Source) {
+	case "", SourceStatic:
+		// the static credential never expires
+		return NewQCloudCredential(cfg.ClusterId, cfg.AppId, cfg.SecretId, cfg.SecretKey, 0), nil
+	case SourceEnv:
+		return NewRefreshingCredential(NewEnvProvider(), 0), nil
+	case SourceFile:
+		if cfg.SecretDir == "" {
+			return nil, fmt.Errorf("secret dir must be specified for credential source %v", cfg.Source)
+		}
+		return NewRefreshingCredential(NewFileProvider(cfg.SecretDir, cfg.SecretReloadInterval), 0), nil
+	case SourceSTS:
+		if cfg.RoleArn == "" {
+			return nil, fmt.Errorf("role arn must be specified for credential source %v", cfg.Source)
+		}
+		duration := cfg.RoleDurationSeconds
+		if duration <= 0 {
+			duration = DefaultRoleDurationSeconds
+		}
+		provider := NewAssumeRoleProvider(stsBaseCredential(cfg), cfg.RoleArn, cfg.RoleSessionName, duration, cfg.Region, cfg.DomainSuffix, cfg.Scheme)
+		return NewRefreshingCredential(provider, DefaultRefreshAhead), nil
+	case SourceInstanceRole:
+		return NewRefreshingCredential(NewInstanceRoleProvider(cfg.MetadataEndpoint, cfg.InstanceRoleName), DefaultRefreshAhead), nil
+	default:
+		return nil, fmt.Errorf("unknown credential source %v", cfg.Source)
+	}
+}
+
+// stsBaseCredential is the long-lived credential to call sts, it is from the config file, the secret dir or the environment variables in order
+func stsBaseCredential(cfg Config) QCloudCredential {
+	if cfg.SecretId != "" && cfg.SecretKey != "" {
+		return NewQCloudCredential(cfg.ClusterId, cfg.AppId, cfg.SecretId, cfg.SecretKey, 0)
+	}
+	if cfg.SecretDir != "" {
+		return NewRefreshingCredential(NewFileProvider(cfg.SecretDir, cfg.SecretReloadInterval), 0)
+	}
+	return NewRefreshingCredential(NewEnvProvider(), 0)
+}
+
+// RefreshingCredential caches the credential retrieved from the provider, and retrieves a new one when it is going to expire.
+// The lock is not held when retrieving, so the callers are not blocked by the network call if the cached credential is still valid
+// to refresh. The concurrent retrievals are coalesced.
+type RefreshingCredential struct {
+	lock         sync.Mutex
+	provider     Provider
+	refreshAhead time.Duration
+	credential   *common.Credential
+	expiration   time.Time
+	// custom credential updated by UpdateQCloudCustomCredential, it has priority
+	custom     *common.Credential
+	refreshing singleflight.Group
+
+	// for test
+	now func() time.Time
+}
+
+func NewRefreshingCredential(provider Provider, refreshAhead time.Duration) *RefreshingCredential {
+	return &RefreshingCredential{
+		provider:     provider,
+		refreshAhead: refreshAhead,
+		now:          time.Now,
+	}
+}
+
+func (cred *RefreshingCredential) expired() bool {
+	if cred.credential == nil {
+		return true
+	}
+	if cred.expiration.IsZero() {
+		return false
+	}
+	return !cred.now().Before(cred.expiration.Add(-cred.refreshAhead))
+}
+
+func (cred *RefreshingCredential) GetQCloudCredential() *common.Credential {
+	if credential, ok := cred.cached(); ok {
+		return credential
+	}
+	cred.refresh()
+	credential, _ := cred.cached()
+	return credential
+}
+
+// cached return a copy of the custom or cached credential, it returns false if the cached credential is going to expire
+func (cred *RefreshingCredential) cached() (*common.Credential, bool) {
+	cred.lock.Lock()
+	defer cred.lock.Unlock()
+
+	if cred.custom != nil {
+		return copyCredential(cred.custom), true
+	}
+	if cred.credential == nil {
+		return &common.Credential{}, false
+	}
+	return copyCredential(cred.credential), !cred.expired()
+}
+
+// refresh retrieves a credential from the provider without the lock and swaps it in, the concurrent refreshes share one retrieval
+func (cred *RefreshingCredential) refresh() {
+	cred.refreshing.Do("", func() (interface{}, error) {
+		credential, expiration, err := cred.provider.Retrieve()
+		if err != nil {
+			// keep the old credential, it maybe still valid
+			klog.Errorf("Failed to refresh qcloud credential: %v", err)
+			return nil, err
+		}
+		cred.lock.Lock()
+		cred.credential = credential
+		cred.expiration = expiration
+		cred.lock.Unlock()
+		klog.V(4).Infof("Refreshed qcloud credential, expiration: %v", expiration)
+		return nil, nil
+	})
+}
+
+func (cred *RefreshingCredential) UpdateQCloudCustomCredential(secretId, secretKey string) *common.Credential {
+	cred.lock.Lock()
+	defer cred.lock.Unlock()
+
+	cred.custom = &common.Credential{SecretId: secretId, SecretKey: secretKey}
+	return copyCredential(cred.custom)
+}
+
+func copyCredential(cred *common.Credential) *common.Credential {
+	return &common.Credential{
+		SecretId:  cred.SecretId,
+		SecretKey: cred.SecretKey,
+		Token:     cred.Token,
+	}
+}
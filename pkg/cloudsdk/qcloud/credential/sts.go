@@ -0,0 +1,106 @@
+package credential
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+)
+
+const (
+	stsService        = "sts"
+	stsVersion        = "2018-08-13"
+	stsActionAssume   = "AssumeRole"
+	stsDefaultRegion  = "ap-guangzhou"
+	stsDefaultSuffix  = "tencentcloudapi.com"
+	stsSessionPrefix  = "fadvisor-"
+	stsMaxDurationSec = 43200
+)
+
+type assumeRoleResponse struct {
+	Response struct {
+		Credentials struct {
+			Token        string `json:"Token"`
+			TmpSecretId  string `json:"TmpSecretId"`
+			TmpSecretKey string `json:"TmpSecretKey"`
+		} `json:"Credentials"`
+		ExpiredTime int64  `json:"ExpiredTime"`
+		RequestId   string `json:"RequestId"`
+	} `json:"Response"`
+}
+
+// AssumeRoleProvider retrieves a temporary credential of a cam role by sts AssumeRole
+type AssumeRoleProvider struct {
+	base            QCloudCredential
+	roleArn         string
+	roleSessionName string
+	durationSeconds int64
+	region          string
+	endpoint        string
+	scheme          string
+}
+
+func NewAssumeRoleProvider(base QCloudCredential, roleArn, roleSessionName string, durationSeconds int64, region, domainSuffix, scheme string) *AssumeRoleProvider {
+	if roleSessionName == "" {
+		roleSessionName = stsSessionPrefix + strconv.FormatInt(time.Now().Unix(), 10)
+	}
+	if durationSeconds <= 0 || durationSeconds > stsMaxDurationSec {
+		durationSeconds = stsMaxDurationSec
+	}
+	if region == "" {
+		region = stsDefaultRegion
+	}
+	if domainSuffix == "" {
+		domainSuffix = stsDefaultSuffix
+	}
+	return &AssumeRoleProvider{
+		base:            base,
+		roleArn:         roleArn,
+		roleSessionName: roleSessionName,
+		durationSeconds: durationSeconds,
+		region:          region,
+		endpoint:        fmt.Sprintf("%v.%v", stsService, domainSuffix),
+		scheme:          scheme,
+	}
+}
+
+func (p *AssumeRoleProvider) Retrieve() (*common.Credential, time.Time, error) {
+	prof := profile.NewClientProfile()
+	prof.HttpProfile.Endpoint = p.endpoint
+	prof.HttpProfile.ReqMethod = "POST"
+	if p.scheme != "" {
+		prof.HttpProfile.Scheme = p.scheme
+	}
+	client := common.NewCommonClient(p.base.GetQCloudCredential(), p.region, prof)
+
+	request := tchttp.NewCommonRequest(stsService, stsVersion, stsActionAssume)
+	err := request.SetActionParameters(map[string]interface{}{
+		"RoleArn":         p.roleArn,
+		"RoleSessionName": p.roleSessionName,
+		"DurationSeconds": p.durationSeconds,
+	})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	response := tchttp.NewCommonResponse()
+	if err = client.Send(request, response); err != nil {
+		return nil, time.Time{}, fmt.Errorf("sts assume role %v failed: %v", p.roleArn, err)
+	}
+	resp := &assumeRoleResponse{}
+	if err = json.Unmarshal(response.GetBody(), resp); err != nil {
+		return nil, time.Time{}, err
+	}
+	creds := resp.Response.Credentials
+	if creds.TmpSecretId == "" || creds.TmpSecretKey == "" {
+		return nil, time.Time{}, fmt.Errorf("sts assume role %v returned empty credential, request id: %v", p.roleArn, resp.Response.RequestId)
+	}
+	return &common.Credential{
+		SecretId:  creds.TmpSecretId,
+		SecretKey: creds.TmpSecretKey,
+		Token:     creds.Token,
+	}, time.Unix(resp.Response.ExpiredTime, 0), nil
+}
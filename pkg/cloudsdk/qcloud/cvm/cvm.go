@@ -6,7 +6,6 @@ import (
 	"sync"
 	"time"
 
-	"k8s.io/apimachinery/pkg/util/rand"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/klog/v2"
 
@@ -41,6 +40,9 @@ func NewCVMClient(qcc *qcloud.QCloudClientConfig) *CVMClient {
 }
 
 func (qcc *CVMClient) getCVMDomain() string {
+	if qcc.config.Endpoint != "" {
+		return qcc.config.Endpoint
+	}
 	return fmt.Sprintf("%v.%v", "cvm", qcc.config.DomainSuffix)
 }
 
@@ -51,28 +53,11 @@ func (qcc *CVMClient) UpdateCredential(cred credential.QCloudCredential) {
 }
 
 func (qcc *CVMClient) ExponentialRetryCall(retryCnt int, f retryFunc, request interface{}) (interface{}, error) {
-	var err error
-	var resp interface{}
-
-	// blocking
-	qcc.config.RateLimiter.Accept()
-
-	resp, err = f(request)
-	if err == nil {
-		return resp, nil
+	req, ok := request.(qcloud.GovernedRequest)
+	if !ok {
+		return nil, fmt.Errorf("qcloudClient unknown request type %T", request)
 	}
-	for i := 1; i <= retryCnt; i++ {
-		klog.Errorf("qcloudClient tencent cloud api failed, retrying %v times, qps:%v, err: %v", i, qcc.config.RateLimiter.QPS(), err)
-		randInt := rand.IntnRange(2<<i, 2<<(i+1)+1)
-		sleepTime := time.Duration(randInt) * time.Second
-		time.Sleep(sleepTime)
-		qcc.config.RateLimiter.Accept()
-		resp, err = f(request)
-		if err == nil {
-			return resp, nil
-		}
-	}
-	return nil, fmt.Errorf("qcloudClient tencent cloud api retry failed after retry %v times, err: %s", retryCnt, err)
+	return qcc.config.RequestGovernor().Call(retryCnt, qcloud.RetryFunc(f), req)
 }
 
 func (qcc *CVMClient) UpdateCred(cred credential.QCloudCredential) {
@@ -118,6 +103,27 @@ func (qcc *CVMClient) getAllRegions() ([]*cvm.RegionInfo, error) {
 	return resp.Response.RegionSet, nil
 }
 
+// DiscoverRegions loads all the regions by DescribeRegions to the resolver, so the nodes in new regions which are not in the static table can be resolved
+func (qcc *CVMClient) DiscoverRegions(resolver *qcloud.RegionResolver) error {
+	regionInfos, err := qcc.getAllRegions()
+	if err != nil {
+		return err
+	}
+	regions := make([]qcloud.RegionInfo, 0, len(regionInfos))
+	for _, info := range regionInfos {
+		if info.Region == nil {
+			continue
+		}
+		region := qcloud.RegionInfo{Region: *info.Region}
+		if info.RegionName != nil {
+			region.RegionName = *info.RegionName
+		}
+		regions = append(regions, region)
+	}
+	resolver.SetDiscoveredRegions(regions)
+	return nil
+}
+
 func (qcc *CVMClient) getDefaultClient() (*cvm.Client, error) {
 	cred := qcc.getQCloudCredential()
 	var err error
@@ -163,6 +169,8 @@ func (qcc *CVMClient) getClient() (*cvm.Client, error) {
 			return qcc.client, err
 		}
 	}
+	// the credential maybe refreshed or rotated
+	qcc.client.WithCredential(cred)
 	if qcc.config.Debug {
 		SecretId := cred.GetSecretId()
 		SecretKey := cred.GetSecretKey()
@@ -0,0 +1,238 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud"
+	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/consts"
+	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/credential"
+)
+
+const (
+	headerAction  = "X-TC-Action"
+	headerVersion = "X-TC-Version"
+	headerRegion  = "X-TC-Region"
+)
+
+// Fixture is a scripted result of an api action
+type Fixture struct {
+	// Response is the content of the Response field of the api result, RequestId is filled by the server
+	Response json.RawMessage `json:"response,omitempty"`
+	// Error makes the action fail with the code and message
+	Error *FixtureError `json:"error,omitempty"`
+	// HttpStatus other than 200 simulates a failure of the api gateway
+	HttpStatus int `json:"httpStatus,omitempty"`
+}
+
+type FixtureError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Fixtures are the scripted results of each action, key is the action name, such as DescribeInstances
+type Fixtures map[string][]Fixture
+
+// Call is a request received by the server
+type Call struct {
+	Action  string
+	Version string
+	Region  string
+	Params  map[string]interface{}
+}
+
+// Handler returns the fixture of a call dynamically, it has priority over the scripted fixtures
+type Handler func(call Call) *Fixture
+
+// Server is a local fake server of the tencent cloud api v3 json protocol, used to test the cloud provider and datasource without a real account.
+// Each action returns its scripted fixtures in order, the last fixture is repeated when the script runs out.
+// The server routes the request by action only, so point all the services to it by the Endpoint of QCloudClientProfile.
+type Server struct {
+	*httptest.Server
+
+	lock      sync.Mutex
+	scripts   map[string][]Fixture
+	handlers  map[string]Handler
+	calls     []Call
+	requestId int
+}
+
+func NewServer() *Server {
+	s := &Server{
+		scripts:  make(map[string][]Fixture),
+		handlers: make(map[string]Handler),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewServerWithFixtures return a server scripted by the fixtures json file
+func NewServerWithFixtures(path string) (*Server, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fixtures, err := LoadFixtures(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fixtures %v: %v", path, err)
+	}
+	s := NewServer()
+	for action, script := range fixtures {
+		s.Script(action, script...)
+	}
+	return s, nil
+}
+
+func LoadFixtures(r io.Reader) (Fixtures, error) {
+	fixtures := Fixtures{}
+	if err := json.NewDecoder(r).Decode(&fixtures); err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// Endpoint return the host of the server, set it to the Endpoint of QCloudClientProfile with http Scheme
+func (s *Server) Endpoint() string {
+	u, _ := url.Parse(s.URL)
+	return u.Host
+}
+
+// ClientConfig return a client config of the qcloud sdk clients which send requests to the server
+func (s *Server) ClientConfig(region string) *qcloud.QCloudClientConfig {
+	return &qcloud.QCloudClientConfig{
+		Governor: qcloud.NewRequestGovernor(qcloud.GovernorConfig{
+			QPS:              1000,
+			Burst:            1000,
+			ActionQPS:        1000,
+			ActionBurst:      1000,
+			FailureThreshold: 1000,
+			OpenDuration:     time.Second,
+			BaseBackoff:      time.Millisecond,
+			MaxBackoff:       time.Millisecond,
+		}),
+		DefaultRetryCnt: 0,
+		Credential:      credential.NewFakeCred("fake-secret-id", "fake-secret-key", time.Hour),
+		QCloudClientProfile: qcloud.QCloudClientProfile{
+			DefaultLimit:    consts.LIMITS,
+			DefaultLanguage: consts.LANGUAGE,
+			DefaultTimeout:  consts.TIMEOUT,
+			Region:          region,
+			Scheme:          "http",
+			Endpoint:        s.Endpoint(),
+		},
+	}
+}
+
+// Script appends the fixtures to the script of the action
+func (s *Server) Script(action string, fixtures ...Fixture) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.scripts[action] = append(s.scripts[action], fixtures...)
+}
+
+// ScriptResponse appends a fixture of the response to the script of the action, response is marshaled to json
+func (s *Server) ScriptResponse(action string, response interface{}) error {
+	out, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	s.Script(action, Fixture{Response: out})
+	return nil
+}
+
+// ScriptError appends a fixture of the error to the script of the action
+func (s *Server) ScriptError(action, code, message string) {
+	s.Script(action, Fixture{Error: &FixtureError{Code: code, Message: message}})
+}
+
+// Handle registers a handler of the action
+func (s *Server) Handle(action string, handler Handler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handlers[action] = handler
+}
+
+// Calls return the calls of the action the server received, all the calls if action is empty
+func (s *Server) Calls(action string) []Call {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var calls []Call
+	for _, call := range s.calls {
+		if action == "" || call.Action == action {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (s *Server) next(call Call) (*Fixture, string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.calls = append(s.calls, call)
+	s.requestId++
+	requestId := fmt.Sprintf("fake-request-%v", s.requestId)
+	if handler, ok := s.handlers[call.Action]; ok {
+		if fixture := handler(call); fixture != nil {
+			return fixture, requestId
+		}
+	}
+	script := s.scripts[call.Action]
+	if len(script) == 0 {
+		return nil, requestId
+	}
+	fixture := script[0]
+	if len(script) > 1 {
+		s.scripts[call.Action] = script[1:]
+	}
+	return &fixture, requestId
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	call := Call{
+		Action:  r.Header.Get(headerAction),
+		Version: r.Header.Get(headerVersion),
+		Region:  r.Header.Get(headerRegion),
+		Params:  map[string]interface{}{},
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &call.Params); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	fixture, requestId := s.next(call)
+	if fixture == nil {
+		fixture = &Fixture{Error: &FixtureError{Code: "InvalidAction", Message: fmt.Sprintf("no fixture for action %v", call.Action)}}
+	}
+	if fixture.HttpStatus != 0 && fixture.HttpStatus != http.StatusOK {
+		w.WriteHeader(fixture.HttpStatus)
+		return
+	}
+
+	response := map[string]interface{}{}
+	if fixture.Error != nil {
+		response["Error"] = map[string]string{"Code": fixture.Error.Code, "Message": fixture.Error.Message}
+	} else if len(fixture.Response) > 0 {
+		if err := json.Unmarshal(fixture.Response, &response); err != nil {
+			response = map[string]interface{}{"Error": map[string]string{"Code": "InternalError", "Message": err.Error()}}
+		}
+	}
+	response["RequestId"] = requestId
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"Response": response})
+}
@@ -0,0 +1,335 @@
+package qcloud
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tcerr "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/metrics"
+)
+
+const (
+	// result codes reported to metrics when the governor refuses a request without calling the api
+	ResultCodeCircuitOpen = "CircuitBreakerOpen"
+)
+
+// RateLimit is the qps and burst of a token bucket
+type RateLimit struct {
+	QPS   float32
+	Burst int
+}
+
+// GovernorConfig is the config of RequestGovernor
+type GovernorConfig struct {
+	// QPS and Burst of the token bucket shared by all the actions of all the clients
+	QPS   float32
+	Burst int
+	// ServiceLimits are the token buckets of the services, key is the service such as cvm, the services not in it are limited by the global bucket only
+	ServiceLimits map[string]RateLimit
+	// ActionQPS and ActionBurst of the token bucket of each api action, such as cvm.DescribeInstances
+	ActionQPS   float32
+	ActionBurst int
+	// FailureThreshold is the number of consecutive failures of an action which opens its circuit breaker
+	FailureThreshold int
+	// OpenDuration is how long an opened circuit breaker rejects requests before it lets a probe request through
+	OpenDuration time.Duration
+	// BaseBackoff is the backoff of the first retry, it doubles for each retry and is capped by MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultGovernorConfig keeps the limits of the clients before the governor, cvm and tke were 5 qps and 1 burst, monitor was 10 qps and 20 burst
+func DefaultGovernorConfig() GovernorConfig {
+	return GovernorConfig{
+		QPS:   15,
+		Burst: 21,
+		ServiceLimits: map[string]RateLimit{
+			"cvm":     {QPS: 5, Burst: 1},
+			"tke":     {QPS: 5, Burst: 1},
+			"monitor": {QPS: 10, Burst: 20},
+		},
+		ActionQPS:        5,
+		ActionBurst:      10,
+		FailureThreshold: 5,
+		OpenDuration:     60 * time.Second,
+		BaseBackoff:      2 * time.Second,
+		MaxBackoff:       32 * time.Second,
+	}
+}
+
+// GovernedRequest is the part of tencent cloud sdk request the governor needs to identify an api action
+type GovernedRequest interface {
+	GetService() string
+	GetDomain() string
+	GetAction() string
+	GetVersion() string
+}
+
+type RetryFunc func(request interface{}) (interface{}, error)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type circuitBreaker struct {
+	state       breakerState
+	failures    int
+	openedAt    time.Time
+	probing     bool
+	lastFailure error
+}
+
+// RequestGovernor governs all the tencent cloud api requests sent by the qcloud sdk clients.
+// It applies a global token bucket and a token bucket per action, opens a circuit breaker for an action
+// when it fails repeatedly, and retries failed requests with jittered exponential backoff.
+// Clients sharing one governor share one qps budget, so fadvisor does not hammer the api when the cloud is in trouble.
+type RequestGovernor struct {
+	config GovernorConfig
+
+	lock            sync.Mutex
+	globalLimiter   flowcontrol.RateLimiter
+	serviceLimiters map[string]flowcontrol.RateLimiter
+	limiters        map[string]flowcontrol.RateLimiter
+	breakers        map[string]*circuitBreaker
+
+	// for test
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewRequestGovernor(config GovernorConfig) *RequestGovernor {
+	g := &RequestGovernor{
+		breakers: make(map[string]*circuitBreaker),
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+	g.SetConfig(config)
+	return g
+}
+
+// SetConfig replaces the config, the token buckets are rebuilt and the circuit breakers are kept
+func (g *RequestGovernor) SetConfig(config GovernorConfig) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.config = config
+	g.globalLimiter = flowcontrol.NewTokenBucketRateLimiter(config.QPS, config.Burst)
+	g.serviceLimiters = make(map[string]flowcontrol.RateLimiter, len(config.ServiceLimits))
+	for service, limit := range config.ServiceLimits {
+		g.serviceLimiters[service] = flowcontrol.NewTokenBucketRateLimiter(limit.QPS, limit.Burst)
+	}
+	g.limiters = make(map[string]flowcontrol.RateLimiter)
+}
+
+var (
+	sharedGovernorOnce sync.Once
+	sharedGovernor     *RequestGovernor
+)
+
+// SharedRequestGovernor return the process wide governor, all clients use it unless a governor is specified in QCloudClientConfig.
+// It is of the default config unless it is configured by SetConfig
+func SharedRequestGovernor() *RequestGovernor {
+	sharedGovernorOnce.Do(func() {
+		sharedGovernor = NewRequestGovernor(DefaultGovernorConfig())
+	})
+	return sharedGovernor
+}
+
+func actionKey(req GovernedRequest) string {
+	return req.GetService() + "." + req.GetAction()
+}
+
+func requestModule(req GovernedRequest) string {
+	if req.GetDomain() != "" {
+		return req.GetDomain()
+	}
+	return req.GetService()
+}
+
+// rateLimiters return the token buckets the request of the action waits for, the global one, the one of the service if configured and the one of the action
+func (g *RequestGovernor) rateLimiters(service, key string) []flowcontrol.RateLimiter {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	limiter, ok := g.limiters[key]
+	if !ok {
+		limiter = flowcontrol.NewTokenBucketRateLimiter(g.config.ActionQPS, g.config.ActionBurst)
+		g.limiters[key] = limiter
+	}
+	limiters := []flowcontrol.RateLimiter{g.globalLimiter}
+	if serviceLimiter, ok := g.serviceLimiters[service]; ok {
+		limiters = append(limiters, serviceLimiter)
+	}
+	return append(limiters, limiter)
+}
+
+// allow return nil if the circuit breaker of the action let the request through
+func (g *RequestGovernor) allow(key string) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	breaker, ok := g.breakers[key]
+	if !ok {
+		return nil
+	}
+	switch breaker.state {
+	case breakerOpen:
+		if g.now().Sub(breaker.openedAt) < g.config.OpenDuration {
+			return fmt.Errorf("qcloudClient circuit breaker of %v is open, last err: %v", key, breaker.lastFailure)
+		}
+		g.transit(key, breaker, breakerHalfOpen)
+		breaker.probing = true
+		return nil
+	case breakerHalfOpen:
+		// only one probe request at one time
+		if breaker.probing {
+			return fmt.Errorf("qcloudClient circuit breaker of %v is half-open and probing, last err: %v", key, breaker.lastFailure)
+		}
+		breaker.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// retryableErrorCodePrefixes are the tencent cloud api error codes of throttling and server side failures
+var retryableErrorCodePrefixes = []string{
+	"RequestLimitExceeded",
+	"InternalError",
+	"ServiceUnavailable",
+	"ClientError.NetworkError",
+	"ClientError.IOError",
+	"ClientError.CircuitBreakerError",
+}
+
+// retryable returns true if the error is of throttling, 5xx or network. The client errors such as InvalidParameter, ResourceNotFound and
+// AuthFailure are not retryable, they do not count against the circuit breaker either. The errors not from the api are network errors
+func retryable(err error) bool {
+	sdkErr, ok := err.(*tcerr.TencentCloudSDKError)
+	if !ok {
+		return true
+	}
+	code := sdkErr.GetCode()
+	if code == "ClientError.HttpStatusCodeError" {
+		return strings.Contains(sdkErr.GetMessage(), "status code: 5") || strings.Contains(sdkErr.GetMessage(), "status code: 429")
+	}
+	for _, prefix := range retryableErrorCodePrefixes {
+		if strings.HasPrefix(code, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// onResult records the result of the action, a non retryable error is a response of the healthy api, so it closes the breaker as a success
+func (g *RequestGovernor) onResult(key string, err error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	breaker, ok := g.breakers[key]
+	if !ok {
+		breaker = &circuitBreaker{}
+		g.breakers[key] = breaker
+	}
+	breaker.probing = false
+	if err == nil || !retryable(err) {
+		breaker.failures = 0
+		breaker.lastFailure = nil
+		g.transit(key, breaker, breakerClosed)
+		return
+	}
+	breaker.failures++
+	breaker.lastFailure = err
+	if breaker.state == breakerHalfOpen || breaker.failures >= g.config.FailureThreshold {
+		breaker.openedAt = g.now()
+		g.transit(key, breaker, breakerOpen)
+	}
+}
+
+func (g *RequestGovernor) transit(key string, breaker *circuitBreaker, state breakerState) {
+	if breaker.state != state {
+		klog.Warningf("qcloudClient circuit breaker of %v transits from %v to %v, failures: %v", key, breaker.state, state, breaker.failures)
+	}
+	breaker.state = state
+	metrics.ComponentCircuitBreakerState.WithLabelValues(key).Set(float64(state))
+}
+
+// BreakerState return the circuit breaker state of the action, closed, open or half-open
+func (g *RequestGovernor) BreakerState(service, action string) string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	breaker, ok := g.breakers[service+"."+action]
+	if !ok {
+		return breakerClosed.String()
+	}
+	return breaker.state.String()
+}
+
+// backoff return the jittered sleep duration before the ith retry, it is in [d/2, d], d = min(BaseBackoff * 2^(i-1), MaxBackoff)
+func (g *RequestGovernor) backoff(i int) time.Duration {
+	g.lock.Lock()
+	base, max := g.config.BaseBackoff, g.config.MaxBackoff
+	g.lock.Unlock()
+	d := base << uint(i-1)
+	if d <= 0 || d > max {
+		d = max
+	}
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int63nRange(0, half+1))
+}
+
+// Call sends the request by f, it retries retryCnt times when failed by a retryable error unless the circuit breaker of the action opens.
+func (g *RequestGovernor) Call(retryCnt int, f RetryFunc, request GovernedRequest) (interface{}, error) {
+	key := actionKey(request)
+	limiters := g.rateLimiters(request.GetService(), key)
+	limiter := limiters[len(limiters)-1]
+
+	var err error
+	var resp interface{}
+	for i := 0; i <= retryCnt; i++ {
+		if i > 0 {
+			sleepTime := g.backoff(i)
+			klog.Errorf("qcloudClient tencent cloud api %v failed, retrying %v times after %v, qps: %v, err: %v", key, i, sleepTime, limiter.QPS(), err)
+			g.sleep(sleepTime)
+		}
+		if allowErr := g.allow(key); allowErr != nil {
+			metrics.ComponentWrongRequestStatics(requestModule(request), request.GetAction(), ResultCodeCircuitOpen, request.GetVersion())
+			return nil, fmt.Errorf("qcloudClient tencent cloud api %v rejected after %v retries: %v", key, i, allowErr)
+		}
+
+		// blocking
+		for _, l := range limiters {
+			l.Accept()
+		}
+
+		resp, err = f(request)
+		g.onResult(key, err)
+		if err == nil {
+			return resp, nil
+		}
+		if !retryable(err) {
+			return nil, fmt.Errorf("qcloudClient tencent cloud api %v failed with non retryable err: %v", key, err)
+		}
+	}
+	return nil, fmt.Errorf("qcloudClient tencent cloud api retry failed after retry %v times, err: %s", retryCnt, err)
+}
@@ -0,0 +1,119 @@
+package qcloud
+
+import (
+	"testing"
+	"time"
+
+	tcerr "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+func newTestGovernor(now *time.Time) *RequestGovernor {
+	g := NewRequestGovernor(GovernorConfig{
+		QPS:              1000,
+		Burst:            1000,
+		ActionQPS:        1000,
+		ActionBurst:      1000,
+		FailureThreshold: 3,
+		OpenDuration:     time.Minute,
+		BaseBackoff:      time.Second,
+		MaxBackoff:       4 * time.Second,
+	})
+	g.now = func() time.Time { return *now }
+	g.sleep = func(d time.Duration) { *now = now.Add(d) }
+	return g
+}
+
+func TestRequestGovernorCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	g := newTestGovernor(&now)
+	req := cvm.NewDescribeInstancesRequest()
+
+	calls := 0
+	failing := func(request interface{}) (interface{}, error) {
+		calls++
+		return nil, tcerr.NewTencentCloudSDKError("RequestLimitExceeded", "throttled", "")
+	}
+	if _, err := g.Call(5, failing, req); err == nil {
+		t.Fatalf("expect error")
+	}
+	if calls != 3 {
+		t.Errorf("expect breaker opens after 3 calls, got %v calls", calls)
+	}
+	if state := g.BreakerState("cvm", "DescribeInstances"); state != "open" {
+		t.Errorf("expect breaker open, got %v", state)
+	}
+
+	// other actions are not affected
+	if state := g.BreakerState("cvm", "DescribeRegions"); state != "closed" {
+		t.Errorf("expect breaker closed, got %v", state)
+	}
+
+	calls = 0
+	succeeded := func(request interface{}) (interface{}, error) {
+		calls++
+		return "ok", nil
+	}
+	if _, err := g.Call(0, succeeded, req); err == nil || calls != 0 {
+		t.Fatalf("expect request rejected by open breaker, err: %v, calls: %v", err, calls)
+	}
+
+	now = now.Add(time.Minute)
+	resp, err := g.Call(0, succeeded, req)
+	if err != nil || resp != "ok" {
+		t.Fatalf("expect probe request succeeded, resp: %v, err: %v", resp, err)
+	}
+	if state := g.BreakerState("cvm", "DescribeInstances"); state != "closed" {
+		t.Errorf("expect breaker closed after probe succeeded, got %v", state)
+	}
+}
+
+func TestRequestGovernorNonRetryableError(t *testing.T) {
+	now := time.Now()
+	g := newTestGovernor(&now)
+	req := cvm.NewDescribeInstancesRequest()
+
+	calls := 0
+	invalid := func(request interface{}) (interface{}, error) {
+		calls++
+		return nil, tcerr.NewTencentCloudSDKError("InvalidParameterValue.InstanceIdMalformed", "malformed", "")
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := g.Call(5, invalid, req); err == nil {
+			t.Fatalf("expect error")
+		}
+	}
+	if calls != 5 {
+		t.Errorf("expect no retry of invalid parameter, got %v calls", calls)
+	}
+	if state := g.BreakerState("cvm", "DescribeInstances"); state != "closed" {
+		t.Errorf("expect breaker closed by invalid parameter errors, got %v", state)
+	}
+
+	for code, expected := range map[string]bool{
+		"RequestLimitExceeded.UinLimitExceeded": true,
+		"InternalError":                         true,
+		"ClientError.NetworkError":              true,
+		"AuthFailure.SignatureFailure":          false,
+		"ResourceNotFound":                      false,
+	} {
+		if retryable(tcerr.NewTencentCloudSDKError(code, "", "")) != expected {
+			t.Errorf("expect %v retryable %v", code, expected)
+		}
+	}
+	if !retryable(tcerr.NewTencentCloudSDKError("ClientError.HttpStatusCodeError", "Request fail with http status code: 502 Bad Gateway, with body:", "")) ||
+		retryable(tcerr.NewTencentCloudSDKError("ClientError.HttpStatusCodeError", "Request fail with http status code: 403 Forbidden, with body:", "")) {
+		t.Errorf("expect only 5xx and 429 http status retryable")
+	}
+}
+
+func TestRequestGovernorBackoff(t *testing.T) {
+	now := time.Now()
+	g := newTestGovernor(&now)
+	for i, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		d := g.backoff(i + 1)
+		if d < max/2 || d > max {
+			t.Errorf("retry %v backoff %v not in [%v, %v]", i+1, d, max/2, max)
+		}
+	}
+}
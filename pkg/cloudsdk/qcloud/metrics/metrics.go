@@ -20,11 +20,27 @@ var (
 		Help:       "norm request durations in millisecond",
 		Objectives: map[float64]float64{0.25: 0.05, 0.5: 0.05, 0.75: 0.01, 0.9: 0.01, 0.99: 0.001},
 	}, []string{"module", "action", "status_code", "result_code", "version"})
+	ComponentCircuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "component_circuit_breaker_state",
+			Help: "circuit breaker state of each api action, 0 closed, 1 open, 2 half-open",
+		},
+		[]string{"action"},
+	)
+	NodeRegionUnresolved = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "qcloud_node_region_unresolved",
+			Help: "number of nodes whose region label can not be resolved to a qcloud region, their price maybe wrong",
+		},
+		[]string{"region"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(ComponentRequestTotal)
 	prometheus.MustRegister(ComponentRequestDuration)
+	prometheus.MustRegister(ComponentCircuitBreakerState)
+	prometheus.MustRegister(NodeRegionUnresolved)
 }
 
 func ComponentRequestStatics(module string, action string, statusCode int, resultCode string, version string, startTime time.Time) {
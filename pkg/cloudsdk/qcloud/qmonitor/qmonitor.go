@@ -7,7 +7,6 @@ import (
 	"sync"
 	"time"
 
-	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/klog"
 
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
@@ -109,30 +108,11 @@ func (qcc *QCloudMonitorClient) UpdateCredential(cred credential.QCloudCredentia
 }
 
 func (qcc *QCloudMonitorClient) ExponentialRetryCall(retryCnt int, f retryFunc, request interface{}) (interface{}, error) {
-	var err error
-	var resp interface{}
-	//ctx, cancelFunc := context.WithTimeout(context.Background(), 5*time.Minute)
-	//defer cancelFunc()
-
-	// blocking
-	qcc.config.RateLimiter.Accept()
-
-	resp, err = f(request)
-	if err == nil {
-		return resp, nil
+	req, ok := request.(qcloud.GovernedRequest)
+	if !ok {
+		return nil, fmt.Errorf("qcloudClient unknown request type %T", request)
 	}
-	for i := 1; i <= retryCnt; i++ {
-		randInt := rand.IntnRange(2<<i, 2<<(i+1)+1)
-		sleepTime := time.Duration(randInt) * time.Second
-		klog.Errorf("qcloudClient tencent cloud api failed, retrying %v times, qps:%v, err: %v", i, qcc.config.RateLimiter.QPS(), err)
-		time.Sleep(sleepTime)
-		qcc.config.RateLimiter.Accept()
-		resp, err = f(request)
-		if err == nil {
-			return resp, nil
-		}
-	}
-	return nil, fmt.Errorf("qcloudClient tencent cloud api retry failed after retry %v times, err: %s", retryCnt, err)
+	return qcc.config.RequestGovernor().Call(retryCnt, qcloud.RetryFunc(f), req)
 }
 
 func (qcc *QCloudMonitorClient) UpdateCred(cred credential.QCloudCredential) {
@@ -180,6 +160,8 @@ func (qcc *QCloudMonitorClient) getClient() (*cm.Client, error) {
 			return qcc.client, err
 		}
 	}
+	// the credential maybe refreshed or rotated
+	qcc.client.WithCredential(cred)
 	if qcc.config.Debug {
 		SecretId := cred.GetSecretId()
 		SecretKey := cred.GetSecretKey()
@@ -190,6 +172,9 @@ func (qcc *QCloudMonitorClient) getClient() (*cm.Client, error) {
 }
 
 func (qcc *QCloudMonitorClient) getQMonitorDomain() string {
+	if qcc.config.Endpoint != "" {
+		return qcc.config.Endpoint
+	}
 	return fmt.Sprintf("%v.%v", "monitor", qcc.config.DomainSuffix)
 }
 
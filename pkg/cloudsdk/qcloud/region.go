@@ -0,0 +1,171 @@
+package qcloud
+
+import (
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/metrics"
+)
+
+type RegionInfo struct {
+	RegionId        int
+	Region          string
+	RegionName      string
+	Area            string
+	RegionShortName string
+}
+
+// RegionResolver resolves the region label of node to the cloud region. tke node label is the region short name such as gz,
+// other clusters maybe label the region directly such as ap-guangzhou.
+// It merges the overrides from config file, the static ShortName2region table and the regions discovered by DescribeRegions, in order of priority.
+type RegionResolver struct {
+	lock sync.RWMutex
+	// key is the region short name or region
+	overrides map[string]RegionInfo
+	// key is the region
+	discovered map[string]RegionInfo
+	// nodes which region is unresolved and its region label value, to avoid logging repeatedly
+	unresolvedNodes map[string]string
+}
+
+var defaultRegionResolver = NewRegionResolver()
+
+func NewRegionResolver() *RegionResolver {
+	return &RegionResolver{
+		overrides:       make(map[string]RegionInfo),
+		discovered:      make(map[string]RegionInfo),
+		unresolvedNodes: make(map[string]string),
+	}
+}
+
+// DefaultRegionResolver is used by ResolveRegion and ResolveNodeRegion
+func DefaultRegionResolver() *RegionResolver {
+	return defaultRegionResolver
+}
+
+// SetOverrides replaces the overrides, key is the region label value
+func (r *RegionResolver) SetOverrides(overrides map[string]RegionInfo) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.overrides = make(map[string]RegionInfo, len(overrides))
+	for name, info := range overrides {
+		if info.RegionShortName == "" {
+			info.RegionShortName = name
+		}
+		r.overrides[name] = info
+	}
+}
+
+// SetDiscoveredRegions replaces the regions discovered from cloud api
+func (r *RegionResolver) SetDiscoveredRegions(regions []RegionInfo) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.discovered = make(map[string]RegionInfo, len(regions))
+	for _, info := range regions {
+		if info.Region == "" {
+			continue
+		}
+		// the cloud api has no short name, complete it by the static table
+		if static, ok := Region2shortNameRegion[info.Region]; ok {
+			if info.RegionShortName == "" {
+				info.RegionShortName = static.RegionShortName
+			}
+			if info.RegionId == 0 {
+				info.RegionId = static.RegionId
+			}
+		}
+		if info.RegionShortName == "" && !r.hasOverride(info.Region) {
+			klog.Warningf("Discovered qcloud region %v has no known short name, the nodes labeled by its short name can not be resolved. "+
+				"Map the short name to it by [region \"<short name>\"] region=%v in cloud config", info.Region, info.Region)
+		}
+		r.discovered[info.Region] = info
+	}
+	klog.V(4).Infof("Discovered %v qcloud regions", len(r.discovered))
+}
+
+// hasOverride returns true if an override maps to the region, the caller must hold the lock
+func (r *RegionResolver) hasOverride(region string) bool {
+	for _, info := range r.overrides {
+		if info.Region == region {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve return the region info of the region label value, which is a region short name or a region
+func (r *RegionResolver) Resolve(name string) (RegionInfo, bool) {
+	if name == "" {
+		return RegionInfo{}, false
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if info, ok := r.overrides[name]; ok {
+		return info, true
+	}
+	if info, ok := ShortName2region[name]; ok {
+		return info, true
+	}
+	if info, ok := r.discovered[name]; ok {
+		return info, true
+	}
+	for _, info := range r.discovered {
+		if info.RegionShortName == name {
+			return info, true
+		}
+	}
+	if info, ok := Region2shortNameRegion[name]; ok {
+		return info, true
+	}
+	return RegionInfo{}, false
+}
+
+// ResolveNode resolves the region label value of the node, the node is logged and counted by metric of its region label if its
+// region can not be resolved
+func (r *RegionResolver) ResolveNode(node string, name string) (string, bool) {
+	info, ok := r.Resolve(name)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	last, logged := r.unresolvedNodes[node]
+	if logged && (ok || last != name) {
+		delete(r.unresolvedNodes, node)
+		r.updateUnresolvedMetric(last)
+	}
+	if !ok {
+		if _, logged := r.unresolvedNodes[node]; !logged {
+			klog.Warningf("Can not resolve region %q of node %v, add it to region overrides of cloud config if it is a new region", name, node)
+			r.unresolvedNodes[node] = name
+			r.updateUnresolvedMetric(name)
+		}
+		return "", false
+	}
+	return info.Region, true
+}
+
+// updateUnresolvedMetric sets the number of the unresolved nodes of the region label value, the caller must hold the lock
+func (r *RegionResolver) updateUnresolvedMetric(name string) {
+	count := 0
+	for _, region := range r.unresolvedNodes {
+		if region == name {
+			count++
+		}
+	}
+	if count == 0 {
+		metrics.NodeRegionUnresolved.DeleteLabelValues(name)
+		return
+	}
+	metrics.NodeRegionUnresolved.WithLabelValues(name).Set(float64(count))
+}
+
+// ResolveRegion return the region of the region label value by the default resolver
+func ResolveRegion(name string) (string, bool) {
+	info, ok := defaultRegionResolver.Resolve(name)
+	return info.Region, ok
+}
+
+// ResolveNodeRegion return the region of the region label value of the node by the default resolver
+func ResolveNodeRegion(node string, name string) (string, bool) {
+	return defaultRegionResolver.ResolveNode(node, name)
+}
@@ -0,0 +1,63 @@
+package qcloud
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/metrics"
+)
+
+func TestRegionResolver(t *testing.T) {
+	r := NewRegionResolver()
+	r.SetOverrides(map[string]RegionInfo{"gz": {Region: "ap-guangzhou-override"}})
+	r.SetDiscoveredRegions([]RegionInfo{{Region: "ap-shanghai"}, {Region: "ap-newregion"}})
+
+	cases := []struct {
+		name   string
+		region string
+		ok     bool
+	}{
+		{name: "gz", region: "ap-guangzhou-override", ok: true},
+		{name: "bj", region: "ap-beijing", ok: true},
+		{name: "ap-newregion", region: "ap-newregion", ok: true},
+		{name: "ap-beijing", region: "ap-beijing", ok: true},
+		{name: "unknown", region: "", ok: false},
+		{name: "", region: "", ok: false},
+	}
+	for _, c := range cases {
+		info, ok := r.Resolve(c.name)
+		if ok != c.ok || info.Region != c.region {
+			t.Errorf("resolve %q, expect (%v, %v), got (%v, %v)", c.name, c.region, c.ok, info.Region, ok)
+		}
+	}
+	if info, _ := r.Resolve("ap-shanghai"); info.RegionShortName != "sh" {
+		t.Errorf("expect discovered region completed by static table, got %+v", info)
+	}
+}
+
+func TestRegionResolverUnresolvedNode(t *testing.T) {
+	r := NewRegionResolver()
+	if _, ok := r.ResolveNode("node1", "xyz"); ok {
+		t.Fatalf("expect region xyz unresolved")
+	}
+	if _, ok := r.ResolveNode("node2", "xyz"); ok {
+		t.Fatalf("expect region xyz unresolved")
+	}
+	r.ResolveNode("node2", "xyz")
+	if v := testutil.ToFloat64(metrics.NodeRegionUnresolved.WithLabelValues("xyz")); v != 2 {
+		t.Errorf("expect 2 unresolved nodes, got %v", v)
+	}
+
+	r.SetOverrides(map[string]RegionInfo{"xyz": {Region: "ap-xyz"}})
+	if region, ok := r.ResolveNode("node1", "xyz"); !ok || region != "ap-xyz" {
+		t.Fatalf("expect region ap-xyz, got %v", region)
+	}
+	if v := testutil.ToFloat64(metrics.NodeRegionUnresolved.WithLabelValues("xyz")); v != 1 {
+		t.Errorf("expect 1 unresolved node, got %v", v)
+	}
+	r.ResolveNode("node2", "xyz")
+	if n := testutil.CollectAndCount(metrics.NodeRegionUnresolved); n != 0 {
+		t.Errorf("expect unresolved metric deleted, got %v series", n)
+	}
+}
@@ -3,12 +3,12 @@ package tke
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
-	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/klog/v2"
 
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
@@ -24,6 +24,34 @@ type TKEClient struct {
 	clientLock sync.Mutex
 	client     *tke.Client
 	config     *qcloud.QCloudClientConfig
+
+	// specStore memoises the eks specifications GetPodSpecification returned, the specification rules are static,
+	// so the pods of the same workload are converted by only one api call. it is in memory unless SetSpecStore is called
+	specStore EKSSpecStore
+}
+
+// EKSSpecStore stores the eks specifications, key is the cpu type and resource requirements of the containers
+type EKSSpecStore interface {
+	GetSpec(key string) (v1.ResourceList, bool)
+	SetSpec(key string, spec v1.ResourceList)
+}
+
+type memorySpecStore struct {
+	lock  sync.RWMutex
+	specs map[string]v1.ResourceList
+}
+
+func (s *memorySpecStore) GetSpec(key string) (v1.ResourceList, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	spec, ok := s.specs[key]
+	return spec, ok
+}
+
+func (s *memorySpecStore) SetSpec(key string, spec v1.ResourceList) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.specs[key] = spec
 }
 
 type retryFunc func(request interface{}) (interface{}, error)
@@ -31,11 +59,20 @@ type retryFunc func(request interface{}) (interface{}, error)
 func NewTKEClient(qcc *qcloud.QCloudClientConfig) *TKEClient {
 
 	return &TKEClient{
-		config: qcc,
+		config:    qcc,
+		specStore: &memorySpecStore{specs: make(map[string]v1.ResourceList)},
 	}
 }
 
+// SetSpecStore replaces the store of the eks specifications, such as a persistent one so that later runs can reuse them. it is not safe to call it with the conversions concurrently
+func (qcc *TKEClient) SetSpecStore(store EKSSpecStore) {
+	qcc.specStore = store
+}
+
 func (qcc *TKEClient) getTKEDomain() string {
+	if qcc.config.Endpoint != "" {
+		return qcc.config.Endpoint
+	}
 	return fmt.Sprintf("%v.%v", "tke", qcc.config.DomainSuffix)
 }
 
@@ -46,28 +83,11 @@ func (qcc *TKEClient) UpdateCredential(cred credential.QCloudCredential) {
 }
 
 func (qcc *TKEClient) ExponentialRetryCall(retryCnt int, f retryFunc, request interface{}) (interface{}, error) {
-	var err error
-	var resp interface{}
-
-	// blocking
-	qcc.config.RateLimiter.Accept()
-
-	resp, err = f(request)
-	if err == nil {
-		return resp, nil
-	}
-	for i := 1; i <= retryCnt; i++ {
-		klog.Errorf("qcloudClient tencent cloud api failed, retrying %v times, qps:%v, err: %v", i, qcc.config.RateLimiter.QPS(), err)
-		randInt := rand.IntnRange(2<<i, 2<<(i+1)+1)
-		sleepTime := time.Duration(randInt) * time.Second
-		time.Sleep(sleepTime)
-		qcc.config.RateLimiter.Accept()
-		resp, err = f(request)
-		if err == nil {
-			return resp, nil
-		}
+	req, ok := request.(qcloud.GovernedRequest)
+	if !ok {
+		return nil, fmt.Errorf("qcloudClient unknown request type %T", request)
 	}
-	return nil, fmt.Errorf("qcloudClient tencent cloud api retry failed after retry %v times, err: %s", retryCnt, err)
+	return qcc.config.RequestGovernor().Call(retryCnt, qcloud.RetryFunc(f), req)
 }
 
 func (qcc *TKEClient) UpdateCred(cred credential.QCloudCredential) {
@@ -117,6 +137,8 @@ func (qcc *TKEClient) getClient() (*tke.Client, error) {
 			return qcc.client, err
 		}
 	}
+	// the credential maybe refreshed or rotated
+	qcc.client.WithCredential(cred)
 	if qcc.config.Debug {
 		SecretId := cred.GetSecretId()
 		SecretKey := cred.GetSecretKey()
@@ -191,11 +213,6 @@ func (qcc *TKEClient) GetPodSpecification(req *tke.GetPodSpecificationRequest) (
 }
 
 func (qcc *TKEClient) Pod2EKSSpecConverter(pod *v1.Pod) (v1.ResourceList, error) {
-	cli, err := qcc.getClient()
-	if err != nil {
-		return nil, err
-	}
-
 	req := tke.NewGetPodSpecificationRequest()
 
 	var requirements []string
@@ -217,7 +234,13 @@ func (qcc *TKEClient) Pod2EKSSpecConverter(pod *v1.Pod) (v1.ResourceList, error)
 	req.Type = &machineType
 	req.ResourceRequirements = reqRequirements
 
-	resp, err := qcc.getPodSpecificationWithRetry(cli, req)
+	key := machineType + "|" + strings.Join(requirements, "|")
+	spec, ok := qcc.specStore.GetSpec(key)
+	if ok {
+		return spec.DeepCopy(), nil
+	}
+
+	resp, err := qcc.GetPodSpecification(req)
 	if err != nil {
 		return nil, err
 	}
@@ -230,10 +253,12 @@ func (qcc *TKEClient) Pod2EKSSpecConverter(pod *v1.Pod) (v1.ResourceList, error)
 	if err != nil {
 		return nil, err
 	}
-	return v1.ResourceList{
+	spec = v1.ResourceList{
 		v1.ResourceCPU:    cpuQuantity,
 		v1.ResourceMemory: memQuantity,
-	}, nil
+	}
+	qcc.specStore.SetSpec(key, spec)
+	return spec.DeepCopy(), nil
 }
 
 const (
@@ -39,6 +39,13 @@ const (
 	LabelProviderId    = "provider_id"
 )
 
+const (
+	// AnnotationImagePlatforms is the comma separated platforms of the images of the pod or workload, such as linux/amd64,linux/arm64
+	AnnotationImagePlatforms = "fadvisor.crane.io/image-platforms"
+	// AnnotationEstimatorProfile is the name of the estimator profile of the pod or workload, it has priority over the namespaces and selectors of the profiles
+	AnnotationEstimatorProfile = "fadvisor.crane.io/estimator-profile"
+)
+
 const (
 	MetricCpuRequest = "cpu_request"
 	MetricCpuLimit   = "cpu_limit"
@@ -46,4 +53,6 @@ const (
 	MetricMemLimit   = "mem_limit"
 
 	MetricWorkloadReplicas = "replicas"
+	// MetricWorkloadPodRuntime is the run seconds of the completed pods of a job or cronjob
+	MetricWorkloadPodRuntime = "pod_runtime"
 )
@@ -0,0 +1,253 @@
+package checkpoint
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+const (
+	// magic identifies the checkpoint file
+	magic = "fadvisor-checkpoint"
+	// Version is the version of the checkpoint format, checkpoints of other versions are refused
+	Version = 1
+)
+
+const (
+	TypeContainer = "container"
+	TypeWorkload  = "workload"
+)
+
+// Metadata describes the time series in the checkpoint, it is the header of the file
+type Metadata struct {
+	Magic      string
+	Version    int
+	Type       string
+	ClusterId  string
+	DataSource string
+	Start      time.Time
+	End        time.Time
+	Step       time.Duration
+	CreatedAt  time.Time
+	// Partial is true if the fetching of the run is partial, the records of the failed tasks are missing or cover shorter ranges
+	Partial bool
+}
+
+// Series is a time series stored by columns, it is smaller than the samples after compression
+type Series struct {
+	Labels     []common.Label
+	Timestamps []int64
+	Values     []float64
+}
+
+// Record is the time series of a workload or a container, key of Series is the metric name such as cpu
+type Record struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Container string
+	Series    map[string][]Series
+	// Start and End are the range the series cover, it is the range of the metadata if zero
+	Start time.Time
+	End   time.Time
+}
+
+// NewSeries converts the time series to columns
+func NewSeries(tsList []*common.TimeSeries) []Series {
+	var result []Series
+	for _, ts := range tsList {
+		if ts == nil {
+			continue
+		}
+		s := Series{
+			Labels:     ts.Labels,
+			Timestamps: make([]int64, 0, len(ts.Samples)),
+			Values:     make([]float64, 0, len(ts.Samples)),
+		}
+		for _, sample := range ts.Samples {
+			s.Timestamps = append(s.Timestamps, sample.Timestamp)
+			s.Values = append(s.Values, sample.Value)
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// TimeSeries converts the columns to time series, the samples out of [start, end] are dropped
+func TimeSeries(series []Series, start, end time.Time) []*common.TimeSeries {
+	result := make([]*common.TimeSeries, 0, len(series))
+	for _, s := range series {
+		ts := common.NewTimeSeries()
+		ts.Labels = s.Labels
+		for i, timestamp := range s.Timestamps {
+			if timestamp < start.Unix() || timestamp > end.Unix() || i >= len(s.Values) {
+				continue
+			}
+			ts.AppendSample(timestamp, s.Values[i])
+		}
+		result = append(result, ts)
+	}
+	return result
+}
+
+// Writer writes the records to a gzip compressed gob stream, the file is replaced atomically when closed
+type Writer struct {
+	path    string
+	file    *os.File
+	buf     *bufio.Writer
+	gz      *gzip.Writer
+	encoder *gob.Encoder
+}
+
+func NewWriter(path string, meta Metadata) (*Writer, error) {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(file)
+	gz := gzip.NewWriter(buf)
+	w := &Writer{path: path, file: file, buf: buf, gz: gz, encoder: gob.NewEncoder(gz)}
+	meta.Magic = magic
+	meta.Version = Version
+	if meta.CreatedAt.IsZero() {
+		meta.CreatedAt = time.Now()
+	}
+	if err := w.encoder.Encode(&meta); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) Write(record *Record) error {
+	return w.encoder.Encode(record)
+}
+
+// Close flushes the records and renames the file to the path
+func (w *Writer) Close() error {
+	if err := w.gz.Close(); err != nil {
+		w.Abort()
+		return err
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.Abort()
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.file.Name())
+		return err
+	}
+	return os.Rename(w.file.Name(), w.path)
+}
+
+// Abort discards the records written
+func (w *Writer) Abort() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
+
+// Reader reads the records of a checkpoint
+type Reader struct {
+	Metadata Metadata
+	file     *os.File
+	gz       *gzip.Reader
+	decoder  *gob.Decoder
+}
+
+// Open opens the checkpoint and reads its metadata, the checkpoint of other format or version is refused
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("not a checkpoint %v: %v", path, err)
+	}
+	r := &Reader{file: file, gz: gz, decoder: gob.NewDecoder(gz)}
+	if err := r.decoder.Decode(&r.Metadata); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("not a checkpoint %v: %v", path, err)
+	}
+	if r.Metadata.Magic != magic {
+		r.Close()
+		return nil, fmt.Errorf("not a checkpoint %v", path)
+	}
+	if r.Metadata.Version != Version {
+		r.Close()
+		return nil, fmt.Errorf("checkpoint %v version %v is not supported, expect version %v", path, r.Metadata.Version, Version)
+	}
+	return r, nil
+}
+
+// Next return the next record, io.EOF if no more records
+func (r *Reader) Next() (*Record, error) {
+	record := &Record{}
+	if err := r.decoder.Decode(record); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *Reader) Close() error {
+	r.gz.Close()
+	return r.file.Close()
+}
+
+// Expectation is the metadata of the current run
+type Expectation struct {
+	Type       string
+	ClusterId  string
+	DataSource string
+	Start      time.Time
+	End        time.Time
+	Step       time.Duration
+	// MinCoverage is the minimal fraction of the current window the checkpoint covers to be reused
+	MinCoverage float64
+}
+
+// Check return the part of the current window the checkpoint covers, the checkpoint is refused if it is of other cluster, datasource or step,
+// or it is stale and covers less than MinCoverage of the current window
+func Check(meta Metadata, expect Expectation) (time.Time, time.Time, error) {
+	if meta.Type != expect.Type {
+		return time.Time{}, time.Time{}, fmt.Errorf("checkpoint type %v mismatch %v", meta.Type, expect.Type)
+	}
+	if meta.ClusterId != expect.ClusterId {
+		return time.Time{}, time.Time{}, fmt.Errorf("checkpoint cluster %v mismatch %v", meta.ClusterId, expect.ClusterId)
+	}
+	if meta.DataSource != expect.DataSource {
+		return time.Time{}, time.Time{}, fmt.Errorf("checkpoint datasource %v mismatch %v", meta.DataSource, expect.DataSource)
+	}
+	if meta.Step != expect.Step {
+		return time.Time{}, time.Time{}, fmt.Errorf("checkpoint step %v mismatch %v", meta.Step, expect.Step)
+	}
+	start, end := meta.Start, meta.End
+	if expect.Start.After(start) {
+		start = expect.Start
+	}
+	if expect.End.Before(end) {
+		end = expect.End
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("checkpoint window [%v, %v] does not overlap [%v, %v]", meta.Start, meta.End, expect.Start, expect.End)
+	}
+	if window := expect.End.Sub(expect.Start); window > 0 {
+		if coverage := float64(end.Sub(start)) / float64(window); coverage < expect.MinCoverage {
+			return time.Time{}, time.Time{}, fmt.Errorf("checkpoint is stale, it covers %.2f of the window, less than %v", coverage, expect.MinCoverage)
+		}
+	}
+	return start, end, nil
+}
@@ -0,0 +1,123 @@
+package checkpoint
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+func TestWriterReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cls-containers.ckpt")
+	start := time.Unix(1640966400, 0)
+	meta := Metadata{Type: TypeContainer, ClusterId: "cls", DataSource: "prom", Start: start, End: start.Add(time.Hour), Step: time.Minute}
+	writer, err := NewWriter(path, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := common.NewTimeSeries()
+	ts.AppendLabel("container", "nginx")
+	for i := 0; i < 3; i++ {
+		ts.AppendSample(start.Unix()+int64(i*1800), float64(i))
+	}
+	if err := writer.Write(&Record{Kind: "Deployment", Namespace: "default", Name: "nginx", Container: "nginx",
+		Series: map[string][]Series{"cpu": NewSeries([]*common.TimeSeries{ts})}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if reader.Metadata.ClusterId != "cls" || reader.Metadata.Version != Version || !reader.Metadata.Start.Equal(start) {
+		t.Errorf("unexpected metadata %+v", reader.Metadata)
+	}
+	record, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Container != "nginx" || len(record.Series["cpu"]) != 1 {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	// the last sample is out of the window
+	tsList := TimeSeries(record.Series["cpu"], start, start.Add(time.Hour-time.Second))
+	if len(tsList) != 1 || len(tsList[0].Samples) != 2 || tsList[0].Labels[0].Value != "nginx" {
+		t.Errorf("unexpected time series %+v", tsList)
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Errorf("expect EOF, got %v", err)
+	}
+}
+
+func TestOpenRefused(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "old.csv")
+	if err := ioutil.WriteFile(csvPath, []byte("Kind\tNamespace\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(csvPath); err == nil {
+		t.Errorf("expect the csv checkpoint refused")
+	}
+
+	versionPath := filepath.Join(dir, "v0.ckpt")
+	f, err := os.Create(versionPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	if err := gob.NewEncoder(gz).Encode(&Metadata{Magic: magic, Version: Version + 1}); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	f.Close()
+	if _, err := Open(versionPath); err == nil {
+		t.Errorf("expect the checkpoint of other version refused")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	start := time.Unix(1640966400, 0)
+	meta := Metadata{Type: TypeContainer, ClusterId: "cls", DataSource: "prom", Start: start, End: start.Add(24 * time.Hour), Step: time.Minute}
+	expect := Expectation{Type: TypeContainer, ClusterId: "cls", DataSource: "prom", Start: start, End: start.Add(24 * time.Hour), Step: time.Minute, MinCoverage: 0.9}
+
+	if s, e, err := Check(meta, expect); err != nil || !s.Equal(expect.Start) || !e.Equal(expect.End) {
+		t.Errorf("expect the whole window, got %v %v %v", s, e, err)
+	}
+
+	shifted := expect
+	shifted.Start = start.Add(2 * time.Hour)
+	shifted.End = start.Add(26 * time.Hour)
+	if s, e, err := Check(meta, shifted); err != nil || !s.Equal(shifted.Start) || !e.Equal(meta.End) {
+		t.Errorf("expect partial reuse, got %v %v %v", s, e, err)
+	}
+
+	stale := expect
+	stale.Start = start.Add(12 * time.Hour)
+	stale.End = start.Add(36 * time.Hour)
+	if _, _, err := Check(meta, stale); err == nil {
+		t.Errorf("expect stale checkpoint refused")
+	}
+
+	for _, modify := range []func(e *Expectation){
+		func(e *Expectation) { e.ClusterId = "other" },
+		func(e *Expectation) { e.DataSource = "qm" },
+		func(e *Expectation) { e.Step = 5 * time.Minute },
+		func(e *Expectation) { e.Type = TypeWorkload },
+	} {
+		mismatch := expect
+		modify(&mismatch)
+		if _, _, err := Check(meta, mismatch); err == nil {
+			t.Errorf("expect mismatched checkpoint refused, expectation %+v", mismatch)
+		}
+	}
+}
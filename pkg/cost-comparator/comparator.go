@@ -2,12 +2,10 @@ package cost_comparator
 
 import (
 	"context"
-	"encoding/csv"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	jsoniter "github.com/json-iterator/go"
@@ -30,6 +28,7 @@ import (
 	"github.com/gocrane/fadvisor/pkg/cost-comparator/config"
 	"github.com/gocrane/fadvisor/pkg/cost-comparator/coster"
 	"github.com/gocrane/fadvisor/pkg/cost-comparator/estimator"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/report"
 	"github.com/gocrane/fadvisor/pkg/datasource"
 	"github.com/gocrane/fadvisor/pkg/metricnaming"
 	"github.com/gocrane/fadvisor/pkg/spec"
@@ -37,6 +36,13 @@ import (
 	targetutil "github.com/gocrane/fadvisor/pkg/util/target"
 )
 
+// TargetCloud is a cloud provider of the cross provider comparison
+type TargetCloud struct {
+	Name     string
+	Provider string
+	Cloud    cloud.Cloud
+}
+
 // Now do once task analysis, mapped Data in, then reduced Data out
 type Comparator struct {
 	config              config.Config
@@ -47,29 +53,56 @@ type Comparator struct {
 	// some Intermediate cache data, cache it for other functions reuse.
 	clusterCache       cache.Cache
 	workloadsSpecCache map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ spec.CloudPodSpec
+	// podWorkloads are the root owners of the scoped pods, they are resolved once when the workloads spec is built
+	podWorkloads map[string] /*namespace-name*/ *unstructured.Unstructured
 	// NOTE: workloadsContainerDataCache is memory consuming, so for online service it is not suitable. now just used to do offline task analysis
 	containersTimeSeriesDataCache map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData
 	workloadsTimeSeriesDataCache  map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ *RawWorkloadTimeSeriesData
+	// containersDataRange and workloadsDataRange are the ranges the cached time series cover, they are recorded in the checkpoints
+	containersDataRange promapiv1.Range
+	workloadsDataRange  promapiv1.Range
+	// containersDataPartial and workloadsDataPartial are true if some time series are not fetched, the next run resumes the checkpoint of them
+	containersDataPartial bool
+	workloadsDataPartial  bool
+	// batchWorkloads are the jobs and cronjobs with the run time of their pods in the history window
+	batchWorkloads []coster.BatchWorkload
 
 	dataSource     datasource.Interface
 	estimateConfig map[string]interface{}
 	estimator      estimator.Estimator
+	// estimators are keyed by the estimator type, used by the estimator profiles
+	estimators map[string]estimator.Estimator
 	// this is your baseline estimate cloud provider, such as a tencent cloud tke cluster which is your current using cluster
 	baselineCloud cloud.Cloud
+	// targetClouds are the cloud providers the cluster workloads are compared on, such as another cloud vendor
+	targetClouds []TargetCloud
+
+	queryRangeOnce sync.Once
+	queryRange     promapiv1.Range
 }
 
-func NewComparator(config config.Config,
+func NewComparator(cfg config.Config,
 	kubeDynamicClient dynamic.Interface,
 	kubeDiscoveryClient discovery.DiscoveryInterface,
 	restMapper meta.RESTMapper,
 	fetcher targetutil.TargetInfoFetcher,
 	clusterCache cache.Cache,
 	baselineCloud cloud.Cloud,
+	targetClouds []TargetCloud,
 	dataSource datasource.Interface) *Comparator {
+	estimators := map[string]estimator.Estimator{
+		config.EstimatorStatistic: estimator.NewStatisticEstimator(),
+		config.EstimatorVpa:       estimator.NewVpaEstimator(cfg.VpaEstimator),
+	}
+	defaultEstimator, ok := estimators[cfg.Estimator]
+	if !ok {
+		defaultEstimator = estimators[config.EstimatorStatistic]
+	}
 	return &Comparator{
 		estimateConfig:      make(map[string]interface{}),
-		estimator:           estimator.NewStatisticEstimator(),
-		config:              config,
+		estimator:           defaultEstimator,
+		estimators:          estimators,
+		config:              cfg,
 		kubeDynamicClient:   kubeDynamicClient,
 		kubeDiscoveryClient: kubeDiscoveryClient,
 		restMapper:          restMapper,
@@ -77,28 +110,102 @@ func NewComparator(config config.Config,
 		clusterCache:        clusterCache,
 		dataSource:          dataSource,
 		baselineCloud:       baselineCloud,
+		targetClouds:        targetClouds,
+	}
+}
+
+// workloadEstimatorProfile return the estimator profile of the workload, nil if no profiles
+func (c *Comparator) workloadEstimatorProfile(nn types.NamespacedName, workloadPodSpec spec.CloudPodSpec) *estimator.Profile {
+	if c.config.EstimatorProfiles == nil {
+		return nil
+	}
+	workloadLabels := make(map[string]string)
+	var profileName string
+	if workloadPodSpec.PodRef != nil {
+		for k, v := range workloadPodSpec.PodRef.Labels {
+			workloadLabels[k] = v
+		}
+		profileName = workloadPodSpec.PodRef.Annotations[consts.AnnotationEstimatorProfile]
+	}
+	if workloadPodSpec.Workload != nil {
+		for k, v := range workloadPodSpec.Workload.GetLabels() {
+			workloadLabels[k] = v
+		}
+		if name, ok := workloadPodSpec.Workload.GetAnnotations()[consts.AnnotationEstimatorProfile]; ok {
+			profileName = name
+		}
 	}
+	return c.config.EstimatorProfiles.Select(nn.Namespace, workloadLabels, profileName)
 }
 
-// Init initialize some cached data and time series data, Must call before DoAnalysis
-func (c *Comparator) Init() {
+// profileEstimator return the estimator of the profile, the default estimator if the profile is nil or has no estimator
+func (c *Comparator) profileEstimator(profile *estimator.Profile) estimator.Estimator {
+	if profile == nil || profile.Estimator == "" {
+		return c.estimator
+	}
+	if e, ok := c.estimators[profile.Estimator]; ok {
+		return e
+	}
+	klog.Warningf("Unknown estimator %v of profile %v, use the default estimator", profile.Estimator, profile.Name)
+	return c.estimator
+}
+
+// resourceEstimateConfig return the estimate config of the resource, the profile config overrides the default config
+func (c *Comparator) resourceEstimateConfig(profile *estimator.Profile, resourceName string) map[string]interface{} {
+	estimateConfig := make(map[string]interface{}, len(c.estimateConfig)+1)
+	for k, v := range c.estimateConfig {
+		estimateConfig[k] = v
+	}
+	if profile != nil {
+		for k, v := range profile.Resource(resourceName).EstimateConfig() {
+			estimateConfig[k] = v
+		}
+	}
+	estimateConfig[estimator.EstimateConfigResource] = resourceName
+	return estimateConfig
+}
+
+// Init initialize some cached data and time series data, Must call before DoAnalysis.
+// If ctx is done or the fetch deadline exceeds when fetching the time series, the fetched data is kept and the analysis is based on the partial data,
+// the partial data is checkpointed if the checkpoint is enabled and the next run fetches the rest only.
+// It returns error if the time series can not be checkpointed
+func (c *Comparator) Init(ctx context.Context) error {
+	ctx, cancel := c.fetchContext(ctx)
+	defer cancel()
 	c.initWorkloadsSpec()
-	err := c.ContainerTsDataInit()
+	err := c.ContainerTsDataInit(ctx)
 	if err != nil {
-		klog.Fatalf("Failed to init container time series data: %v", err)
+		return fmt.Errorf("failed to init container time series data: %v", err)
 	}
 	if c.config.EnableWorkloadTimeSeries {
-		err = c.WorkloadTsDataInit()
+		err = c.WorkloadTsDataInit(ctx)
 		if err != nil {
-			klog.Fatalf("Failed to init workload time series data: %v", err)
+			return fmt.Errorf("failed to init workload time series data: %v", err)
+		}
+	}
+	if c.config.EnableBatch {
+		var complete bool
+		c.batchWorkloads, complete = c.InitBatchWorkloads(ctx)
+		if !complete {
+			klog.Warningf("Batch workloads pod runtime is partial")
 		}
 	}
+	return nil
 }
 
 // Now it will fetch full data to do once analysis, so it is a time consuming offline computing task, also it will consuming memory because it will do time series analysis.
 // todo: refactor to online service model when used for online deploy, split the services to online service & offline computing job.
 // ??? offline computing jobs like spark by operator way VS. online service by deployment way
-func (c *Comparator) DoAnalysis() {
+func (c *Comparator) DoAnalysis() error {
+	reporter, err := report.NewReporter(c.config.OutputMode, c.config.DataPath, c.config.ClusterId)
+	if err != nil {
+		return err
+	}
+	return c.Analyze(reporter)
+}
+
+// Analyze analyzes the cluster and reports the sections by the reporter, the reporter is closed after the analysis
+func (c *Comparator) Analyze(reporter report.Reporter) error {
 	podsSpec := c.GetAllPodsSpec()
 	nodesSpec := c.GetAllNodesSpec()
 
@@ -112,24 +219,36 @@ func (c *Comparator) DoAnalysis() {
 		WorkloadsRecSpec: workloadsRecs,
 		WorkloadsSpec:    c.workloadsSpecCache,
 		Pricer:           c.baselineCloud,
+		BatchWorkloads:   c.batchWorkloads,
+		NodeCostByPods:   !c.config.Scope.Empty(),
+	}
+	if c.config.ClusterLevel != "" {
+		costerCtx.ClusterLevel = &c.config.ClusterLevel
+	}
+	if c.config.EnableWorkloadTimeSeries {
+		costerCtx.WorkloadsReplicas = c.WorkloadsAverageReplicas()
 	}
 
-	c.ReportOriginalResourceSummary()
-	c.ReportOriginalCostSummary(costerCtx)
-	c.ReportRawServerlessCostSummary(costerCtx)
-	c.ReportRecommendedResourceSummary(costerCtx)
-	c.ReportRecommendedCostSummary(costerCtx)
-
-	c.ReportOriginalWorkloadsResourceDistribution(costerCtx)
-	c.ReportRecommendedWorkloadsResourceDistribution(costerCtx)
-
+	err := c.Report(reporter, costerCtx)
+	if closeErr := reporter.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 func Int642Str(a int64) string {
 	return fmt.Sprintf("%v", a)
 }
 
+// getQueryRange return the query range of the history, it is computed once so the fetching and checkpoints of a run share the same window
 func (c *Comparator) getQueryRange() promapiv1.Range {
+	c.queryRangeOnce.Do(func() {
+		c.queryRange = c.buildQueryRange()
+	})
+	return c.queryRange
+}
+
+func (c *Comparator) buildQueryRange() promapiv1.Range {
 	end := time.Now()
 	var err error
 	if c.config.History.EndTime != "" {
@@ -149,9 +268,15 @@ func (c *Comparator) getQueryRange() promapiv1.Range {
 
 func (c *Comparator) GetAllPodsSpec() map[string] /*namespace-name*/ spec.CloudPodSpec {
 	res := make(map[string]spec.CloudPodSpec)
-	pods := c.clusterCache.GetPods()
+	pods := c.kindScopedPods()
 	for _, pod := range pods {
-		res[klog.KObj(pod).String()] = c.baselineCloud.Pod2Spec(pod)
+		key := klog.KObj(pod).String()
+		podSpec := c.baselineCloud.Pod2Spec(pod)
+		// the usage is used to allocate the node cost to the pod by the usage and max pod allocation modes
+		if owner, ok := c.podWorkloads[key]; ok {
+			podSpec.Usage = c.workloadPodUsage(owner.GetKind(), types.NamespacedName{Namespace: owner.GetNamespace(), Name: owner.GetName()}, pod)
+		}
+		res[key] = podSpec
 	}
 	return res
 }
@@ -166,10 +291,73 @@ func (c *Comparator) GetAllNodesSpec() map[string] /*nodename*/ spec.CloudNodeSp
 	return res
 }
 
+// BinPackingCandidates return the instance types the recommended pods are packed on, a node of each distinct instance type of the real
+// nodes and the configured candidates. The instance types of the nodes without the instance type label are distinguished by cpu and memory.
+// The configured candidates are not instances of the cloud, their configured hourly prices are returned keyed by the instance type
+func (c *Comparator) BinPackingCandidates() ([]spec.CloudNodeSpec, map[string]float64) {
+	var candidates []spec.CloudNodeSpec
+	prices := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, node := range c.clusterCache.GetNodes() {
+		if c.baselineCloud.IsVirtualNode(node) {
+			continue
+		}
+		nodeSpec := c.baselineCloud.Node2Spec(node)
+		key := nodeSpec.InstanceType
+		if key == "" {
+			key = fmt.Sprintf("%v-%v-%v", nodeSpec.Cpu.String(), nodeSpec.Mem.String(), nodeSpec.Arch)
+			nodeSpec.InstanceType = key
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		candidates = append(candidates, nodeSpec)
+	}
+	for _, candidate := range c.config.BinPacking.Candidates {
+		if seen[candidate.InstanceType] {
+			continue
+		}
+		seen[candidate.InstanceType] = true
+		candidates = append(candidates, c.baselineCloud.Node2Spec(candidate.Node()))
+		prices[candidate.InstanceType] = candidate.HourlyPrice
+	}
+	return candidates, prices
+}
+
+// scopedPods returns the pods of the cluster in the namespaces and matching the label selectors of the scope
+func (c *Comparator) scopedPods() []*v1.Pod {
+	var pods []*v1.Pod
+	for _, pod := range c.clusterCache.GetPods() {
+		if c.config.Scope.NamespaceInScope(pod.Namespace) && c.config.Scope.LabelsInScope(pod.Labels) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+// kindScopedPods returns the scoped pods whose root owner is of a kind in scope, the root owners are resolved when the workloads
+// spec is built. The pods whose root owner is not resolved are out of scope if the scope has kinds
+func (c *Comparator) kindScopedPods() []*v1.Pod {
+	pods := c.scopedPods()
+	if len(c.config.Scope.Kinds) == 0 && len(c.config.Scope.ExcludeKinds) == 0 {
+		return pods
+	}
+	var res []*v1.Pod
+	for _, pod := range pods {
+		owner, ok := c.podWorkloads[klog.KObj(pod).String()]
+		if ok && c.config.Scope.KindInScope(owner.GetKind()) {
+			res = append(res, pod)
+		}
+	}
+	return res
+}
+
 // build workloads by inverted-index pods
 func (c *Comparator) initWorkloadsSpec() map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ spec.CloudPodSpec {
 	workloads := make(map[string]map[types.NamespacedName]spec.CloudPodSpec)
-	pods := c.clusterCache.GetPods()
+	podWorkloads := make(map[string]*unstructured.Unstructured)
+	pods := c.scopedPods()
 	for _, pod := range pods {
 		unstruct, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
 		if err != nil {
@@ -181,7 +369,11 @@ func (c *Comparator) initWorkloadsSpec() map[string] /*kind*/ map[types.Namespac
 			klog.V(4).Info(err)
 			continue
 		}
+		podWorkloads[klog.KObj(pod).String()] = rootUnstruct
 		kind := rootUnstruct.GetKind()
+		if !c.config.Scope.KindInScope(kind) {
+			continue
+		}
 		nnworklod, ok := workloads[kind]
 		if !ok {
 			nnworklod = make(map[types.NamespacedName]spec.CloudPodSpec)
@@ -195,7 +387,8 @@ func (c *Comparator) initWorkloadsSpec() map[string] /*kind*/ map[types.Namespac
 		}
 		podSpec := c.baselineCloud.Pod2Spec(pod)
 		if strings.ToLower(kind) != "pod" {
-			// because of job & cronjob is very special workload, it is once task, we can not estimate is directly
+			// because of job & cronjob is very special workload, it is once task, we can not estimate is directly.
+			// they are costed by the run time of their pods if batch costing is enabled
 			if strings.ToLower(kind) == "cronjob" || strings.ToLower(kind) == "job" {
 				klog.Warningf("Ignore %v %v: %v", rootUnstruct.GetAPIVersion(), kind, nn)
 				continue
@@ -216,12 +409,13 @@ func (c *Comparator) initWorkloadsSpec() map[string] /*kind*/ map[types.Namespac
 		nnworklod[nn] = podSpec
 	}
 	c.workloadsSpecCache = workloads
+	c.podWorkloads = podWorkloads
 	return workloads
 }
 
 func (c *Comparator) GetAllWorkloads() []*unstructured.Unstructured {
 	var workloads []*unstructured.Unstructured
-	pods := c.clusterCache.GetPods()
+	pods := c.scopedPods()
 	for _, pod := range pods {
 		unstruct, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
 		if err != nil {
@@ -233,6 +427,9 @@ func (c *Comparator) GetAllWorkloads() []*unstructured.Unstructured {
 			klog.V(4).Infof("Failed to FindRootOwner pod %v: %v", klog.KObj(pod), err)
 			continue
 		}
+		if !c.config.Scope.KindInScope(rootUnstruct.GetKind()) {
+			continue
+		}
 		workloads = append(workloads, rootUnstruct)
 
 	}
@@ -246,6 +443,8 @@ type RawContainerTimeSeriesData struct {
 	MemRequests []*common.TimeSeries
 	CpuLimits   []*common.TimeSeries
 	MemLimits   []*common.TimeSeries
+	// Range is the range the series cover, it is shorter than the window if the series are not refreshed, zero means the window
+	Range promapiv1.Range
 }
 
 type RawWorkloadTimeSeriesData struct {
@@ -256,6 +455,8 @@ type RawWorkloadTimeSeriesData struct {
 	CpuLimits   []*common.TimeSeries
 	MemLimits   []*common.TimeSeries
 	Replicas    []*common.TimeSeries
+	// Range is the range the series cover like RawContainerTimeSeriesData
+	Range promapiv1.Range
 }
 
 func PathExists(path string) (bool, error) {
@@ -269,931 +470,88 @@ func PathExists(path string) (bool, error) {
 	return false, err
 }
 
-func (c *Comparator) ContainerTsDataInit() error {
-	data, err := c.LoadContainerTimeSeriesDataFromCheckpoint()
-	if err == nil {
-		c.containersTimeSeriesDataCache = data
-		return nil
-	}
-	klog.Errorf("Failed to load workload container data from check point: %v, init from datasource", err)
-	data = c.fetchContainerData()
-	c.containersTimeSeriesDataCache = data
-	klog.V(2).Infof("Succeed to load workload container data from data source, checkpointing it")
-	if c.config.EnableContainerCheckpoint {
-		err = c.ContainerDataCheckpoint()
-		return err
-	}
-	return nil
-}
-
-func (c *Comparator) WorkloadTsDataInit() error {
-	data, err := c.LoadWorkloadTimeSeriesDataFromCheckpoint()
-	if err == nil {
-		c.workloadsTimeSeriesDataCache = data
+// ContainerTsDataInit loads the container time series from the checkpoint, or fetches them from the datasource.
+// If incremental checkpoint is enabled and the checkpoint ends before the window, only the missing range is fetched and merged.
+// If the checkpoint is partial, the containers missing from it are fetched. The partial data is checkpointed too, so the next run resumes it.
+func (c *Comparator) ContainerTsDataInit(ctx context.Context) error {
+	window := c.getQueryRange()
+	cached, cachedRange, partial, err := c.loadContainerTimeSeriesDataFromCheckpoint()
+	if err == nil && !partial && (!c.config.CheckpointIncremental || !cachedRange.End.Before(window.End)) {
+		c.containersTimeSeriesDataCache = cached
+		c.containersDataRange = cachedRange
 		return nil
 	}
-	klog.Errorf("Failed to load workload time series data from check point: %v, init from datasource", err)
-	data = c.fetchWorkloadMetricData()
-	c.workloadsTimeSeriesDataCache = data
-	klog.V(2).Infof("Succeed to load workload time series data from data source, checkpointing it")
-	if c.config.EnableWorkloadCheckpoint {
-		err = c.workloadTimeSeriesDataCheckpoint()
-	}
-	return err
-}
-
-func (c *Comparator) LoadContainerTimeSeriesDataFromCheckpoint() (map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData, error) {
-	checkPointFile := c.containerTimeSeriesDataCheckpointName()
-	result := make(map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData)
-
-	fileData, err := os.Open(checkPointFile)
+	dataRange := window
 	if err != nil {
-		return result, err
-	}
-	reader := csv.NewReader(fileData)
-	reader.Comma = '\t'
-	lines, err := reader.ReadAll()
-	if err != nil {
-		return result, err
-	}
-
-	if len(lines) == 0 || len(lines[0]) <= 4 {
-		return nil, fmt.Errorf("wrong fmt csv, header is wrong")
-	}
-
-	cpuUsageHeaders := make([]string, 0)
-	memUsageHeaders := make([]string, 0)
-	cpuRequestHeaders := make([]string, 0)
-	memRequestHeaders := make([]string, 0)
-	cpuLimitHeaders := make([]string, 0)
-	memLimitHeaders := make([]string, 0)
-
-	headers := lines[0][0:4]
-
-	for _, field := range lines[0] {
-		if strings.HasPrefix(field, "CpuUsage-") {
-			cpuUsageHeaders = append(cpuUsageHeaders, field)
-		}
-		if strings.HasPrefix(field, "MemUsage-") {
-			memUsageHeaders = append(memUsageHeaders, field)
-		}
-		if strings.HasPrefix(field, "CpuRequest-") {
-			cpuRequestHeaders = append(cpuRequestHeaders, field)
-		}
-		if strings.HasPrefix(field, "MemRequest-") {
-			memRequestHeaders = append(memRequestHeaders, field)
-		}
-		if strings.HasPrefix(field, "CpuLimit-") {
-			cpuLimitHeaders = append(cpuLimitHeaders, field)
-		}
-		if strings.HasPrefix(field, "MemLimit-") {
-			memLimitHeaders = append(memLimitHeaders, field)
+		klog.Errorf("Failed to load workload container data from check point: %v, init from datasource", err)
+		cached = nil
+	} else {
+		if partial {
+			klog.Infof("Resume the partial workload container data checkpoint from datasource")
+		} else {
+			klog.Infof("Refresh workload container data of [%v, %v] from datasource", cachedRange.End, window.End)
 		}
+		dataRange.Start = cachedRange.Start
 	}
-
-	headers = append(headers, cpuUsageHeaders...)
-	headers = append(headers, memUsageHeaders...)
-	headers = append(headers, cpuRequestHeaders...)
-	headers = append(headers, memRequestHeaders...)
-	headers = append(headers, cpuLimitHeaders...)
-	headers = append(headers, memLimitHeaders...)
-
-	// skip header
-	for line, row := range lines[1:] {
-		if len(row) < len(headers) {
-			klog.Warningf("wrong length row")
-		}
-		kind := row[0]
-		namespace := row[1]
-		name := row[2]
-		container := row[3]
-
-		kindNN, ok := result[kind]
-		if !ok {
-			kindNN = make(map[types.NamespacedName]map[string]*RawContainerTimeSeriesData)
-			result[kind] = kindNN
-		}
-		nn := types.NamespacedName{Namespace: namespace, Name: name}
-		containersMap, ok := kindNN[nn]
-		if !ok {
-			containersMap = make(map[string]*RawContainerTimeSeriesData)
-			kindNN[nn] = containersMap
-		}
-
-		cpuTs := common.NewTimeSeries()
-		memTs := common.NewTimeSeries()
-		CpuRequestTs := common.NewTimeSeries()
-		MemRequestTs := common.NewTimeSeries()
-		CpuLimitTs := common.NewTimeSeries()
-		MemLimitTs := common.NewTimeSeries()
-
-		row := row[4:]
-		indexStart := 0
-		for i, colName := range cpuUsageHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			cpuTs.AppendSample(ts, val)
-		}
-		indexStart += len(cpuUsageHeaders)
-		for i, colName := range memUsageHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			memTs.AppendSample(ts, val)
-		}
-		indexStart += len(memUsageHeaders)
-		for i, colName := range cpuRequestHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			CpuRequestTs.AppendSample(ts, val)
-		}
-		indexStart += len(cpuRequestHeaders)
-		for i, colName := range memRequestHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			MemRequestTs.AppendSample(ts, val)
-		}
-		indexStart += len(memRequestHeaders)
-		for i, colName := range cpuLimitHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			CpuLimitTs.AppendSample(ts, val)
-		}
-		indexStart += len(cpuLimitHeaders)
-		for i, colName := range memLimitHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			MemLimitTs.AppendSample(ts, val)
-		}
-		containersMap[container] = &RawContainerTimeSeriesData{
-			Cpu:         []*common.TimeSeries{cpuTs},
-			Mem:         []*common.TimeSeries{memTs},
-			CpuRequests: []*common.TimeSeries{CpuRequestTs},
-			MemRequests: []*common.TimeSeries{MemRequestTs},
-			CpuLimits:   []*common.TimeSeries{CpuLimitTs},
-			MemLimits:   []*common.TimeSeries{MemLimitTs},
-		}
-	}
-
-	return result, nil
-	//file, err := os.Open(checkPointFile)
-	//defer file.Close()
-	//decoder := json.NewDecoder(file)
-	//// Read the array open bracket
-	//decoder.Token()
-	//for decoder.More() {
-	//	decoder.Decode(&data)
-	//}
-	//return data, nil
-}
-
-func (c *Comparator) LoadWorkloadTimeSeriesDataFromCheckpoint() (map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ *RawWorkloadTimeSeriesData, error) {
-	checkPointFile := c.workloadTimeSeriesDataCheckpointName()
-	result := make(map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ *RawWorkloadTimeSeriesData)
-
-	fileData, err := os.Open(checkPointFile)
-	if err != nil {
-		return result, err
-	}
-	reader := csv.NewReader(fileData)
-	reader.Comma = '\t'
-	lines, err := reader.ReadAll()
-	if err != nil {
-		return result, err
-	}
-
-	if len(lines) == 0 || len(lines[0]) <= 4 {
-		return nil, fmt.Errorf("wrong fmt csv, header is wrong")
-	}
-
-	cpuUsageHeaders := make([]string, 0)
-	memUsageHeaders := make([]string, 0)
-	cpuRequestHeaders := make([]string, 0)
-	memRequestHeaders := make([]string, 0)
-	cpuLimitHeaders := make([]string, 0)
-	memLimitHeaders := make([]string, 0)
-	replicaHeaders := make([]string, 0)
-
-	headers := lines[0][0:3]
-
-	for _, field := range lines[0] {
-		if strings.HasPrefix(field, "CpuUsage-") {
-			cpuUsageHeaders = append(cpuUsageHeaders, field)
-		}
-		if strings.HasPrefix(field, "MemUsage-") {
-			memUsageHeaders = append(memUsageHeaders, field)
-		}
-		if strings.HasPrefix(field, "CpuRequest-") {
-			cpuRequestHeaders = append(cpuRequestHeaders, field)
-		}
-		if strings.HasPrefix(field, "MemRequest-") {
-			memRequestHeaders = append(memRequestHeaders, field)
-		}
-		if strings.HasPrefix(field, "CpuLimit-") {
-			cpuLimitHeaders = append(cpuLimitHeaders, field)
-		}
-		if strings.HasPrefix(field, "MemLimit-") {
-			memLimitHeaders = append(memLimitHeaders, field)
-		}
-		if strings.HasPrefix(field, "Replicas-") {
-			replicaHeaders = append(replicaHeaders, field)
-		}
-	}
-
-	headers = append(headers, cpuUsageHeaders...)
-	headers = append(headers, memUsageHeaders...)
-	headers = append(headers, cpuRequestHeaders...)
-	headers = append(headers, memRequestHeaders...)
-	headers = append(headers, cpuLimitHeaders...)
-	headers = append(headers, memLimitHeaders...)
-	headers = append(headers, replicaHeaders...)
-
-	// skip header
-	for line, row := range lines[1:] {
-		if len(row) < len(headers) {
-			klog.Warningf("wrong length row")
-		}
-		kind := row[0]
-		namespace := row[1]
-		name := row[2]
-
-		kindNN, ok := result[kind]
-		if !ok {
-			kindNN = make(map[types.NamespacedName]*RawWorkloadTimeSeriesData)
-			result[kind] = kindNN
-		}
-		nn := types.NamespacedName{Namespace: namespace, Name: name}
-
-		cpuTs := common.NewTimeSeries()
-		memTs := common.NewTimeSeries()
-		CpuRequestTs := common.NewTimeSeries()
-		MemRequestTs := common.NewTimeSeries()
-		CpuLimitTs := common.NewTimeSeries()
-		MemLimitTs := common.NewTimeSeries()
-		ReplicaTs := common.NewTimeSeries()
-
-		row := row[3:]
-		indexStart := 0
-		for i, colName := range cpuUsageHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			cpuTs.AppendSample(ts, val)
-		}
-		indexStart += len(cpuUsageHeaders)
-		for i, colName := range memUsageHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			memTs.AppendSample(ts, val)
-		}
-		indexStart += len(memUsageHeaders)
-		for i, colName := range cpuRequestHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			CpuRequestTs.AppendSample(ts, val)
-		}
-		indexStart += len(cpuRequestHeaders)
-		for i, colName := range memRequestHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			MemRequestTs.AppendSample(ts, val)
-		}
-		for i, colName := range cpuLimitHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			CpuLimitTs.AppendSample(ts, val)
-		}
-		indexStart += len(cpuLimitHeaders)
-		for i, colName := range memLimitHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			MemLimitTs.AppendSample(ts, val)
-		}
-		indexStart += len(memLimitHeaders)
-		for i, colName := range replicaHeaders {
-			var ts int64
-			var err error
-			splits := strings.Split(colName, "-")
-			if len(splits) >= 3 {
-				ts, err = strconv.ParseInt(splits[2], 10, 64)
-				if err != nil {
-					klog.Warningf("line %v col %v parsed failed", line, colName)
-				}
-			}
-			// null value ignored
-			if row[indexStart+i] == "null" {
-				continue
-			}
-			val, err := strconv.ParseFloat(row[indexStart+i], 64)
-			if err != nil {
-				continue
-			}
-			ReplicaTs.AppendSample(ts, val)
-		}
-		kindNN[nn] = &RawWorkloadTimeSeriesData{
-			Cpu:         []*common.TimeSeries{cpuTs},
-			Mem:         []*common.TimeSeries{memTs},
-			CpuRequests: []*common.TimeSeries{CpuRequestTs},
-			MemRequests: []*common.TimeSeries{MemRequestTs},
-			CpuLimits:   []*common.TimeSeries{CpuLimitTs},
-			MemLimits:   []*common.TimeSeries{MemLimitTs},
-			Replicas:    []*common.TimeSeries{ReplicaTs},
-		}
-	}
-
-	return result, nil
-}
-
-func (c *Comparator) containerTimeSeriesDataCheckpointName() string {
-	return filepath.Join(c.config.DataPath, c.config.ClusterId+"-workloads-container-timeseries.csv")
-}
-
-func (c *Comparator) workloadTimeSeriesDataCheckpointName() string {
-	return filepath.Join(c.config.DataPath, c.config.ClusterId+"-workloads-timeseries.csv")
-}
-
-func (c *Comparator) ContainerDataCheckpoint() error {
-	//checkPointFile := c.checkpointName()
-	//var json = jsoniter.ConfigCompatibleWithStandardLibrary
-	//file, _ := os.OpenFile(checkPointFile, os.O_CREATE, os.ModePerm)
-	//defer file.Close()
-	//encoder := json.NewEncoder(file)
-	//return encoder.Encode(data)
-
-	maxHeaderLen := 0
-	maxCpuUsageHeaderLen := 0
-	maxMemUsageHeaderLen := 0
-	maxCpuRequestHeaderLen := 0
-	maxMemRequestHeaderLen := 0
-	maxCpuLimitHeaderLen := 0
-	maxMemLimitHeaderLen := 0
-	for _, kindWorkloads := range c.containersTimeSeriesDataCache {
-		for _, workloadContainers := range kindWorkloads {
-			for _, data := range workloadContainers {
-
-				cpuUsageLen := 0
-				memUsageLen := 0
-				cpuRequestLen := 0
-				memRequestLen := 0
-				cpuLimitLen := 0
-				memLimitLen := 0
-
-				if len(data.Cpu) > 0 && data.Cpu[0] != nil {
-					cpuUsageLen = len(data.Cpu[0].Samples)
-				}
-				if len(data.Mem) > 0 && data.Mem[0] != nil {
-					memUsageLen = len(data.Mem[0].Samples)
-				}
-				if len(data.CpuRequests) > 0 && data.CpuRequests[0] != nil {
-					cpuRequestLen = len(data.CpuRequests[0].Samples)
-				}
-				if len(data.MemRequests) > 0 && data.MemRequests[0] != nil {
-					memRequestLen = len(data.MemRequests[0].Samples)
-				}
-				if len(data.CpuLimits) > 0 && data.CpuLimits[0] != nil {
-					cpuLimitLen = len(data.CpuLimits[0].Samples)
-				}
-				if len(data.MemLimits) > 0 && data.MemLimits[0] != nil {
-					memLimitLen = len(data.MemLimits[0].Samples)
-				}
-
-				totalHeaderLen := cpuUsageLen + memUsageLen + cpuRequestLen + memRequestLen + cpuLimitLen + memLimitLen
-				if maxHeaderLen < totalHeaderLen {
-					maxHeaderLen = totalHeaderLen
-				}
-
-				if maxCpuUsageHeaderLen < cpuUsageLen {
-					maxCpuUsageHeaderLen = cpuUsageLen
-				}
-				if maxMemUsageHeaderLen < memUsageLen {
-					maxMemUsageHeaderLen = memUsageLen
-				}
-				if maxCpuRequestHeaderLen < cpuRequestLen {
-					maxCpuRequestHeaderLen = cpuRequestLen
-				}
-				if maxMemRequestHeaderLen < memRequestLen {
-					maxMemRequestHeaderLen = memRequestLen
-				}
-				if maxCpuLimitHeaderLen < cpuLimitLen {
-					maxCpuLimitHeaderLen = cpuLimitLen
-				}
-				if maxMemLimitHeaderLen < memLimitLen {
-					maxMemLimitHeaderLen = memLimitLen
-				}
-			}
-		}
-	}
-
-	headers := []string{"Kind", "Namespace", "Name", "Container"}
-	cpuUsageHeaders := make([]string, maxCpuUsageHeaderLen)
-	memUsageHeaders := make([]string, maxMemUsageHeaderLen)
-	cpuRequestHeaders := make([]string, maxCpuRequestHeaderLen)
-	memRequestHeaders := make([]string, maxMemRequestHeaderLen)
-	cpuLimitHeaders := make([]string, maxCpuLimitHeaderLen)
-	memLimitHeaders := make([]string, maxMemLimitHeaderLen)
-
-	for i := 0; i < maxCpuUsageHeaderLen; i++ {
-		cpuUsageHeaders[i] = fmt.Sprintf("CpuUsage-%v", i)
-	}
-	for i := 0; i < maxMemUsageHeaderLen; i++ {
-		memUsageHeaders[i] = fmt.Sprintf("MemUsage-%v", i)
-	}
-	for i := 0; i < maxCpuRequestHeaderLen; i++ {
-		cpuRequestHeaders[i] = fmt.Sprintf("CpuRequest-%v", i)
-	}
-	for i := 0; i < maxMemRequestHeaderLen; i++ {
-		memRequestHeaders[i] = fmt.Sprintf("MemRequest-%v", i)
-	}
-	for i := 0; i < maxCpuLimitHeaderLen; i++ {
-		cpuLimitHeaders[i] = fmt.Sprintf("CpuLimit-%v", i)
-	}
-	for i := 0; i < maxMemLimitHeaderLen; i++ {
-		memLimitHeaders[i] = fmt.Sprintf("MemLimit-%v", i)
-	}
-
-	var data [][]string
-	for kind, kindWorkloads := range c.containersTimeSeriesDataCache {
-		for nn, workloadContainers := range kindWorkloads {
-			for container, containerData := range workloadContainers {
-				row := []string{kind, nn.Namespace, nn.Name, container}
-
-				for i := range cpuUsageHeaders {
-					if len(containerData.Cpu) > 0 && i < len(containerData.Cpu[0].Samples) {
-						sample := containerData.Cpu[0].Samples[i]
-						row = append(row, Float642Str(sample.Value))
-						splits := strings.Split(cpuUsageHeaders[i], "-")
-						cpuUsageHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-					} else {
-						row = append(row, "null")
-					}
-				}
-				for i := range memUsageHeaders {
-					if len(containerData.Mem) > 0 && i < len(containerData.Mem[0].Samples) {
-						sample := containerData.Mem[0].Samples[i]
-						row = append(row, Float642Str(sample.Value))
-						splits := strings.Split(memUsageHeaders[i], "-")
-						memUsageHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-					} else {
-						row = append(row, "null")
-					}
-				}
-				for i := range cpuRequestHeaders {
-					if len(containerData.CpuRequests) > 0 && i < len(containerData.CpuRequests[0].Samples) {
-						sample := containerData.CpuRequests[0].Samples[i]
-						row = append(row, Float642Str(sample.Value))
-						splits := strings.Split(cpuRequestHeaders[i], "-")
-						cpuRequestHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-					} else {
-						row = append(row, "null")
-					}
-				}
-				for i := range memRequestHeaders {
-					if len(containerData.MemRequests) > 0 && i < len(containerData.MemRequests[0].Samples) {
-						sample := containerData.MemRequests[0].Samples[i]
-						row = append(row, Float642Str(sample.Value))
-						splits := strings.Split(memRequestHeaders[i], "-")
-						memRequestHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-					} else {
-						row = append(row, "null")
-					}
-				}
-				for i := range cpuLimitHeaders {
-					if len(containerData.CpuLimits) > 0 && i < len(containerData.CpuLimits[0].Samples) {
-						sample := containerData.CpuLimits[0].Samples[i]
-						row = append(row, Float642Str(sample.Value))
-						splits := strings.Split(cpuLimitHeaders[i], "-")
-						cpuLimitHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-					} else {
-						row = append(row, "null")
-					}
-				}
-				for i := range memLimitHeaders {
-					if len(containerData.MemLimits) > 0 && i < len(containerData.MemLimits[0].Samples) {
-						sample := containerData.MemLimits[0].Samples[i]
-						row = append(row, Float642Str(sample.Value))
-						splits := strings.Split(memLimitHeaders[i], "-")
-						memLimitHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-					} else {
-						row = append(row, "null")
-					}
-				}
-				data = append(data, row)
-			}
-		}
-	}
-
-	headers = append(headers, cpuUsageHeaders...)
-	headers = append(headers, memUsageHeaders...)
-	headers = append(headers, cpuRequestHeaders...)
-	headers = append(headers, memRequestHeaders...)
-	headers = append(headers, cpuLimitHeaders...)
-	headers = append(headers, memLimitHeaders...)
-
-	csvFile, err := os.Create(c.containerTimeSeriesDataCheckpointName())
-	if err != nil {
-		return err
-	}
-	csvW := csv.NewWriter(csvFile)
-	csvW.Comma = '\t'
-	err = csvW.Write(headers)
-	if err != nil {
-		return err
+	data, complete := c.fetchContainerData(ctx, cached)
+	c.containersTimeSeriesDataCache = data
+	c.containersDataRange = dataRange
+	c.containersDataPartial = !complete
+	if !complete {
+		klog.Warningf("Workload container data is partial, the checkpoint of it is resumed by the next run")
+	} else {
+		klog.V(2).Infof("Succeed to load workload container data from data source")
 	}
-	err = csvW.WriteAll(data)
-	if err != nil {
-		return err
+	if c.config.EnableContainerCheckpoint {
+		return c.ContainerDataCheckpoint()
 	}
 	return nil
 }
 
-func (c *Comparator) workloadTimeSeriesDataCheckpoint() error {
-	maxHeaderLen := 0
-	maxCpuUsageHeaderLen := 0
-	maxMemUsageHeaderLen := 0
-	maxCpuRequestHeaderLen := 0
-	maxMemRequestHeaderLen := 0
-	maxCpuLimitHeaderLen := 0
-	maxMemLimitHeaderLen := 0
-	maxReplicaHeaderLen := 0
-	for _, kindWorkloads := range c.workloadsTimeSeriesDataCache {
-		for _, workloadTSData := range kindWorkloads {
-
-			cpuUsageLen := 0
-			memUsageLen := 0
-			cpuRequestLen := 0
-			memRequestLen := 0
-			cpuLimitLen := 0
-			memLimitLen := 0
-			replicaLen := 0
-
-			if len(workloadTSData.Cpu) > 0 && workloadTSData.Cpu[0] != nil {
-				cpuUsageLen = len(workloadTSData.Cpu[0].Samples)
-			}
-			if len(workloadTSData.Mem) > 0 && workloadTSData.Mem[0] != nil {
-				memUsageLen = len(workloadTSData.Mem[0].Samples)
-			}
-			if len(workloadTSData.CpuRequests) > 0 && workloadTSData.CpuRequests[0] != nil {
-				cpuRequestLen = len(workloadTSData.CpuRequests[0].Samples)
-			}
-			if len(workloadTSData.MemRequests) > 0 && workloadTSData.MemRequests[0] != nil {
-				memRequestLen = len(workloadTSData.MemRequests[0].Samples)
-			}
-			if len(workloadTSData.CpuLimits) > 0 && workloadTSData.CpuLimits[0] != nil {
-				cpuLimitLen = len(workloadTSData.CpuLimits[0].Samples)
-			}
-			if len(workloadTSData.MemLimits) > 0 && workloadTSData.MemLimits[0] != nil {
-				memLimitLen = len(workloadTSData.MemLimits[0].Samples)
-			}
-			if len(workloadTSData.Replicas) > 0 && workloadTSData.Replicas[0] != nil {
-				replicaLen = len(workloadTSData.Replicas[0].Samples)
-			}
-
-			totalHeaderLen := cpuUsageLen + memUsageLen + cpuRequestLen + memRequestLen + cpuLimitLen + memLimitLen + replicaLen
-			if maxHeaderLen < totalHeaderLen {
-				maxHeaderLen = totalHeaderLen
-			}
-
-			if maxCpuUsageHeaderLen < cpuUsageLen {
-				maxCpuUsageHeaderLen = cpuUsageLen
-			}
-			if maxMemUsageHeaderLen < memUsageLen {
-				maxMemUsageHeaderLen = memUsageLen
-			}
-			if maxCpuRequestHeaderLen < cpuRequestLen {
-				maxCpuRequestHeaderLen = cpuRequestLen
-			}
-			if maxMemRequestHeaderLen < memRequestLen {
-				maxMemRequestHeaderLen = memRequestLen
-			}
-			if maxCpuLimitHeaderLen < cpuLimitLen {
-				maxCpuLimitHeaderLen = cpuLimitLen
-			}
-			if maxMemLimitHeaderLen < memLimitLen {
-				maxMemLimitHeaderLen = memLimitLen
-			}
-			if maxReplicaHeaderLen < replicaLen {
-				maxReplicaHeaderLen = replicaLen
-			}
-		}
-	}
-
-	headers := []string{"Kind", "Namespace", "Name"}
-	cpuUsageHeaders := make([]string, maxCpuUsageHeaderLen)
-	memUsageHeaders := make([]string, maxMemUsageHeaderLen)
-	cpuRequestHeaders := make([]string, maxCpuRequestHeaderLen)
-	memRequestHeaders := make([]string, maxMemRequestHeaderLen)
-	cpuLimitHeaders := make([]string, maxCpuLimitHeaderLen)
-	memLimitHeaders := make([]string, maxMemLimitHeaderLen)
-	replicaHeaders := make([]string, maxReplicaHeaderLen)
-
-	for i := 0; i < maxCpuUsageHeaderLen; i++ {
-		cpuUsageHeaders[i] = fmt.Sprintf("CpuUsage-%v", i)
-	}
-	for i := 0; i < maxMemUsageHeaderLen; i++ {
-		memUsageHeaders[i] = fmt.Sprintf("MemUsage-%v", i)
-	}
-	for i := 0; i < maxCpuRequestHeaderLen; i++ {
-		cpuRequestHeaders[i] = fmt.Sprintf("CpuRequest-%v", i)
-	}
-	for i := 0; i < maxMemRequestHeaderLen; i++ {
-		memRequestHeaders[i] = fmt.Sprintf("MemRequest-%v", i)
-	}
-	for i := 0; i < maxCpuLimitHeaderLen; i++ {
-		cpuLimitHeaders[i] = fmt.Sprintf("CpuLimit-%v", i)
-	}
-	for i := 0; i < maxMemLimitHeaderLen; i++ {
-		memLimitHeaders[i] = fmt.Sprintf("MemLimit-%v", i)
-	}
-	for i := 0; i < maxReplicaHeaderLen; i++ {
-		replicaHeaders[i] = fmt.Sprintf("Replicas-%v", i)
-	}
-
-	var data [][]string
-	for kind, kindWorkloads := range c.workloadsTimeSeriesDataCache {
-		for nn, workload := range kindWorkloads {
-			row := []string{kind, nn.Namespace, nn.Name}
-
-			for i := range cpuUsageHeaders {
-				if len(workload.Cpu) > 0 && i < len(workload.Cpu[0].Samples) {
-					sample := workload.Cpu[0].Samples[i]
-					row = append(row, Float642Str(sample.Value))
-					splits := strings.Split(cpuUsageHeaders[i], "-")
-					cpuUsageHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-				} else {
-					row = append(row, "null")
-				}
-			}
-			for i := range memUsageHeaders {
-				if len(workload.Mem) > 0 && i < len(workload.Mem[0].Samples) {
-					sample := workload.Mem[0].Samples[i]
-					row = append(row, Float642Str(sample.Value))
-					splits := strings.Split(memUsageHeaders[i], "-")
-					memUsageHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-				} else {
-					row = append(row, "null")
-				}
-			}
-			for i := range cpuRequestHeaders {
-				if len(workload.CpuRequests) > 0 && i < len(workload.CpuRequests[0].Samples) {
-					sample := workload.CpuRequests[0].Samples[i]
-					row = append(row, Float642Str(sample.Value))
-					splits := strings.Split(cpuRequestHeaders[i], "-")
-					cpuRequestHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-				} else {
-					row = append(row, "null")
-				}
-			}
-			for i := range memRequestHeaders {
-				if len(workload.MemRequests) > 0 && i < len(workload.MemRequests[0].Samples) {
-					sample := workload.MemRequests[0].Samples[i]
-					row = append(row, Float642Str(sample.Value))
-					splits := strings.Split(memRequestHeaders[i], "-")
-					memRequestHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-				} else {
-					row = append(row, "null")
-				}
-			}
-			for i := range cpuLimitHeaders {
-				if len(workload.CpuLimits) > 0 && i < len(workload.CpuLimits[0].Samples) {
-					sample := workload.CpuLimits[0].Samples[i]
-					row = append(row, Float642Str(sample.Value))
-					splits := strings.Split(cpuLimitHeaders[i], "-")
-					cpuLimitHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-				} else {
-					row = append(row, "null")
-				}
-			}
-			for i := range memLimitHeaders {
-				if len(workload.MemLimits) > 0 && i < len(workload.MemLimits[0].Samples) {
-					sample := workload.MemLimits[0].Samples[i]
-					row = append(row, Float642Str(sample.Value))
-					splits := strings.Split(memLimitHeaders[i], "-")
-					memLimitHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-				} else {
-					row = append(row, "null")
-				}
-			}
-			for i := range replicaHeaders {
-				if len(workload.Replicas) > 0 && i < len(workload.Replicas[0].Samples) {
-					sample := workload.Replicas[0].Samples[i]
-					row = append(row, Float642Str(sample.Value))
-					splits := strings.Split(replicaHeaders[i], "-")
-					replicaHeaders[i] = fmt.Sprintf("%v-%v", splits[0]+"-"+splits[1], sample.Timestamp)
-				} else {
-					row = append(row, "null")
-				}
-			}
-			data = append(data, row)
-		}
+// WorkloadTsDataInit loads the workload time series from the checkpoint, or fetches them from the datasource like ContainerTsDataInit
+func (c *Comparator) WorkloadTsDataInit(ctx context.Context) error {
+	window := c.getQueryRange()
+	cached, cachedRange, partial, err := c.loadWorkloadTimeSeriesDataFromCheckpoint()
+	if err == nil && !partial && (!c.config.CheckpointIncremental || !cachedRange.End.Before(window.End)) {
+		c.workloadsTimeSeriesDataCache = cached
+		c.workloadsDataRange = cachedRange
+		return nil
 	}
-
-	headers = append(headers, cpuUsageHeaders...)
-	headers = append(headers, memUsageHeaders...)
-	headers = append(headers, cpuRequestHeaders...)
-	headers = append(headers, memRequestHeaders...)
-	headers = append(headers, cpuLimitHeaders...)
-	headers = append(headers, memLimitHeaders...)
-	headers = append(headers, replicaHeaders...)
-
-	csvFile, err := os.Create(c.workloadTimeSeriesDataCheckpointName())
+	dataRange := window
 	if err != nil {
-		return err
+		klog.Errorf("Failed to load workload time series data from check point: %v, init from datasource", err)
+		cached = nil
+	} else {
+		if partial {
+			klog.Infof("Resume the partial workload time series data checkpoint from datasource")
+		} else {
+			klog.Infof("Refresh workload time series data of [%v, %v] from datasource", cachedRange.End, window.End)
+		}
+		dataRange.Start = cachedRange.Start
 	}
-	csvW := csv.NewWriter(csvFile)
-	csvW.Comma = '\t'
-	err = csvW.Write(headers)
-	if err != nil {
-		return err
+	data, complete := c.fetchWorkloadMetricData(ctx, cached)
+	c.workloadsTimeSeriesDataCache = data
+	c.workloadsDataRange = dataRange
+	c.workloadsDataPartial = !complete
+	if !complete {
+		klog.Warningf("Workload time series data is partial, the checkpoint of it is resumed by the next run")
+	} else {
+		klog.V(2).Infof("Succeed to load workload time series data from data source")
 	}
-	err = csvW.WriteAll(data)
-	if err != nil {
-		return err
+	if c.config.EnableWorkloadCheckpoint {
+		return c.workloadTimeSeriesDataCheckpoint()
 	}
 	return nil
 }
 
-func (c *Comparator) fetchWorkloadMetricData() map[string]map[types.NamespacedName]*RawWorkloadTimeSeriesData {
+// fetchWorkloadMetricData fetches the workloads time series concurrently, it returns false if the result is partial.
+// The cached data of a workload is kept, it is fetched since the end of its range and merged if the checkpoint is refreshed incrementally.
+// The workloads not in cached are fetched in the window, the cached data is kept if the fetching fails.
+func (c *Comparator) fetchWorkloadMetricData(ctx context.Context, cached map[string]map[types.NamespacedName]*RawWorkloadTimeSeriesData) (map[string]map[types.NamespacedName]*RawWorkloadTimeSeriesData, bool) {
 	results := make(map[string]map[types.NamespacedName]*RawWorkloadTimeSeriesData)
 	workloads := c.workloadsSpecCache
 	qRange := c.getQueryRange()
+	var tasks []fetchTask
 	for kind := range workloads {
 		if kindWorkloads, ok := workloads[kind]; ok {
 			kindResult, ok := results[kind]
@@ -1202,6 +560,7 @@ func (c *Comparator) fetchWorkloadMetricData() map[string]map[types.NamespacedNa
 				results[kind] = kindResult
 			}
 			for nn, workload := range kindWorkloads {
+				nn := nn
 				target := &v1.ObjectReference{
 					Kind:       workload.Workload.GetKind(),
 					Namespace:  workload.Workload.GetNamespace(),
@@ -1217,61 +576,58 @@ func (c *Comparator) fetchWorkloadMetricData() map[string]map[types.NamespacedNa
 				workloadMemLims := metricnaming.WorkloadMetricNamer(c.config.ClusterId, target, consts.MetricMemLimit, labels.Everything())
 				workloadReplicas := metricnaming.WorkloadMetricNamer(c.config.ClusterId, target, consts.MetricWorkloadReplicas, labels.Everything())
 
-				wkCpuUsageTs, err := c.dataSource.QueryTimeSeries(context.TODO(), workloadCpuUsage, qRange.Start, qRange.End, qRange.Step)
-				if err != nil {
-					klog.Errorf("Failed to query history for metric %v: %v", workloadCpuUsage.BuildUniqueKey(), err)
-					continue
-				}
-				wkMemUsageTs, err := c.dataSource.QueryTimeSeries(context.TODO(), workloadMemUsage, qRange.Start, qRange.End, qRange.Step)
-				if err != nil {
-					klog.Errorf("Failed to query history for metric %v: %v", workloadMemUsage.BuildUniqueKey(), err)
-					continue
-				}
-				wkCpuReqTs, err := c.dataSource.QueryTimeSeries(context.TODO(), workloadCpuReqs, qRange.Start, qRange.End, qRange.Step)
-				if err != nil {
-					klog.Errorf("Failed to query history for metric %v: %v", workloadCpuReqs.BuildUniqueKey(), err)
-					continue
-				}
-				wkCpuLimTs, err := c.dataSource.QueryTimeSeries(context.TODO(), workloadCpuLims, qRange.Start, qRange.End, qRange.Step)
-				if err != nil {
-					klog.Errorf("Failed to query history for metric %v: %v", workloadCpuLims.BuildUniqueKey(), err)
-					continue
-				}
-				wkMemReqTs, err := c.dataSource.QueryTimeSeries(context.TODO(), workloadMemReqs, qRange.Start, qRange.End, qRange.Step)
-				if err != nil {
-					klog.Errorf("Failed to query history for metric %v: %v", workloadMemReqs.BuildUniqueKey(), err)
-					continue
-				}
-				wkMemLimTs, err := c.dataSource.QueryTimeSeries(context.TODO(), workloadMemLims, qRange.Start, qRange.End, qRange.Step)
-				if err != nil {
-					klog.Errorf("Failed to query history for metric %v: %v", workloadMemLims.BuildUniqueKey(), err)
-					continue
-				}
-				wkReplicasTs, err := c.dataSource.QueryTimeSeries(context.TODO(), workloadReplicas, qRange.Start, qRange.End, qRange.Step)
-				if err != nil {
-					klog.Errorf("Failed to query history for metric %v: %v", workloadReplicas.BuildUniqueKey(), err)
-					continue
-				}
-
-				kindResult[nn] = &RawWorkloadTimeSeriesData{
-					Cpu:         wkCpuUsageTs,
-					Mem:         wkMemUsageTs,
-					CpuRequests: wkCpuReqTs,
-					MemRequests: wkMemReqTs,
-					CpuLimits:   wkCpuLimTs,
-					MemLimits:   wkMemLimTs,
-					Replicas:    wkReplicasTs,
-				}
+				taskRange := qRange
+				cachedData := cached[kind][nn]
+				if cachedData != nil {
+					kindResult[nn] = cachedData
+					if !c.config.CheckpointIncremental || !cachedData.Range.End.Before(qRange.End) {
+						continue
+					}
+					taskRange.Start = cachedData.Range.End
+				}
+				tasks = append(tasks, fetchTask{
+					key:     kind + "/" + nn.String(),
+					qRange:  taskRange,
+					queries: []metricnaming.MetricNamer{workloadCpuUsage, workloadMemUsage, workloadCpuReqs, workloadCpuLims, workloadMemReqs, workloadMemLims, workloadReplicas},
+					assign: func(ts [][]*common.TimeSeries) {
+						data := &RawWorkloadTimeSeriesData{
+							Cpu:         ts[0],
+							Mem:         ts[1],
+							CpuRequests: ts[2],
+							CpuLimits:   ts[3],
+							MemRequests: ts[4],
+							MemLimits:   ts[5],
+							Replicas:    ts[6],
+							Range:       taskRange,
+						}
+						if cachedData != nil {
+							data = &RawWorkloadTimeSeriesData{
+								Cpu:         mergeTimeSeries(cachedData.Cpu, data.Cpu),
+								Mem:         mergeTimeSeries(cachedData.Mem, data.Mem),
+								CpuRequests: mergeTimeSeries(cachedData.CpuRequests, data.CpuRequests),
+								CpuLimits:   mergeTimeSeries(cachedData.CpuLimits, data.CpuLimits),
+								MemRequests: mergeTimeSeries(cachedData.MemRequests, data.MemRequests),
+								MemLimits:   mergeTimeSeries(cachedData.MemLimits, data.MemLimits),
+								Replicas:    mergeTimeSeries(cachedData.Replicas, data.Replicas),
+								Range:       promapiv1.Range{Start: cachedData.Range.Start, End: taskRange.End, Step: taskRange.Step},
+							}
+						}
+						kindResult[nn] = data
+					},
+				})
 			}
 		}
 	}
-	return results
+	complete := c.runFetchTasks(ctx, "workload time series", tasks)
+	return results, complete
 }
 
-func (c *Comparator) fetchContainerData() map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData {
+// fetchContainerData fetches the containers time series concurrently like fetchWorkloadMetricData, it returns false if the result is partial.
+func (c *Comparator) fetchContainerData(ctx context.Context, cached map[string]map[types.NamespacedName]map[string]*RawContainerTimeSeriesData) (map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData, bool) {
 	results := make(map[string]map[types.NamespacedName]map[string]*RawContainerTimeSeriesData)
 	workloads := c.workloadsSpecCache
 	qRange := c.getQueryRange()
+	var tasks []fetchTask
 	for kind := range workloads {
 		if kindWorkloads, ok := workloads[kind]; ok {
 			kindResult, ok := results[kind]
@@ -1286,6 +642,7 @@ func (c *Comparator) fetchContainerData() map[string] /*kind*/ map[types.Namespa
 					kindResult[nn] = workloadResult
 				}
 				for _, container := range workload.PodRef.Spec.Containers {
+					containerName := container.Name
 					cpu := metricnaming.ResourceToContainerMetricNamer(c.config.ClusterId, nn.Namespace, nn.Name, container.Name, v1.ResourceCPU)
 					mem := metricnaming.ResourceToContainerMetricNamer(c.config.ClusterId, nn.Namespace, nn.Name, container.Name, v1.ResourceMemory)
 					cpuRequest := metricnaming.ContainerMetricNamer(c.config.ClusterId, kind, nn.Namespace, nn.Name, container.Name, consts.MetricCpuRequest, labels.Everything())
@@ -1293,50 +650,49 @@ func (c *Comparator) fetchContainerData() map[string] /*kind*/ map[types.Namespa
 					cpuLimit := metricnaming.ContainerMetricNamer(c.config.ClusterId, kind, nn.Namespace, nn.Name, container.Name, consts.MetricCpuLimit, labels.Everything())
 					memLimit := metricnaming.ContainerMetricNamer(c.config.ClusterId, kind, nn.Namespace, nn.Name, container.Name, consts.MetricMemLimit, labels.Everything())
 
-					cpuTsList, err := c.dataSource.QueryTimeSeries(context.TODO(), cpu, qRange.Start, qRange.End, qRange.Step)
-					if err != nil {
-						klog.Errorf("Failed to query history for metric %v: %v", cpu.BuildUniqueKey(), err)
-						continue
-					}
-					memTsList, err := c.dataSource.QueryTimeSeries(context.TODO(), mem, qRange.Start, qRange.End, qRange.Step)
-					if err != nil {
-						klog.Errorf("Failed to query history for metric %v: %v", mem.BuildUniqueKey(), err)
-						continue
-					}
-					cpuReqTsList, err := c.dataSource.QueryTimeSeries(context.TODO(), cpuRequest, qRange.Start, qRange.End, qRange.Step)
-					if err != nil {
-						klog.Errorf("Failed to query history for metric %v: %v", cpuRequest.BuildUniqueKey(), err)
-						continue
-					}
-					memReqTsList, err := c.dataSource.QueryTimeSeries(context.TODO(), memRequest, qRange.Start, qRange.End, qRange.Step)
-					if err != nil {
-						klog.Errorf("Failed to query history for metric %v: %v", memRequest.BuildUniqueKey(), err)
-						continue
-					}
-					cpuLimTsList, err := c.dataSource.QueryTimeSeries(context.TODO(), cpuLimit, qRange.Start, qRange.End, qRange.Step)
-					if err != nil {
-						klog.Errorf("Failed to query history for metric %v: %v", cpuLimit.BuildUniqueKey(), err)
-						continue
-					}
-					memLimTsList, err := c.dataSource.QueryTimeSeries(context.TODO(), memLimit, qRange.Start, qRange.End, qRange.Step)
-					if err != nil {
-						klog.Errorf("Failed to query history for metric %v: %v", memLimit.BuildUniqueKey(), err)
-						continue
-					}
-					workloadResult[container.Name] = &RawContainerTimeSeriesData{
-						Cpu:         cpuTsList,
-						Mem:         memTsList,
-						CpuRequests: cpuReqTsList,
-						MemRequests: memReqTsList,
-						CpuLimits:   cpuLimTsList,
-						MemLimits:   memLimTsList,
+					taskRange := qRange
+					cachedData := cached[kind][nn][containerName]
+					if cachedData != nil {
+						workloadResult[containerName] = cachedData
+						if !c.config.CheckpointIncremental || !cachedData.Range.End.Before(qRange.End) {
+							continue
+						}
+						taskRange.Start = cachedData.Range.End
 					}
-
+					tasks = append(tasks, fetchTask{
+						key:     kind + "/" + nn.String() + "/" + containerName,
+						qRange:  taskRange,
+						queries: []metricnaming.MetricNamer{cpu, mem, cpuRequest, memRequest, cpuLimit, memLimit},
+						assign: func(ts [][]*common.TimeSeries) {
+							data := &RawContainerTimeSeriesData{
+								Cpu:         ts[0],
+								Mem:         ts[1],
+								CpuRequests: ts[2],
+								MemRequests: ts[3],
+								CpuLimits:   ts[4],
+								MemLimits:   ts[5],
+								Range:       taskRange,
+							}
+							if cachedData != nil {
+								data = &RawContainerTimeSeriesData{
+									Cpu:         mergeTimeSeries(cachedData.Cpu, data.Cpu),
+									Mem:         mergeTimeSeries(cachedData.Mem, data.Mem),
+									CpuRequests: mergeTimeSeries(cachedData.CpuRequests, data.CpuRequests),
+									MemRequests: mergeTimeSeries(cachedData.MemRequests, data.MemRequests),
+									CpuLimits:   mergeTimeSeries(cachedData.CpuLimits, data.CpuLimits),
+									MemLimits:   mergeTimeSeries(cachedData.MemLimits, data.MemLimits),
+									Range:       promapiv1.Range{Start: cachedData.Range.Start, End: taskRange.End, Step: taskRange.Step},
+								}
+							}
+							workloadResult[containerName] = data
+						},
+					})
 				}
 			}
 		}
 	}
-	return results
+	complete := c.runFetchTasks(ctx, "container time series", tasks)
+	return results, complete
 }
 
 func (c *Comparator) GetWorkloadContainerData() map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData {
@@ -1346,6 +702,11 @@ func (c *Comparator) GetWorkloadContainerData() map[string] /*kind*/ map[types.N
 // NOTE: memory & time consuming, now it is a tool for offline analytics
 // todo: For online predicting service, we should use a model updating way not the offline once task way
 func (c *Comparator) GetAllWorkloadRecommendedData() map[string]map[types.NamespacedName] /*namespace-name*/ *spec.WorkloadRecommendedData {
+	return c.getAllWorkloadRecommendedData(nil)
+}
+
+// getAllWorkloadRecommendedData estimates the workloads, estimateOverrides overrides the estimate config of all the workloads and resources
+func (c *Comparator) getAllWorkloadRecommendedData(estimateOverrides map[string]interface{}) map[string]map[types.NamespacedName] /*namespace-name*/ *spec.WorkloadRecommendedData {
 	results := make(map[string]map[types.NamespacedName]*spec.WorkloadRecommendedData)
 	workloads := c.workloadsSpecCache
 	workloadsContainerData := c.GetWorkloadContainerData()
@@ -1362,6 +723,18 @@ func (c *Comparator) GetAllWorkloadRecommendedData() map[string]map[types.Namesp
 				Containers: make(map[string]*spec.ContainerRecommendedData),
 			}
 
+			profile := c.workloadEstimatorProfile(nn, workloadPodSpec)
+			if profile != nil {
+				klog.V(6).Infof("Estimate kind %v, workload %v by profile %v", kind, nn, profile.Name)
+			}
+			workloadEstimator := c.profileEstimator(profile)
+			cpuEstimateConfig := c.resourceEstimateConfig(profile, estimator.ResourceCpu)
+			memEstimateConfig := c.resourceEstimateConfig(profile, estimator.ResourceMemory)
+			for k, v := range estimateOverrides {
+				cpuEstimateConfig[k] = v
+				memEstimateConfig[k] = v
+			}
+
 			recPod := workloadPodSpec.PodRef.DeepCopy()
 			pertRecPod := workloadPodSpec.PodRef.DeepCopy()
 			maxRecPod := workloadPodSpec.PodRef.DeepCopy()
@@ -1384,13 +757,13 @@ func (c *Comparator) GetAllWorkloadRecommendedData() map[string]map[types.Namesp
 					continue
 				}
 				cpuTs := MergeTimeSeriesList(rawTsData.Cpu)
-				cpuStatistics, err := c.estimator.Estimation(cpuTs, c.estimateConfig)
+				cpuStatistics, err := workloadEstimator.Estimation(cpuTs, cpuEstimateConfig)
 				if err != nil {
 					klog.Errorf("Failed to estimate cpu for kind %v, workload %v, container %v, err: %v", kind, nn, container.Name, err)
 					continue
 				}
 				memTs := MergeTimeSeriesList(rawTsData.Mem)
-				memStatistics, err := c.estimator.Estimation(memTs, c.estimateConfig)
+				memStatistics, err := workloadEstimator.Estimation(memTs, memEstimateConfig)
 				if err != nil {
 					klog.Errorf("Failed to estimate mem for kind %v, workload %v, container %v, err: %v", kind, nn, container.Name, err)
 					continue
@@ -0,0 +1,183 @@
+package cost_comparator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/fadvisor/pkg/consts"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/coster"
+	"github.com/gocrane/fadvisor/pkg/metricnaming"
+)
+
+// InitBatchWorkloads lists the jobs not created by cronjobs and the cronjobs, and fetches the run time of their completed pods in the history window.
+// It returns false if the run time of some workloads are not fetched
+func (c *Comparator) InitBatchWorkloads(ctx context.Context) ([]coster.BatchWorkload, bool) {
+	qRange := c.getQueryRange()
+	var workloads []coster.BatchWorkload
+
+	jobs, err := c.listBatchObjects(ctx, "Job")
+	if err != nil {
+		klog.Errorf("Failed to list jobs: %v", err)
+	}
+	for _, obj := range jobs {
+		job := &batchv1.Job{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, job); err != nil {
+			klog.V(4).Infof("Failed to convert job %v: %v", klog.KObj(obj), err)
+			continue
+		}
+		if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+			continue
+		}
+		if !c.batchInScope("Job", job.Namespace, job.Spec.Template.Labels) {
+			continue
+		}
+		workloads = append(workloads, c.batchWorkload("Job", job.Namespace, job.Name, "", job.Spec.Template, -1))
+	}
+
+	cronJobs, err := c.listBatchObjects(ctx, "CronJob")
+	if err != nil {
+		klog.Errorf("Failed to list cronjobs: %v", err)
+	}
+	for _, obj := range cronJobs {
+		// the jobTemplate of batch/v1beta1 is the same as batch/v1
+		cronJob := &batchv1.CronJob{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, cronJob); err != nil {
+			klog.V(4).Infof("Failed to convert cronjob %v: %v", klog.KObj(obj), err)
+			continue
+		}
+		if !c.batchInScope("CronJob", cronJob.Namespace, cronJob.Spec.JobTemplate.Spec.Template.Labels) {
+			continue
+		}
+		monthlyRuns := 0.
+		if cronJob.Spec.Suspend == nil || !*cronJob.Spec.Suspend {
+			schedule, err := cron.ParseStandard(cronJob.Spec.Schedule)
+			if err != nil {
+				klog.Warningf("Failed to parse schedule of cronjob %v, project the runs of the history: %v", klog.KObj(cronJob), err)
+				monthlyRuns = -1
+			} else {
+				monthlyRuns = float64(scheduleRuns(schedule, qRange.End, qRange.End.Add(coster.BatchMonth)))
+			}
+		}
+		workloads = append(workloads, c.batchWorkload("CronJob", cronJob.Namespace, cronJob.Name, cronJob.Spec.Schedule, cronJob.Spec.JobTemplate.Spec.Template, monthlyRuns))
+	}
+
+	var tasks []fetchTask
+	for i := range workloads {
+		workload := &workloads[i]
+		target := &v1.ObjectReference{Kind: workload.Kind, Namespace: workload.NamespacedName.Namespace, Name: workload.NamespacedName.Name, APIVersion: "batch/v1"}
+		tasks = append(tasks, fetchTask{
+			key:     workload.Kind + "/" + workload.NamespacedName.String(),
+			qRange:  qRange,
+			queries: []metricnaming.MetricNamer{metricnaming.WorkloadMetricNamer(c.config.ClusterId, target, consts.MetricWorkloadPodRuntime, labels.Everything())},
+			assign: func(ts [][]*common.TimeSeries) {
+				workload.PodSeconds, workload.Runs = podsRuntime(ts[0])
+			},
+		})
+	}
+	complete := c.runFetchTasks(ctx, "batch workloads pod runtime", tasks)
+	return workloads, complete
+}
+
+// scheduleRuns returns the number of runs of the schedule in [start, end)
+func scheduleRuns(schedule cron.Schedule, start, end time.Time) int {
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		return int(end.Sub(start) / every.Delay)
+	}
+	runs := 0
+	// Next returns the time after the second of t, so the run at start is counted
+	for t := schedule.Next(start.Add(-time.Second)); !t.IsZero() && t.Before(end); t = schedule.Next(t) {
+		runs++
+	}
+	return runs
+}
+
+// batchInScope returns true if the batch workload is in the scope, the label selectors match the labels of its pods
+func (c *Comparator) batchInScope(kind, namespace string, podLabels map[string]string) bool {
+	scope := c.config.Scope
+	return scope.KindInScope(kind) && scope.NamespaceInScope(namespace) && scope.LabelsInScope(podLabels)
+}
+
+// listBatchObjects lists the objects of the kind in batch group by the preferred version the cluster serves
+func (c *Comparator) listBatchObjects(ctx context.Context, kind string) ([]*unstructured.Unstructured, error) {
+	mappings, err := c.restMapper.RESTMappings(schema.GroupKind{Group: batchv1.GroupName, Kind: kind})
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, mapping := range mappings {
+		list, err := c.kubeDynamicClient.Resource(mapping.Resource).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		objs := make([]*unstructured.Unstructured, 0, len(list.Items))
+		for i := range list.Items {
+			objs = append(objs, &list.Items[i])
+		}
+		return objs, nil
+	}
+	return nil, fmt.Errorf("%v", errs)
+}
+
+func (c *Comparator) batchWorkload(kind, namespace, name, schedule string, template v1.PodTemplateSpec, monthlyRuns float64) coster.BatchWorkload {
+	pod := &v1.Pod{ObjectMeta: template.ObjectMeta, Spec: template.Spec}
+	pod.Namespace = namespace
+	pod.Name = name
+	podSpec := c.baselineCloud.Pod2Spec(pod)
+	podSpec.GoodsNum = 1
+	serverlessPodSpec := c.baselineCloud.Pod2ServerlessSpec(pod)
+	serverlessPodSpec.GoodsNum = 1
+	return coster.BatchWorkload{
+		Kind:              kind,
+		NamespacedName:    types.NamespacedName{Namespace: namespace, Name: name},
+		Schedule:          schedule,
+		PodSpec:           podSpec,
+		ServerlessPodSpec: serverlessPodSpec,
+		WindowSeconds:     c.config.History.Length.Seconds(),
+		MonthlyRuns:       monthlyRuns,
+	}
+}
+
+// podsRuntime returns the run seconds of the pods and the number of the jobs they belong to. A series is the run time of a pod,
+// labeled by the pod and the job, the max sample is used because the series of a pod can be split by the restart of kube-state-metrics
+func podsRuntime(series []*common.TimeSeries) (float64, int) {
+	podSeconds := make(map[string]float64)
+	jobs := make(map[string]bool)
+	for i, ts := range series {
+		if ts == nil {
+			continue
+		}
+		pod := fmt.Sprintf("#%d", i)
+		for _, label := range ts.Labels {
+			switch label.Name {
+			case "pod":
+				pod = label.Value
+			case "owner_name":
+				jobs[label.Value] = true
+			}
+		}
+		for _, sample := range ts.Samples {
+			if sample.Value > podSeconds[pod] {
+				podSeconds[pod] = sample.Value
+			}
+		}
+	}
+	total := 0.
+	for _, seconds := range podSeconds {
+		total += seconds
+	}
+	return total, len(jobs)
+}
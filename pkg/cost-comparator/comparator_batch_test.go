@@ -0,0 +1,62 @@
+package cost_comparator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+func TestPodsRuntime(t *testing.T) {
+	newSeries := func(job, pod string, seconds ...float64) *common.TimeSeries {
+		ts := common.NewTimeSeries()
+		ts.SetLabels([]common.Label{{Name: "namespace", Value: "default"}, {Name: "pod", Value: pod}, {Name: "owner_name", Value: job}})
+		for i, v := range seconds {
+			ts.AppendSample(int64(i*60), v)
+		}
+		return ts
+	}
+	series := []*common.TimeSeries{
+		newSeries("backup-27600000", "backup-27600000-a", 600, 600),
+		// a run of 2 pods, the series of a pod is split
+		newSeries("backup-27600060", "backup-27600060-a", 300),
+		newSeries("backup-27600060", "backup-27600060-b", 100),
+		newSeries("backup-27600060", "backup-27600060-b", 100, 120),
+	}
+	podSeconds, runs := podsRuntime(series)
+	if podSeconds != 1020 || runs != 2 {
+		t.Errorf("expect 1020 pod seconds of 2 runs, got %v seconds of %v runs", podSeconds, runs)
+	}
+}
+
+func TestScheduleRuns(t *testing.T) {
+	// 2022-08-01 is a monday
+	start := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(30 * 24 * time.Hour)
+	testCases := []struct {
+		schedule string
+		runs     int
+	}{
+		{schedule: "CRON_TZ=UTC */15 * * * *", runs: 30 * 24 * 4},
+		{schedule: "CRON_TZ=UTC 0 2 * * *", runs: 30},
+		{schedule: "@hourly", runs: 30 * 24},
+		{schedule: "CRON_TZ=UTC 30 1 * * mon-fri", runs: 22},
+		{schedule: "CRON_TZ=UTC 0 0 1,15 * *", runs: 2},
+		// the day of month or the day of week
+		{schedule: "CRON_TZ=UTC 0 0 1 * sun", runs: 5},
+		{schedule: "CRON_TZ=UTC 0 0 1 jan *", runs: 0},
+		{schedule: "@every 90m", runs: 480},
+		{schedule: "@every 1s", runs: 30 * 24 * 3600},
+	}
+	for _, tc := range testCases {
+		schedule, err := cron.ParseStandard(tc.schedule)
+		if err != nil {
+			t.Fatalf("failed to parse %v: %v", tc.schedule, err)
+		}
+		if runs := scheduleRuns(schedule, start, end); runs != tc.runs {
+			t.Errorf("expect %v runs of %v, got %v", tc.runs, tc.schedule, runs)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package cost_comparator
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/checkpoint"
+	"github.com/gocrane/fadvisor/pkg/datasource-providers/prom"
+)
+
+// metric names of the checkpoint series
+const (
+	checkpointCpu        = "cpu"
+	checkpointMem        = "mem"
+	checkpointCpuRequest = "cpuRequest"
+	checkpointMemRequest = "memRequest"
+	checkpointCpuLimit   = "cpuLimit"
+	checkpointMemLimit   = "memLimit"
+	checkpointReplicas   = "replicas"
+)
+
+func (c *Comparator) containerTimeSeriesDataCheckpointName() string {
+	return filepath.Join(c.config.DataPath, c.config.ClusterId+"-workloads-container-timeseries.ckpt")
+}
+
+func (c *Comparator) workloadTimeSeriesDataCheckpointName() string {
+	return filepath.Join(c.config.DataPath, c.config.ClusterId+"-workloads-timeseries.ckpt")
+}
+
+// checkpointMetadata returns the metadata of the checkpoint, dataRange is the range the data covers, the current window is used if it is empty
+func (c *Comparator) checkpointMetadata(checkpointType string, dataRange promapiv1.Range) checkpoint.Metadata {
+	qRange := c.getQueryRange()
+	if !dataRange.Start.IsZero() && !dataRange.End.IsZero() {
+		qRange = dataRange
+	}
+	return checkpoint.Metadata{
+		Type:       checkpointType,
+		ClusterId:  c.config.ClusterId,
+		DataSource: c.config.DataSource,
+		Start:      qRange.Start,
+		End:        qRange.End,
+		Step:       qRange.Step,
+	}
+}
+
+// openCheckpoint opens the checkpoint and checks it is reusable by the current run, it return the window of the current run the checkpoint covers
+func (c *Comparator) openCheckpoint(path string, checkpointType string) (*checkpoint.Reader, time.Time, time.Time, error) {
+	reader, err := checkpoint.Open(path)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+	meta := c.checkpointMetadata(checkpointType, promapiv1.Range{})
+	minCoverage := c.config.CheckpointMinCoverage
+	if c.config.CheckpointIncremental {
+		// the missing range is refreshed from the datasource, so any overlapped checkpoint is reusable
+		minCoverage = 0
+	}
+	start, end, err := checkpoint.Check(reader.Metadata, checkpoint.Expectation{
+		Type:        meta.Type,
+		ClusterId:   meta.ClusterId,
+		DataSource:  meta.DataSource,
+		Start:       meta.Start,
+		End:         meta.End,
+		Step:        meta.Step,
+		MinCoverage: minCoverage,
+	})
+	if err != nil {
+		reader.Close()
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("refuse checkpoint %v: %v", path, err)
+	}
+	if c.config.CheckpointIncremental && end.Before(meta.End) {
+		klog.Infof("Checkpoint %v covers [%v, %v] of the window [%v, %v], refresh the missing range", path, start, end, meta.Start, meta.End)
+	} else if start.After(meta.Start) || end.Before(meta.End) {
+		klog.Warningf("Checkpoint %v covers [%v, %v] of the window [%v, %v], reuse it partially", path, start, end, meta.Start, meta.End)
+	}
+	return reader, start, end, nil
+}
+
+// LoadContainerTimeSeriesDataFromCheckpoint loads the container time series of the workloads in the cluster, the samples out of the window are dropped
+func (c *Comparator) LoadContainerTimeSeriesDataFromCheckpoint() (map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData, error) {
+	result, _, _, err := c.loadContainerTimeSeriesDataFromCheckpoint()
+	return result, err
+}
+
+// loadContainerTimeSeriesDataFromCheckpoint loads the container time series and returns the range of the window the data covers, and whether the checkpoint is partial
+func (c *Comparator) loadContainerTimeSeriesDataFromCheckpoint() (map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData, promapiv1.Range, bool, error) {
+	reader, start, end, err := c.openCheckpoint(c.containerTimeSeriesDataCheckpointName(), checkpoint.TypeContainer)
+	if err != nil {
+		return nil, promapiv1.Range{}, false, err
+	}
+	defer reader.Close()
+
+	result := make(map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ map[string] /*container*/ *RawContainerTimeSeriesData)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, promapiv1.Range{}, false, fmt.Errorf("failed to read checkpoint %v: %v", c.containerTimeSeriesDataCheckpointName(), err)
+		}
+		nn := types.NamespacedName{Namespace: record.Namespace, Name: record.Name}
+		// the workloads deleted from the cluster are not analyzed
+		if _, ok := c.workloadsSpecCache[record.Kind][nn]; !ok {
+			continue
+		}
+		recordRange, ok := checkpointRecordRange(record, start, end, reader.Metadata.Step)
+		if !ok {
+			continue
+		}
+		kindNN, ok := result[record.Kind]
+		if !ok {
+			kindNN = make(map[types.NamespacedName]map[string]*RawContainerTimeSeriesData)
+			result[record.Kind] = kindNN
+		}
+		containersMap, ok := kindNN[nn]
+		if !ok {
+			containersMap = make(map[string]*RawContainerTimeSeriesData)
+			kindNN[nn] = containersMap
+		}
+		containersMap[record.Container] = &RawContainerTimeSeriesData{
+			Cpu:         checkpoint.TimeSeries(record.Series[checkpointCpu], recordRange.Start, recordRange.End),
+			Mem:         checkpoint.TimeSeries(record.Series[checkpointMem], recordRange.Start, recordRange.End),
+			CpuRequests: checkpoint.TimeSeries(record.Series[checkpointCpuRequest], recordRange.Start, recordRange.End),
+			MemRequests: checkpoint.TimeSeries(record.Series[checkpointMemRequest], recordRange.Start, recordRange.End),
+			CpuLimits:   checkpoint.TimeSeries(record.Series[checkpointCpuLimit], recordRange.Start, recordRange.End),
+			MemLimits:   checkpoint.TimeSeries(record.Series[checkpointMemLimit], recordRange.Start, recordRange.End),
+			Range:       recordRange,
+		}
+	}
+	return result, promapiv1.Range{Start: start, End: end, Step: reader.Metadata.Step}, reader.Metadata.Partial, nil
+}
+
+// LoadWorkloadTimeSeriesDataFromCheckpoint loads the workload time series of the workloads in the cluster, the samples out of the window are dropped
+func (c *Comparator) LoadWorkloadTimeSeriesDataFromCheckpoint() (map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ *RawWorkloadTimeSeriesData, error) {
+	result, _, _, err := c.loadWorkloadTimeSeriesDataFromCheckpoint()
+	return result, err
+}
+
+// loadWorkloadTimeSeriesDataFromCheckpoint loads the workload time series and returns the range of the window the data covers, and whether the checkpoint is partial
+func (c *Comparator) loadWorkloadTimeSeriesDataFromCheckpoint() (map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ *RawWorkloadTimeSeriesData, promapiv1.Range, bool, error) {
+	reader, start, end, err := c.openCheckpoint(c.workloadTimeSeriesDataCheckpointName(), checkpoint.TypeWorkload)
+	if err != nil {
+		return nil, promapiv1.Range{}, false, err
+	}
+	defer reader.Close()
+
+	result := make(map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ *RawWorkloadTimeSeriesData)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, promapiv1.Range{}, false, fmt.Errorf("failed to read checkpoint %v: %v", c.workloadTimeSeriesDataCheckpointName(), err)
+		}
+		nn := types.NamespacedName{Namespace: record.Namespace, Name: record.Name}
+		if _, ok := c.workloadsSpecCache[record.Kind][nn]; !ok {
+			continue
+		}
+		recordRange, ok := checkpointRecordRange(record, start, end, reader.Metadata.Step)
+		if !ok {
+			continue
+		}
+		kindNN, ok := result[record.Kind]
+		if !ok {
+			kindNN = make(map[types.NamespacedName]*RawWorkloadTimeSeriesData)
+			result[record.Kind] = kindNN
+		}
+		kindNN[nn] = &RawWorkloadTimeSeriesData{
+			Cpu:         checkpoint.TimeSeries(record.Series[checkpointCpu], recordRange.Start, recordRange.End),
+			Mem:         checkpoint.TimeSeries(record.Series[checkpointMem], recordRange.Start, recordRange.End),
+			CpuRequests: checkpoint.TimeSeries(record.Series[checkpointCpuRequest], recordRange.Start, recordRange.End),
+			MemRequests: checkpoint.TimeSeries(record.Series[checkpointMemRequest], recordRange.Start, recordRange.End),
+			CpuLimits:   checkpoint.TimeSeries(record.Series[checkpointCpuLimit], recordRange.Start, recordRange.End),
+			MemLimits:   checkpoint.TimeSeries(record.Series[checkpointMemLimit], recordRange.Start, recordRange.End),
+			Replicas:    checkpoint.TimeSeries(record.Series[checkpointReplicas], recordRange.Start, recordRange.End),
+			Range:       recordRange,
+		}
+	}
+	return result, promapiv1.Range{Start: start, End: end, Step: reader.Metadata.Step}, reader.Metadata.Partial, nil
+}
+
+// checkpointRecordRange return the range the record covers in [start, end], false if they do not overlap
+func checkpointRecordRange(record *checkpoint.Record, start, end time.Time, step time.Duration) (promapiv1.Range, bool) {
+	if !record.Start.IsZero() && record.Start.After(start) {
+		start = record.Start
+	}
+	if !record.End.IsZero() && record.End.Before(end) {
+		end = record.End
+	}
+	return promapiv1.Range{Start: start, End: end, Step: step}, end.After(start)
+}
+
+func (c *Comparator) ContainerDataCheckpoint() error {
+	meta := c.checkpointMetadata(checkpoint.TypeContainer, c.containersDataRange)
+	meta.Partial = c.containersDataPartial
+	writer, err := checkpoint.NewWriter(c.containerTimeSeriesDataCheckpointName(), meta)
+	if err != nil {
+		return err
+	}
+	for kind, kindData := range c.containersTimeSeriesDataCache {
+		for nn, containers := range kindData {
+			for container, data := range containers {
+				if data == nil {
+					continue
+				}
+				err := writer.Write(&checkpoint.Record{
+					Kind:      kind,
+					Namespace: nn.Namespace,
+					Name:      nn.Name,
+					Container: container,
+					Series: map[string][]checkpoint.Series{
+						checkpointCpu:        checkpoint.NewSeries(data.Cpu),
+						checkpointMem:        checkpoint.NewSeries(data.Mem),
+						checkpointCpuRequest: checkpoint.NewSeries(data.CpuRequests),
+						checkpointMemRequest: checkpoint.NewSeries(data.MemRequests),
+						checkpointCpuLimit:   checkpoint.NewSeries(data.CpuLimits),
+						checkpointMemLimit:   checkpoint.NewSeries(data.MemLimits),
+					},
+					Start: data.Range.Start,
+					End:   data.Range.End,
+				})
+				if err != nil {
+					writer.Abort()
+					return err
+				}
+			}
+		}
+	}
+	return writer.Close()
+}
+
+func (c *Comparator) workloadTimeSeriesDataCheckpoint() error {
+	meta := c.checkpointMetadata(checkpoint.TypeWorkload, c.workloadsDataRange)
+	meta.Partial = c.workloadsDataPartial
+	writer, err := checkpoint.NewWriter(c.workloadTimeSeriesDataCheckpointName(), meta)
+	if err != nil {
+		return err
+	}
+	for kind, kindData := range c.workloadsTimeSeriesDataCache {
+		for nn, data := range kindData {
+			if data == nil {
+				continue
+			}
+			err := writer.Write(&checkpoint.Record{
+				Kind:      kind,
+				Namespace: nn.Namespace,
+				Name:      nn.Name,
+				Series: map[string][]checkpoint.Series{
+					checkpointCpu:        checkpoint.NewSeries(data.Cpu),
+					checkpointMem:        checkpoint.NewSeries(data.Mem),
+					checkpointCpuRequest: checkpoint.NewSeries(data.CpuRequests),
+					checkpointMemRequest: checkpoint.NewSeries(data.MemRequests),
+					checkpointCpuLimit:   checkpoint.NewSeries(data.CpuLimits),
+					checkpointMemLimit:   checkpoint.NewSeries(data.MemLimits),
+					checkpointReplicas:   checkpoint.NewSeries(data.Replicas),
+				},
+				Start: data.Range.Start,
+				End:   data.Range.End,
+			})
+			if err != nil {
+				writer.Abort()
+				return err
+			}
+		}
+	}
+	return writer.Close()
+}
+
+// mergeTimeSeries merges the refreshed series into the checkpointed series of the same labels, the refreshed sample wins at the same timestamp
+func mergeTimeSeries(checkpointed, refreshed []*common.TimeSeries) []*common.TimeSeries {
+	refreshedByLabels := make(map[string]*common.TimeSeries, len(refreshed))
+	for _, ts := range refreshed {
+		refreshedByLabels[labelsKey(ts.Labels)] = ts
+	}
+	result := make([]*common.TimeSeries, 0, len(checkpointed)+len(refreshed))
+	for _, ts := range checkpointed {
+		key := labelsKey(ts.Labels)
+		if refreshedTs, ok := refreshedByLabels[key]; ok {
+			result = append(result, prom.MergeSortedTimeSeries(refreshedTs, ts))
+			delete(refreshedByLabels, key)
+			continue
+		}
+		result = append(result, ts)
+	}
+	// the series appeared after the checkpoint
+	for _, ts := range refreshed {
+		if _, ok := refreshedByLabels[labelsKey(ts.Labels)]; ok {
+			result = append(result, ts)
+		}
+	}
+	return result
+}
+
+func labelsKey(labels []common.Label) string {
+	keys := make([]string, 0, len(labels))
+	for _, label := range labels {
+		keys = append(keys, label.Name+"="+label.Value)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,246 @@
+package cost_comparator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/config"
+	"github.com/gocrane/fadvisor/pkg/metricnaming"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+func TestContainerDataCheckpoint(t *testing.T) {
+	nginx := types.NamespacedName{Namespace: "default", Name: "nginx"}
+	deleted := types.NamespacedName{Namespace: "default", Name: "deleted"}
+	cfg := config.Config{
+		ClusterId:             "cls",
+		DataSource:            "prom",
+		DataPath:              t.TempDir(),
+		CheckpointMinCoverage: 0.9,
+		History:               config.HistoryAnalyzeConfig{EndTime: "2022-01-01T00:00:00Z", Length: time.Hour, Step: time.Minute},
+	}
+	end, _ := time.Parse(time.RFC3339, cfg.History.EndTime)
+	cpu := common.NewTimeSeries()
+	cpu.AppendSample(end.Add(-30*time.Minute).Unix(), 0.5)
+	data := map[string]map[types.NamespacedName]map[string]*RawContainerTimeSeriesData{
+		"Deployment": {
+			nginx:   {"nginx": {Cpu: []*common.TimeSeries{cpu}}},
+			deleted: {"app": {Cpu: []*common.TimeSeries{cpu}}},
+		},
+	}
+	c := &Comparator{config: cfg, containersTimeSeriesDataCache: data}
+	if err := c.ContainerDataCheckpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	c = &Comparator{config: cfg, workloadsSpecCache: map[string]map[types.NamespacedName]spec.CloudPodSpec{"Deployment": {nginx: {}}}}
+	loaded, err := c.LoadContainerTimeSeriesDataFromCheckpoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded["Deployment"][deleted]; ok {
+		t.Errorf("expect the deleted workload dropped")
+	}
+	rawData := loaded["Deployment"][nginx]["nginx"]
+	if rawData == nil || len(rawData.Cpu) != 1 || len(rawData.Cpu[0].Samples) != 1 || rawData.Cpu[0].Samples[0].Value != 0.5 {
+		t.Errorf("unexpected loaded data %+v", rawData)
+	}
+
+	// the checkpoint of other datasource is refused
+	cfg.DataSource = "qm"
+	c = &Comparator{config: cfg, workloadsSpecCache: map[string]map[types.NamespacedName]spec.CloudPodSpec{"Deployment": {nginx: {}}}}
+	if _, err := c.LoadContainerTimeSeriesDataFromCheckpoint(); err == nil {
+		t.Errorf("expect the checkpoint of other datasource refused")
+	}
+}
+
+// rangeDataSource returns a sample of value 2 at each step of the queried range, and records the start of the queries
+type rangeDataSource struct {
+	lock   sync.Mutex
+	starts []time.Time
+}
+
+func (f *rangeDataSource) QueryLatestTimeSeries(ctx context.Context, metricNamer metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+	return nil, nil
+}
+
+func (f *rangeDataSource) QueryTimeSeries(ctx context.Context, metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error) {
+	f.lock.Lock()
+	f.starts = append(f.starts, startTime)
+	f.lock.Unlock()
+	ts := common.NewTimeSeries()
+	for t := startTime; !t.After(endTime); t = t.Add(step) {
+		ts.AppendSample(t.Unix(), 2)
+	}
+	return []*common.TimeSeries{ts}, nil
+}
+
+func TestContainerTsDataInitIncremental(t *testing.T) {
+	nginx := types.NamespacedName{Namespace: "default", Name: "nginx"}
+	cfg := config.Config{
+		ClusterId:                 "cls",
+		DataSource:                "prom",
+		DataPath:                  t.TempDir(),
+		EnableContainerCheckpoint: true,
+		CheckpointMinCoverage:     0.9,
+		CheckpointIncremental:     true,
+		History:                   config.HistoryAnalyzeConfig{EndTime: "2022-01-01T00:00:00Z", Length: time.Hour, Step: time.Minute},
+		Fetch:                     config.FetchConfig{Concurrency: 1},
+	}
+	end, _ := time.Parse(time.RFC3339, cfg.History.EndTime)
+	cpu := common.NewTimeSeries()
+	for t := end.Add(-time.Hour); !t.After(end); t = t.Add(time.Minute) {
+		cpu.AppendSample(t.Unix(), 1)
+	}
+	c := &Comparator{config: cfg, containersTimeSeriesDataCache: map[string]map[types.NamespacedName]map[string]*RawContainerTimeSeriesData{
+		"Deployment": {nginx: {"nginx": {Cpu: []*common.TimeSeries{cpu}}}},
+	}}
+	if err := c.ContainerDataCheckpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the next run ends 10 minutes later, only the 10 minutes are fetched
+	cfg.History.EndTime = "2022-01-01T00:10:00Z"
+	ds := &rangeDataSource{}
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "nginx"}}}}
+	c = &Comparator{config: cfg, dataSource: ds, workloadsSpecCache: map[string]map[types.NamespacedName]spec.CloudPodSpec{"Deployment": {nginx: {PodRef: pod}}}}
+	if err := c.ContainerTsDataInit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, start := range ds.starts {
+		if !start.Equal(end) {
+			t.Errorf("expect fetching since the checkpoint end %v, got %v", end, start)
+		}
+	}
+	rawData := c.containersTimeSeriesDataCache["Deployment"][nginx]["nginx"]
+	if rawData == nil || len(rawData.Cpu) != 1 {
+		t.Fatalf("unexpected data %+v", rawData)
+	}
+	samples := rawData.Cpu[0].Samples
+	// samples before the new window start are dropped, the refreshed sample wins at the checkpoint end
+	if len(samples) != 61 || samples[0].Timestamp != end.Add(-50*time.Minute).Unix() || samples[50].Value != 2 || samples[49].Value != 1 {
+		t.Errorf("unexpected merged samples %v", samples)
+	}
+
+	// the refreshed checkpoint covers the new window, so it is reused without fetching
+	ds = &rangeDataSource{}
+	c = &Comparator{config: cfg, dataSource: ds, workloadsSpecCache: map[string]map[types.NamespacedName]spec.CloudPodSpec{"Deployment": {nginx: {PodRef: pod}}}}
+	if err := c.ContainerTsDataInit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.starts) != 0 {
+		t.Errorf("expect no fetching, got %v", len(ds.starts))
+	}
+	if samples := c.containersTimeSeriesDataCache["Deployment"][nginx]["nginx"].Cpu[0].Samples; len(samples) != 61 {
+		t.Errorf("expect 61 samples, got %v", len(samples))
+	}
+}
+
+// failingDataSource fails the queries of the metrics whose key contains fail, and records the keys of the queries
+type failingDataSource struct {
+	rangeDataSource
+	fail string
+	keys []string
+}
+
+func (f *failingDataSource) QueryTimeSeries(ctx context.Context, metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error) {
+	key := metricNamer.BuildUniqueKey()
+	f.lock.Lock()
+	f.keys = append(f.keys, key)
+	f.lock.Unlock()
+	if f.fail != "" && strings.Contains(key, f.fail) {
+		return nil, fmt.Errorf("query %v failed", key)
+	}
+	return f.rangeDataSource.QueryTimeSeries(ctx, metricNamer, startTime, endTime, step)
+}
+
+func TestContainerTsDataInitResume(t *testing.T) {
+	shop := types.NamespacedName{Namespace: "default", Name: "shop"}
+	cfg := config.Config{
+		ClusterId:                 "cls",
+		DataSource:                "prom",
+		DataPath:                  t.TempDir(),
+		EnableContainerCheckpoint: true,
+		CheckpointMinCoverage:     0.9,
+		History:                   config.HistoryAnalyzeConfig{EndTime: "2022-01-01T00:00:00Z", Length: time.Hour, Step: time.Minute},
+		Fetch:                     config.FetchConfig{Concurrency: 1},
+	}
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "nginx"}, {Name: "redis"}}}}
+	workloads := map[string]map[types.NamespacedName]spec.CloudPodSpec{"Deployment": {shop: {PodRef: pod}}}
+
+	// the queries of redis fail, the data of nginx is checkpointed
+	ds := &failingDataSource{fail: "redis"}
+	c := &Comparator{config: cfg, dataSource: ds, workloadsSpecCache: workloads}
+	if err := c.ContainerTsDataInit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.containersTimeSeriesDataCache["Deployment"][shop]["redis"]; ok {
+		t.Errorf("expect no data of the failed container")
+	}
+
+	// the next run resumes the checkpoint, only redis is fetched
+	ds = &failingDataSource{}
+	c = &Comparator{config: cfg, dataSource: ds, workloadsSpecCache: workloads}
+	if err := c.ContainerTsDataInit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range ds.keys {
+		if !strings.Contains(key, "redis") {
+			t.Errorf("expect only the queries of redis, got %v", key)
+		}
+	}
+	if len(ds.keys) == 0 {
+		t.Errorf("expect the queries of redis")
+	}
+	for _, container := range []string{"nginx", "redis"} {
+		if rawData := c.containersTimeSeriesDataCache["Deployment"][shop][container]; rawData == nil || len(rawData.Cpu) != 1 || len(rawData.Cpu[0].Samples) != 61 {
+			t.Errorf("expect 61 samples of %v, got %+v", container, rawData)
+		}
+	}
+
+	// the resumed checkpoint is complete, it is reused without fetching
+	ds = &failingDataSource{}
+	c = &Comparator{config: cfg, dataSource: ds, workloadsSpecCache: workloads}
+	if err := c.ContainerTsDataInit(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.keys) != 0 {
+		t.Errorf("expect no fetching, got %v", ds.keys)
+	}
+}
+
+func TestMergeTimeSeries(t *testing.T) {
+	old := common.NewTimeSeries()
+	old.AppendLabel("pod", "a")
+	old.AppendSample(1, 1)
+	old.AppendSample(2, 1)
+	gone := common.NewTimeSeries()
+	gone.AppendLabel("pod", "b")
+	gone.AppendSample(1, 1)
+	refreshed := common.NewTimeSeries()
+	refreshed.AppendLabel("pod", "a")
+	refreshed.AppendSample(2, 2)
+	refreshed.AppendSample(3, 2)
+	added := common.NewTimeSeries()
+	added.AppendLabel("pod", "c")
+	added.AppendSample(3, 2)
+
+	merged := mergeTimeSeries([]*common.TimeSeries{old, gone}, []*common.TimeSeries{refreshed, added})
+	if len(merged) != 3 {
+		t.Fatalf("expect 3 series, got %v", len(merged))
+	}
+	if samples := merged[0].Samples; len(samples) != 3 || samples[1].Value != 2 {
+		t.Errorf("unexpected merged samples %v", samples)
+	}
+	if merged[1] != gone || merged[2] != added {
+		t.Errorf("expect the other series kept")
+	}
+}
@@ -0,0 +1,161 @@
+package cost_comparator
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/fadvisor/pkg/metricnaming"
+)
+
+// fetchTask is the queries of a workload or container, results are assigned only if all the queries succeed
+type fetchTask struct {
+	key string
+	// qRange is the range to query, it is shorter than the history window if the task refreshes a checkpoint
+	qRange  promapiv1.Range
+	queries []metricnaming.MetricNamer
+	assign  func(results [][]*common.TimeSeries)
+}
+
+// fetchStats is the progress of fetching tasks
+type fetchStats struct {
+	total     int
+	succeeded int64
+	failed    int64
+}
+
+// fetchContext return the ctx of the fetch deadline, it is created once and shared by all the fetching phases of a run
+func (c *Comparator) fetchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.config.Fetch.Deadline > 0 {
+		return context.WithTimeout(ctx, c.config.Fetch.Deadline)
+	}
+	return context.WithCancel(ctx)
+}
+
+// runFetchTasks runs the tasks by a bounded worker pool, each query has a timeout and is retried. It stops early if the ctx is done,
+// the ctx carries the fetch deadline of the run. The results of the finished tasks are kept, so the result is partial. assign is called serially.
+// It returns false if some tasks are not run or failed, the failed tasks keep the cached data so it does not cover the range of the run.
+func (c *Comparator) runFetchTasks(ctx context.Context, name string, tasks []fetchTask) bool {
+	fetchConfig := c.config.Fetch
+	concurrency := fetchConfig.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	stats := &fetchStats{total: len(tasks)}
+	stopProgress := make(chan struct{})
+	if fetchConfig.ProgressInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(fetchConfig.ProgressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					klog.Infof("Fetching %v, succeeded %v, failed %v, total %v", name, atomic.LoadInt64(&stats.succeeded), atomic.LoadInt64(&stats.failed), stats.total)
+				case <-stopProgress:
+					return
+				}
+			}
+		}()
+	}
+
+	taskCh := make(chan fetchTask)
+	var assignLock sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range taskCh {
+				results, err := c.runFetchTask(ctx, task)
+				if err != nil {
+					atomic.AddInt64(&stats.failed, 1)
+					continue
+				}
+				assignLock.Lock()
+				task.assign(results)
+				assignLock.Unlock()
+				atomic.AddInt64(&stats.succeeded, 1)
+			}
+		}()
+	}
+
+	complete := true
+dispatch:
+	for _, task := range tasks {
+		select {
+		case taskCh <- task:
+		case <-ctx.Done():
+			complete = false
+			break dispatch
+		}
+	}
+	close(taskCh)
+	wg.Wait()
+	close(stopProgress)
+
+	if ctx.Err() != nil {
+		complete = false
+		klog.Warningf("Fetching %v stopped early: %v, the result is partial, succeeded %v, failed %v, total %v", name, ctx.Err(), stats.succeeded, stats.failed, stats.total)
+	} else if stats.failed > 0 {
+		complete = false
+		klog.Warningf("Fetching %v failed for some tasks, the result is partial, succeeded %v, failed %v, total %v", name, stats.succeeded, stats.failed, stats.total)
+	} else {
+		klog.Infof("Fetched %v, succeeded %v, failed %v, total %v", name, stats.succeeded, stats.failed, stats.total)
+	}
+	return complete
+}
+
+func (c *Comparator) runFetchTask(ctx context.Context, task fetchTask) ([][]*common.TimeSeries, error) {
+	results := make([][]*common.TimeSeries, 0, len(task.queries))
+	for _, query := range task.queries {
+		tsList, err := c.queryWithRetry(ctx, task.qRange, query)
+		if err != nil {
+			klog.Errorf("Failed to query history of %v for metric %v: %v", task.key, query.BuildUniqueKey(), err)
+			return nil, err
+		}
+		results = append(results, tsList)
+	}
+	return results, nil
+}
+
+// queryWithRetry queries the time series with a timeout, and retries with a backoff if failed
+func (c *Comparator) queryWithRetry(ctx context.Context, qRange promapiv1.Range, query metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+	fetchConfig := c.config.Fetch
+	backoff := fetchConfig.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= fetchConfig.Retries; attempt++ {
+		if attempt > 0 {
+			klog.V(4).Infof("Retry query of metric %v after %v: %v", query.BuildUniqueKey(), backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			backoff *= 2
+		}
+		var tsList []*common.TimeSeries
+		tsList, err = c.queryTimeSeries(ctx, qRange, query)
+		if err == nil {
+			return tsList, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+func (c *Comparator) queryTimeSeries(ctx context.Context, qRange promapiv1.Range, query metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+	if c.config.Fetch.QueryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Fetch.QueryTimeout)
+		defer cancel()
+	}
+	return c.dataSource.QueryTimeSeries(ctx, query, qRange.Start, qRange.End, qRange.Step)
+}
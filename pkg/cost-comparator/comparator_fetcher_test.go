@@ -0,0 +1,147 @@
+package cost_comparator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	promapiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/config"
+	"github.com/gocrane/fadvisor/pkg/metricnaming"
+)
+
+// fakeDataSource fails the first failures queries of each metric, and blocks the queries of the metrics in block until ctx is done
+type fakeDataSource struct {
+	lock     sync.Mutex
+	failures int
+	attempts map[string]int
+	block    map[string]bool
+	inflight int64
+	maxConc  int64
+}
+
+func (f *fakeDataSource) QueryLatestTimeSeries(ctx context.Context, metricNamer metricnaming.MetricNamer) ([]*common.TimeSeries, error) {
+	return nil, nil
+}
+
+func (f *fakeDataSource) QueryTimeSeries(ctx context.Context, metricNamer metricnaming.MetricNamer, startTime time.Time, endTime time.Time, step time.Duration) ([]*common.TimeSeries, error) {
+	n := atomic.AddInt64(&f.inflight, 1)
+	defer atomic.AddInt64(&f.inflight, -1)
+	for {
+		max := atomic.LoadInt64(&f.maxConc)
+		if n <= max || atomic.CompareAndSwapInt64(&f.maxConc, max, n) {
+			break
+		}
+	}
+	key := metricNamer.BuildUniqueKey()
+	f.lock.Lock()
+	f.attempts[key]++
+	attempt := f.attempts[key]
+	blocked := f.block[key]
+	f.lock.Unlock()
+	if blocked {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	time.Sleep(time.Millisecond)
+	if attempt <= f.failures {
+		return nil, fmt.Errorf("failure %v", attempt)
+	}
+	ts := common.NewTimeSeries()
+	ts.AppendSample(0, 1)
+	return []*common.TimeSeries{ts}, nil
+}
+
+func TestRunFetchTasks(t *testing.T) {
+	ds := &fakeDataSource{failures: 1, attempts: map[string]int{}, block: map[string]bool{}}
+	c := &Comparator{
+		dataSource: ds,
+		config: config.Config{Fetch: config.FetchConfig{
+			Concurrency:  3,
+			QueryTimeout: time.Second,
+			Retries:      1,
+			RetryBackoff: time.Millisecond,
+		}},
+	}
+	results := map[string]int{}
+	var tasks []fetchTask
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("c%v", i)
+		tasks = append(tasks, fetchTask{
+			key:    name,
+			qRange: promapiv1.Range{Step: time.Minute},
+			queries: []metricnaming.MetricNamer{
+				metricnaming.ResourceToContainerMetricNamer("cls", "default", "nginx", name, v1.ResourceCPU),
+				metricnaming.ResourceToContainerMetricNamer("cls", "default", "nginx", name, v1.ResourceMemory),
+			},
+			assign: func(ts [][]*common.TimeSeries) {
+				results[name] = len(ts)
+			},
+		})
+	}
+
+	if complete := c.runFetchTasks(context.Background(), "test", tasks); !complete {
+		t.Errorf("expect complete")
+	}
+	if len(results) != 10 || results["c0"] != 2 {
+		t.Errorf("expect all tasks succeeded after retry, got %v", results)
+	}
+	if ds.maxConc > 3 {
+		t.Errorf("expect concurrency bounded by 3, got %v", ds.maxConc)
+	}
+
+	// the tasks failed after the retries make the result partial
+	c.dataSource = &fakeDataSource{failures: 2, attempts: map[string]int{}, block: map[string]bool{}}
+	results = map[string]int{}
+	if complete := c.runFetchTasks(context.Background(), "test", tasks); complete {
+		t.Errorf("expect partial result of the failed tasks")
+	}
+	if len(results) != 0 {
+		t.Errorf("expect no results of the failed tasks, got %v", results)
+	}
+}
+
+func TestRunFetchTasksDeadline(t *testing.T) {
+	blocked := metricnaming.ResourceToContainerMetricNamer("cls", "default", "nginx", "c1", v1.ResourceCPU)
+	ds := &fakeDataSource{attempts: map[string]int{}, block: map[string]bool{blocked.BuildUniqueKey(): true}}
+	c := &Comparator{
+		dataSource: ds,
+		config: config.Config{Fetch: config.FetchConfig{
+			Concurrency: 1,
+			Deadline:    100 * time.Millisecond,
+		}},
+	}
+	results := map[string]bool{}
+	var tasks []fetchTask
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("c%v", i)
+		tasks = append(tasks, fetchTask{
+			key:     name,
+			qRange:  promapiv1.Range{Step: time.Minute},
+			queries: []metricnaming.MetricNamer{metricnaming.ResourceToContainerMetricNamer("cls", "default", "nginx", name, v1.ResourceCPU)},
+			assign: func(ts [][]*common.TimeSeries) {
+				results[name] = true
+			},
+		})
+	}
+
+	ctx, cancel := c.fetchContext(context.Background())
+	defer cancel()
+	if complete := c.runFetchTasks(ctx, "test", tasks); complete {
+		t.Errorf("expect partial result after deadline")
+	}
+	// c0 is fetched before the deadline, c1 blocks until the deadline and c2 is not run
+	if !results["c0"] || results["c1"] || results["c2"] {
+		t.Errorf("expect partial result of c0, got %v", results)
+	}
+	// the deadline is shared by the later phases
+	if complete := c.runFetchTasks(ctx, "test", tasks[2:]); complete {
+		t.Errorf("expect the later phase stopped by the exceeded deadline")
+	}
+}
@@ -0,0 +1,100 @@
+package cost_comparator
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/coster"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+const (
+	// BaselineTarget is the target name of the baseline cloud in the provider comparison
+	BaselineTarget = "baseline"
+
+	ProviderModeServerful  = "serverful"
+	ProviderModeServerless = "serverless"
+)
+
+// ProviderCostResult is the cost of the cluster workloads on a cloud provider
+type ProviderCostResult struct {
+	Target   string
+	Provider string
+	Mode     string
+	Cost     coster.Cost
+	// Savings is the serverful cost on the baseline cloud minus the total cost
+	Savings float64
+}
+
+// ProviderComparison prices the same workloads on the baseline cloud and each target cloud, serverful on the current nodes
+// or serverless. The pods and nodes are converted by the spec converters of each cloud, so the specification rounding of the cloud applies
+func (c *Comparator) ProviderComparison(costerCtx *coster.CosterContext) []ProviderCostResult {
+	targets := append([]TargetCloud{{Name: BaselineTarget, Cloud: c.baselineCloud}}, c.targetClouds...)
+	var results []ProviderCostResult
+	for _, target := range targets {
+		serverfulCost := coster.NewServerfulCoster().TotalCost(c.targetServerfulCosterContext(costerCtx, target))
+		serverlessCost := coster.NewServerlessCoster().TotalCost(c.targetServerlessCosterContext(costerCtx, target))
+		results = append(results,
+			ProviderCostResult{Target: target.Name, Provider: target.Provider, Mode: ProviderModeServerful, Cost: serverfulCost},
+			ProviderCostResult{Target: target.Name, Provider: target.Provider, Mode: ProviderModeServerless, Cost: serverlessCost},
+		)
+	}
+	baselineCost := results[0].Cost.TotalCost
+	for i := range results {
+		results[i].Savings = baselineCost - results[i].Cost.TotalCost
+	}
+	return results
+}
+
+// targetServerfulCosterContext keeps the real nodes of the cluster and prices them on the target, the serverless pods of the baseline
+// cloud are kept serverless on the target. The pods in the real nodes are kept too if the node cost is allocated to the pods
+func (c *Comparator) targetServerfulCosterContext(costerCtx *coster.CosterContext, target TargetCloud) *coster.CosterContext {
+	ctx := *costerCtx
+	ctx.Pricer = target.Cloud
+	ctx.NodesSpec = make(map[string]spec.CloudNodeSpec)
+	for _, node := range c.clusterCache.GetNodes() {
+		if c.baselineCloud.IsVirtualNode(node) {
+			continue
+		}
+		nodeSpec := target.Cloud.Node2Spec(node)
+		nodeSpec.VirtualNode = false
+		ctx.NodesSpec[node.Name] = nodeSpec
+	}
+	ctx.PodsSpec = make(map[string]spec.CloudPodSpec)
+	for _, pod := range c.kindScopedPods() {
+		if !c.baselineCloud.IsServerlessPod(pod) {
+			// the pods in the real nodes are priced on the target nodes if the node cost is allocated to the pods
+			if ctx.NodeCostByPods {
+				key := klog.KObj(pod).String()
+				podSpec := target.Cloud.Pod2Spec(pod)
+				podSpec.Serverless = false
+				podSpec.Usage = costerCtx.PodsSpec[key].Usage
+				ctx.PodsSpec[key] = podSpec
+			}
+			continue
+		}
+		ctx.PodsSpec[klog.KObj(pod).String()] = target.Cloud.Pod2ServerlessSpec(pod)
+	}
+	return &ctx
+}
+
+// targetServerlessCosterContext converts the workloads to the serverless pods of the target, the replicas are kept
+func (c *Comparator) targetServerlessCosterContext(costerCtx *coster.CosterContext, target TargetCloud) *coster.CosterContext {
+	ctx := *costerCtx
+	ctx.Pricer = target.Cloud
+	ctx.WorkloadsSpec = make(map[string]map[types.NamespacedName]spec.CloudPodSpec)
+	for kind, workloads := range costerCtx.WorkloadsSpec {
+		kindWorkloads := make(map[types.NamespacedName]spec.CloudPodSpec, len(workloads))
+		for nn, workload := range workloads {
+			if workload.PodRef == nil {
+				continue
+			}
+			podSpec := target.Cloud.Pod2ServerlessSpec(workload.PodRef)
+			podSpec.GoodsNum *= workload.GoodsNum
+			podSpec.Workload = workload.Workload
+			kindWorkloads[nn] = podSpec
+		}
+		ctx.WorkloadsSpec[kind] = kindWorkloads
+	}
+	return &ctx
+}
@@ -0,0 +1,137 @@
+package cost_comparator
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/fadvisor/pkg/cache"
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	defaultcloud "github.com/gocrane/fadvisor/pkg/cloudproviders/default"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/config"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/coster"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+type fakeCache struct {
+	pods  []*v1.Pod
+	nodes []*v1.Node
+}
+
+func (f *fakeCache) GetAllHPAs() []*autoscalingv1.HorizontalPodAutoscaler { return nil }
+func (f *fakeCache) GetStatefulSets() []*appsv1.StatefulSet               { return nil }
+func (f *fakeCache) GetDaemonSets() []*appsv1.DaemonSet                   { return nil }
+func (f *fakeCache) GetDeployments() []*appsv1.Deployment                 { return nil }
+func (f *fakeCache) GetPods() []*v1.Pod                                   { return f.pods }
+func (f *fakeCache) GetNodes() []*v1.Node                                 { return f.nodes }
+func (f *fakeCache) WaitForCacheSync(stopCh <-chan struct{})              {}
+
+func (f *fakeCache) GetNode(name string) *v1.Node {
+	for _, n := range f.nodes {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+func TestProviderComparison(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx-0"},
+		Spec: v1.PodSpec{NodeName: "node", Containers: []v1.Container{{Name: "nginx", Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("2Gi")},
+		}}}},
+	}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status:     v1.NodeStatus{Capacity: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("8Gi")}},
+	}
+	var clusterCache cache.Cache = &fakeCache{pods: []*v1.Pod{pod}, nodes: []*v1.Node{node}}
+	newCloud := func(cpuPrice, ramPrice float64) cloud.Cloud {
+		return defaultcloud.NewDefaultCloud(cloud.NewProviderConfig(&cloud.CustomPricing{CpuHourlyPrice: cpuPrice, RamGBHourlyPrice: ramPrice}), clusterCache)
+	}
+
+	workload := cloud.NodePodSpec(pod)
+	workload.GoodsNum = 3
+	c := &Comparator{
+		clusterCache:  clusterCache,
+		baselineCloud: newCloud(1, 0.5),
+		targetClouds:  []TargetCloud{{Name: "cheaper", Provider: "default", Cloud: newCloud(0.5, 0.25)}},
+	}
+	costerCtx := &coster.CosterContext{
+		TimeSpanSeconds: 3600,
+		WorkloadsSpec:   map[string]map[types.NamespacedName]spec.CloudPodSpec{"Deployment": {{Namespace: "default", Name: "nginx"}: workload}},
+		Pricer:          c.baselineCloud,
+	}
+
+	expected := []struct {
+		target string
+		mode   string
+		cost   float64
+	}{
+		// node of 4 cores and 8GB
+		{target: BaselineTarget, mode: ProviderModeServerful, cost: 8},
+		// 3 replicas of 1 core and 2GB
+		{target: BaselineTarget, mode: ProviderModeServerless, cost: 6},
+		{target: "cheaper", mode: ProviderModeServerful, cost: 4},
+		{target: "cheaper", mode: ProviderModeServerless, cost: 3},
+	}
+	results := c.ProviderComparison(costerCtx)
+	if len(results) != len(expected) {
+		t.Fatalf("expect %v results, got %v", len(expected), len(results))
+	}
+	for i, e := range expected {
+		r := results[i]
+		if r.Target != e.target || r.Mode != e.mode || r.Cost.TotalCost != e.cost || r.Savings != 8-e.cost {
+			t.Errorf("expect %v %v cost %v, got %+v", e.target, e.mode, e.cost, r)
+		}
+	}
+
+	// the scoped run is charged the node cost of the pod of 1 core and 2GB only
+	c.config.Scope = config.ScopeConfig{Namespaces: []string{"default"}}
+	costerCtx.NodeCostByPods = !c.config.Scope.Empty()
+	costerCtx.PodsSpec = map[string]spec.CloudPodSpec{"default/nginx-0": c.baselineCloud.Pod2Spec(pod)}
+	results = c.ProviderComparison(costerCtx)
+	if r := results[0]; r.Mode != ProviderModeServerful || r.Cost.TotalCost != 2 {
+		t.Errorf("expect scoped baseline serverful cost 2, got %+v", r)
+	}
+	if r := results[2]; r.Target != "cheaper" || r.Mode != ProviderModeServerful || r.Cost.TotalCost != 1 || r.Savings != 1 {
+		t.Errorf("expect scoped cheaper serverful cost 1, got %+v", r)
+	}
+
+	// the platform fee of the cluster is charged by the share of the node cost of the pods in scope, 2 of 8
+	costerCtx.Pricer = defaultcloud.NewDefaultCloud(cloud.NewProviderConfig(&cloud.CustomPricing{CpuHourlyPrice: 1, RamGBHourlyPrice: 0.5,
+		PlatformFees: map[string]cloud.PlatformFeeModel{string(cloud.ServerfulKind): {NodeHourlyPrice: 4}}}), clusterCache)
+	costerCtx.NodesSpec = map[string]spec.CloudNodeSpec{node.Name: c.baselineCloud.Node2Spec(node)}
+	if cost := coster.NewServerfulCoster().TotalCost(costerCtx); cost.ServerfulPlatformCost != 1 || cost.TotalCost != 3 {
+		t.Errorf("expect scoped platform cost 1 and total cost 3, got %+v", cost)
+	}
+}
+
+func TestKindScopedPods(t *testing.T) {
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	}
+	newOwner := func(kind string) *unstructured.Unstructured {
+		owner := &unstructured.Unstructured{}
+		owner.SetKind(kind)
+		return owner
+	}
+	c := &Comparator{
+		clusterCache: &fakeCache{pods: []*v1.Pod{newPod("web-0"), newPod("agent-0"), newPod("unknown")}},
+		podWorkloads: map[string]*unstructured.Unstructured{"default/web-0": newOwner("Deployment"), "default/agent-0": newOwner("DaemonSet")},
+	}
+	if pods := c.kindScopedPods(); len(pods) != 3 {
+		t.Errorf("expect all pods without kinds scope, got %v", len(pods))
+	}
+	c.config.Scope = config.ScopeConfig{ExcludeKinds: []string{"daemonset"}}
+	if pods := c.kindScopedPods(); len(pods) != 1 || pods[0].Name != "web-0" {
+		t.Errorf("expect pod web-0 in scope, got %v", pods)
+	}
+}
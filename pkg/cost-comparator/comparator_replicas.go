@@ -0,0 +1,81 @@
+package cost_comparator
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// WorkloadsAverageReplicas returns the time weighted average replicas of the workloads over the history window by the fetched
+// replicas time series, the workloads without replicas history and the daemonsets are not in it
+func (c *Comparator) WorkloadsAverageReplicas() map[string]map[types.NamespacedName]float64 {
+	results := make(map[string]map[types.NamespacedName]float64)
+	for kind, workloads := range c.workloadsTimeSeriesDataCache {
+		if strings.ToLower(kind) == "daemonset" {
+			continue
+		}
+		for nn, data := range workloads {
+			if data == nil {
+				continue
+			}
+			replicas, ok := averageReplicas(data.Replicas, c.config.History.Step)
+			if !ok {
+				continue
+			}
+			kindResults, ok := results[kind]
+			if !ok {
+				kindResults = make(map[types.NamespacedName]float64)
+				results[kind] = kindResults
+			}
+			kindResults[nn] = replicas
+		}
+	}
+	return results
+}
+
+// averageReplicas integrates the replicas over time and divides it by the time covered, a sample holds until the next sample
+// at most step, so the missing data is not counted. The max of the series is used at a timestamp, they are duplicates of a workload
+func averageReplicas(series []*common.TimeSeries, step time.Duration) (float64, bool) {
+	replicas := make(map[int64]float64)
+	for _, ts := range series {
+		if ts == nil {
+			continue
+		}
+		for _, sample := range ts.Samples {
+			if v, ok := replicas[sample.Timestamp]; !ok || sample.Value > v {
+				replicas[sample.Timestamp] = sample.Value
+			}
+		}
+	}
+	if len(replicas) == 0 {
+		return 0, false
+	}
+	timestamps := make([]int64, 0, len(replicas))
+	for timestamp := range replicas {
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i] < timestamps[j]
+	})
+
+	stepSeconds := step.Seconds()
+	var replicaSeconds, seconds float64
+	for i, timestamp := range timestamps {
+		// equal weights if the step is unknown
+		weight := 1.
+		if stepSeconds > 0 {
+			weight = stepSeconds
+			if i+1 < len(timestamps) {
+				weight = math.Min(float64(timestamps[i+1]-timestamp), stepSeconds)
+			}
+		}
+		replicaSeconds += replicas[timestamp] * weight
+		seconds += weight
+	}
+	return replicaSeconds / seconds, true
+}
@@ -0,0 +1,30 @@
+package cost_comparator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+func TestAverageReplicas(t *testing.T) {
+	replicas := common.NewTimeSeries()
+	// 2 replicas for 2 minutes, scaled to 6 for 1 minute, 10 minutes of missing data, then 2 replicas for 1 minute
+	for _, sample := range []struct {
+		timestamp int64
+		value     float64
+	}{{0, 2}, {60, 2}, {120, 6}, {780, 2}} {
+		replicas.AppendSample(sample.timestamp, sample.value)
+	}
+	// a duplicated series with a lower value
+	duplicate := common.NewTimeSeries()
+	duplicate.AppendSample(120, 3)
+
+	average, ok := averageReplicas([]*common.TimeSeries{replicas, duplicate}, time.Minute)
+	if !ok || average != 3 {
+		t.Errorf("expect average replicas 3, got %v", average)
+	}
+	if _, ok := averageReplicas(nil, time.Minute); ok {
+		t.Errorf("expect no average replicas without samples")
+	}
+}
@@ -1,201 +1,131 @@
 package cost_comparator
 
 import (
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
 
-	"github.com/olekukonko/tablewriter"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
 	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
 
 	"github.com/gocrane/fadvisor/pkg/consts"
 	"github.com/gocrane/fadvisor/pkg/cost-comparator/config"
 	"github.com/gocrane/fadvisor/pkg/cost-comparator/coster"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/report"
 	"github.com/gocrane/fadvisor/pkg/util"
 )
 
-func (c *Comparator) ReportOriginalWorkloadsResourceDistribution(costerCtx *coster.CosterContext) {
-	data := [][]string{}
-	for kind, kindWorkloads := range costerCtx.WorkloadsSpec {
-		for nn, workload := range kindWorkloads {
+// Report reports all the sections of the analysis by the reporter
+func (c *Comparator) Report(reporter report.Reporter, costerCtx *coster.CosterContext) error {
+	sections := []report.Section{
+		c.OriginalResourceSummary(),
+		c.OriginalCostSummary(costerCtx),
+		c.RawServerlessCostSummary(costerCtx),
+		c.RecommendedResourceSummary(costerCtx),
+		c.RecommendedCostSummary(costerCtx),
+		c.CostBreakdown(costerCtx),
+		c.SavingsOpportunitiesSection(costerCtx),
+	}
+	if armSavings, ok := c.ArmSavings(costerCtx); ok {
+		sections = append(sections, armSavings)
+	}
+	if c.config.Sensitivity.Enabled {
+		sections = append(sections, c.SensitivityMatrixSection(costerCtx))
+	}
+	if c.config.BinPacking.Enabled {
+		sections = append(sections, c.NodeMixSection(costerCtx))
+	}
+	if len(c.targetClouds) > 0 {
+		sections = append(sections, c.ProviderComparisonSection(costerCtx))
+	}
+	if c.config.EnableBatch {
+		sections = append(sections, c.BatchWorkloadsCostSection(costerCtx))
+	}
+	sections = append(sections,
+		c.OriginalWorkloadsResourceDistribution(costerCtx),
+		c.RecommendedWorkloadsResourceDistribution(costerCtx),
+	)
+	for _, section := range sections {
+		if err := reporter.Report(section); err != nil {
+			return fmt.Errorf("failed to report %v: %v", section.Name, err)
+		}
+	}
+	return nil
+}
 
-			cpuReqFloat64Cores := float64(workload.Cpu.MilliValue()) / 1000.
-			memReqFloat64GB := float64(workload.Mem.Value()) / consts.GB
-			cpuLimFloat64Cores := float64(workload.CpuLimit.MilliValue()) / 1000.
-			memLimFloat64GB := float64(workload.MemLimit.Value()) / consts.GB
+func cores(q resource.Quantity) float64 {
+	return float64(q.MilliValue()) / 1000.
+}
+
+func gigaBytes(q resource.Quantity) float64 {
+	return float64(q.Value()) / consts.GB
+}
 
-			labels := workload.Workload.GetLabels()
-			labelsStr := ""
-			if labels != nil {
-				labelsBytes, _ := json.Marshal(labels)
-				labelsStr = string(labelsBytes)
+func (c *Comparator) OriginalWorkloadsResourceDistribution(costerCtx *coster.CosterContext) report.Section {
+	var data report.OriginalWorkloadsDistribution
+	for kind, kindWorkloads := range costerCtx.WorkloadsSpec {
+		for nn, workload := range kindWorkloads {
+			w := report.OriginalWorkload{
+				WorkloadResource: report.WorkloadResource{
+					Kind:       kind,
+					Namespace:  nn.Namespace,
+					Name:       nn.Name,
+					CpuReq:     cores(workload.Cpu),
+					MemReq:     gigaBytes(workload.Mem),
+					CpuLim:     cores(workload.CpuLimit),
+					MemLim:     gigaBytes(workload.MemLimit),
+					Replicas:   workload.GoodsNum,
+					Serverless: workload.Serverless,
+					QoSClass:   string(workload.QoSClass),
+				},
+				Labels: workload.Workload.GetLabels(),
 			}
 
 			// non serverless, use original pod template resource requirements
 			if !workload.Serverless {
 				req, lim := resourcehelper.PodRequestsAndLimits(workload.PodRef)
-				reqCpu := req[v1.ResourceCPU]
-				reqMem := req[v1.ResourceMemory]
-				limCpu := lim[v1.ResourceCPU]
-				limMem := lim[v1.ResourceMemory]
-				cpuReqFloat64Cores = float64(reqCpu.MilliValue()) / 1000.
-				memReqFloat64GB = float64(reqMem.Value()) / consts.GB
-				cpuLimFloat64Cores = float64(limCpu.MilliValue()) / 1000.
-				memLimFloat64GB = float64(limMem.Value()) / consts.GB
+				w.CpuReq = cores(req[v1.ResourceCPU])
+				w.MemReq = gigaBytes(req[v1.ResourceMemory])
+				w.CpuLim = cores(lim[v1.ResourceCPU])
+				w.MemLim = gigaBytes(lim[v1.ResourceMemory])
 			}
-
-			data = append(data,
-				[]string{kind, nn.Namespace, nn.Name, Float642Str(cpuReqFloat64Cores), Float642Str(memReqFloat64GB), Float642Str(cpuLimFloat64Cores), Float642Str(memLimFloat64GB), fmt.Sprintf("%v", workload.GoodsNum), fmt.Sprintf("%v", workload.Serverless), string(workload.QoSClass), labelsStr},
-			)
-		}
-	}
-
-	fmt.Println("Reporting, Original Workloads Resource Distribution.....................................................................................................")
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeStdOut {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeaderLine(true)
-		table.SetAutoFormatHeaders(false)
-		table.SetHeader([]string{"Kind", "Namespace", "Name", "CpuReq", "MemReq", "CpuLim", "MemLim", "Replicas", "Serverless", "K8SQoS", "Labels"})
-		table.SetBorder(false) // Set Border to false
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-		)
-
-		table.SetColumnColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-		)
-		table.AppendBulk(data) // Add Bulk Data
-		table.Render()
-	}
-
-	filename := filepath.Join(c.config.DataPath, c.config.ClusterId+"-original-workloads-distribution"+".csv")
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeCsv {
-		csvFile, err := os.Create(filename)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		csvW := csv.NewWriter(csvFile)
-		csvW.Comma = '\t'
-		err = csvW.Write([]string{"Kind", "Namespace", "Name", "CpuReq", "MemReq", "CpuLim", "MemLim", "Replicas", "Serverless", "K8SQoS", "Labels"})
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		err = csvW.WriteAll(data)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
+			data = append(data, w)
 		}
 	}
-	fmt.Println()
+	return report.Section{Name: report.SectionOriginalWorkloadsDistribution, Title: "Original Workloads Resource Distribution", Data: data}
 }
 
-func (c *Comparator) ReportRecommendedWorkloadsResourceDistribution(costerCtx *coster.CosterContext) {
-	data := [][]string{}
+func (c *Comparator) RecommendedWorkloadsResourceDistribution(costerCtx *coster.CosterContext) report.Section {
+	var data report.RecommendedWorkloadsDistribution
 	for kind, kindWorkloads := range costerCtx.WorkloadsRecSpec {
 		for nn, workload := range kindWorkloads {
-			cpuReqFloat64Cores := float64(workload.RecommendedSpec.Cpu.MilliValue()) / 1000.
-			memReqFloat64GB := float64(workload.RecommendedSpec.Mem.Value()) / consts.GB
-			cpuLimFloat64Cores := float64(workload.RecommendedSpec.CpuLimit.MilliValue()) / 1000.
-			memLimFloat64GB := float64(workload.RecommendedSpec.MemLimit.Value()) / consts.GB
-			containerStats, _ := json.Marshal(workload.Containers)
-			data = append(data,
-				[]string{kind, nn.Namespace, nn.Name, Float642Str(cpuReqFloat64Cores), Float642Str(memReqFloat64GB), Float642Str(cpuLimFloat64Cores), Float642Str(memLimFloat64GB), fmt.Sprintf("%v", workload.RecommendedSpec.GoodsNum), fmt.Sprintf("%v", workload.RecommendedSpec.Serverless), string(workload.RecommendedSpec.QoSClass), string(containerStats)},
-			)
+			data = append(data, report.RecommendedWorkload{
+				WorkloadResource: report.WorkloadResource{
+					Kind:       kind,
+					Namespace:  nn.Namespace,
+					Name:       nn.Name,
+					CpuReq:     cores(workload.RecommendedSpec.Cpu),
+					MemReq:     gigaBytes(workload.RecommendedSpec.Mem),
+					CpuLim:     cores(workload.RecommendedSpec.CpuLimit),
+					MemLim:     gigaBytes(workload.RecommendedSpec.MemLimit),
+					Replicas:   workload.RecommendedSpec.GoodsNum,
+					Serverless: workload.RecommendedSpec.Serverless,
+					QoSClass:   string(workload.RecommendedSpec.QoSClass),
+				},
+				Containers: workload.Containers,
+			})
 		}
 	}
-
-	fmt.Println("Reporting, Recommended Workloads Resource Distribution.....................................................................................................")
-
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeStdOut {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeaderLine(true)
-		table.SetAutoFormatHeaders(false)
-		table.SetHeader([]string{"Kind", "Namespace", "Name", "CpuReq", "MemReq", "CpuLim", "MemLim", "Replicas", "Serverless", "K8SQoS", "ContainerStats"})
-		table.SetBorder(false) // Set Border to false
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-		)
-
-		table.SetColumnColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-		)
-
-		table.AppendBulk(data) // Add Bulk Data
-		table.Render()
-	}
-
-	filename := filepath.Join(c.config.DataPath, c.config.ClusterId+"-recommended-workloads-distribution"+".csv")
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeCsv {
-		csvFile, err := os.Create(filename)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		csvW := csv.NewWriter(csvFile)
-		csvW.Comma = '\t'
-		err = csvW.Write([]string{"Kind", "Namespace", "Name", "CpuReq", "MemReq", "CpuLim", "MemLim", "Replicas", "Serverless", "K8SQoS", "ContainerStats"})
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		err = csvW.WriteAll(data)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-	}
-
-	fmt.Println()
+	return report.Section{Name: report.SectionRecommendedWorkloadsDistribution, Title: "Recommended Workloads Resource Distribution", Data: data}
 }
 
-func (c *Comparator) ReportOriginalResourceSummary() {
+func resourceTotal(name string, resources v1.ResourceList) report.ResourceTotal {
+	return report.ResourceTotal{Type: name, Cpu: cores(*resources.Cpu()), Mem: gigaBytes(*resources.Memory())}
+}
 
-	pods := c.clusterCache.GetPods()
+func (c *Comparator) OriginalResourceSummary() report.Section {
+	pods := c.kindScopedPods()
 	clusterRequestsTotal, clusterLimitsTotal := util.PodsRequestsAndLimitsTotal(pods, func(pod *v1.Pod) bool {
 		return false
 	}, false)
@@ -207,301 +137,267 @@ func (c *Comparator) ReportOriginalResourceSummary() {
 	clusterRealNodesCapacityTotal := util.NodesResourceTotal(nodes, c.baselineCloud.IsVirtualNode, false)
 	clusterVirtualNodesCapacityTotal := util.NodesResourceTotal(nodes, c.baselineCloud.IsVirtualNode, true)
 
-	data := [][]string{
-		{"clusterRequestsTotal", Float642Str(float64(clusterRequestsTotal.Cpu().MilliValue()) / 1000.), Float642Str(float64(clusterRequestsTotal.Memory().Value()) / consts.GB)},
-		{"clusterLimitsTotal", Float642Str(float64(clusterLimitsTotal.Cpu().MilliValue()) / 1000.), Float642Str(float64(clusterLimitsTotal.Memory().Value()) / consts.GB)},
-		{"serverfulRequestsTotal", Float642Str(float64(serverfulRequestsTotal.Cpu().MilliValue()) / 1000.), Float642Str(float64(serverfulRequestsTotal.Memory().Value()) / consts.GB)},
-		{"serverfulLimitsTotal", Float642Str(float64(serverfulLimitsTotal.Cpu().MilliValue()) / 1000.), Float642Str(float64(serverfulLimitsTotal.Memory().Value()) / consts.GB)},
-		{"serverlessRequestsTotal", Float642Str(float64(serverlessRequestsTotal.Cpu().MilliValue()) / 1000.), Float642Str(float64(serverlessRequestsTotal.Memory().Value()) / consts.GB)},
-		{"serverlessLimitsTotal", Float642Str(float64(serverlessLimitsTotal.Cpu().MilliValue()) / 1000.), Float642Str(float64(serverlessLimitsTotal.Memory().Value()) / consts.GB)},
-		{"clusterRealNodesCapacityTotal", Float642Str(float64(clusterRealNodesCapacityTotal.Cpu().MilliValue()) / 1000.), Float642Str(float64(clusterRealNodesCapacityTotal.Memory().Value()) / consts.GB)},
-		{"clusterVirtualNodesCapacityTotal", clusterVirtualNodesCapacityTotal.Cpu().String(), clusterVirtualNodesCapacityTotal.Memory().String()},
-	}
-
-	fmt.Println("Reporting, Original Resource Summary.....................................................................................................")
-
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeStdOut {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeaderLine(true)
-		table.SetAutoFormatHeaders(false)
-		table.SetHeader([]string{"Type", "Cpu", "Mem"})
-		table.SetBorder(false) // Set Border to false
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor})
-
-		table.SetColumnColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgGreenColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor})
-
-		table.AppendBulk(data) // Add Bulk Data
-		table.Render()
+	data := report.ResourceSummary{
+		resourceTotal("clusterRequestsTotal", clusterRequestsTotal),
+		resourceTotal("clusterLimitsTotal", clusterLimitsTotal),
+		resourceTotal("serverfulRequestsTotal", serverfulRequestsTotal),
+		resourceTotal("serverfulLimitsTotal", serverfulLimitsTotal),
+		resourceTotal("serverlessRequestsTotal", serverlessRequestsTotal),
+		resourceTotal("serverlessLimitsTotal", serverlessLimitsTotal),
+		resourceTotal("clusterRealNodesCapacityTotal", clusterRealNodesCapacityTotal),
+		resourceTotal("clusterVirtualNodesCapacityTotal", clusterVirtualNodesCapacityTotal),
 	}
-
-	filename := filepath.Join(c.config.DataPath, c.config.ClusterId+"-original-resource-summary"+".csv")
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeCsv {
-		csvFile, err := os.Create(filename)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		csvW := csv.NewWriter(csvFile)
-		csvW.Comma = '\t'
-		err = csvW.Write([]string{"Type", "Cpu", "Mem"})
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		err = csvW.WriteAll(data)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-	}
-
-	fmt.Println()
+	return report.Section{Name: report.SectionOriginalResourceSummary, Title: "Original Resource Summary", Data: data}
 }
 
 func Float642Str(a float64) string {
 	return fmt.Sprintf("%.5f", a)
 }
 
-func (c *Comparator) ReportOriginalCostSummary(costerCtx *coster.CosterContext) {
-	serverfulCoster := coster.NewServerfulCoster()
-	originalFee := serverfulCoster.TotalCost(costerCtx)
-
-	data := [][]string{
-		{"tke", Float642Str(originalFee.TotalCost), Float642Str(originalFee.ServerfulCost), Float642Str(originalFee.ServerlessCost), Float642Str(originalFee.ServerfulPlatformCost), Float642Str(originalFee.ServerlessPlatformCost)},
-	}
-
-	fmt.Printf("Reporting, Original Cost Summary(TimeSpan: %v, Discount: %v)............................................................................\n", c.config.TimeSpanSeconds, c.config.Discount)
-
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeStdOut {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeaderLine(true)
-		table.SetAutoFormatHeaders(false)
-		table.SetHeader([]string{"Type", "TotalCost", "ServerfulCost", "ServerlessCost", "ServerfulPlatformCost", "ServerlessPlatformCost"})
-		table.SetBorder(false) // Set Border to false
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-		)
-
-		table.SetColumnColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgGreenColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-		)
-
-		table.AppendBulk(data) // Add Bulk Data
-		table.Render()
+func reportCost(name string, cost coster.Cost) report.Cost {
+	return report.Cost{
+		Type:                   name,
+		TotalCost:              cost.TotalCost,
+		ServerfulCost:          cost.ServerfulCost,
+		ServerlessCost:         cost.ServerlessCost,
+		ServerfulPlatformCost:  cost.ServerfulPlatformCost,
+		ServerlessPlatformCost: cost.ServerlessPlatformCost,
 	}
+}
 
-	filename := filepath.Join(c.config.DataPath, c.config.ClusterId+"-original-cost-summary"+".csv")
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeCsv {
-		csvFile, err := os.Create(filename)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		csvW := csv.NewWriter(csvFile)
-		csvW.Comma = '\t'
-		err = csvW.Write([]string{"Type", "TotalCost", "ServerfulCost", "ServerlessCost", "ServerfulPlatformCost", "ServerlessPlatformCost"})
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		err = csvW.WriteAll(data)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
+func (c *Comparator) OriginalCostSummary(costerCtx *coster.CosterContext) report.Section {
+	serverfulCoster := coster.NewServerfulCoster()
+	originalFee := serverfulCoster.TotalCost(costerCtx)
+	return report.Section{
+		Name:  report.SectionOriginalCostSummary,
+		Title: fmt.Sprintf("Original Cost Summary(TimeSpan: %v, Discount: %v)", c.config.TimeSpanSeconds, c.config.Discount),
+		Data:  report.CostSummary{reportCost("tke", originalFee)},
 	}
-
-	fmt.Println()
 }
 
-func (c *Comparator) ReportRawServerlessCostSummary(costerCtx *coster.CosterContext) {
+func (c *Comparator) RawServerlessCostSummary(costerCtx *coster.CosterContext) report.Section {
 	serverlessCoster := coster.NewServerlessCoster()
 	serverlessFee := serverlessCoster.TotalCost(costerCtx)
-
-	data := [][]string{
-		{"eks", Float642Str(serverlessFee.TotalCost), Float642Str(serverlessFee.ServerfulCost), Float642Str(serverlessFee.ServerlessCost), Float642Str(serverlessFee.ServerfulPlatformCost), Float642Str(serverlessFee.ServerlessPlatformCost)},
+	return report.Section{
+		Name:  report.SectionServerlessCostSummary,
+		Title: fmt.Sprintf("Direct Migrating to Serverless Cost Summary(TimeSpan: %v, Discount: %v)", c.config.TimeSpanSeconds, c.config.Discount),
+		Data:  report.CostSummary{reportCost("eks", serverlessFee)},
 	}
+}
 
-	fmt.Printf("Reporting, Direct Migrating to Serverless Cost Summary(TimeSpan: %v, Discount: %v)............................................................................\n", c.config.TimeSpanSeconds, c.config.Discount)
-
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeStdOut {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeaderLine(true)
-		table.SetAutoFormatHeaders(false)
-		table.SetHeader([]string{"Type", "TotalCost", "ServerfulCost", "ServerlessCost", "ServerfulPlatformCost", "ServerlessPlatformCost"})
-		table.SetBorder(false) // Set Border to false
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-		)
+func (c *Comparator) RecommendedResourceSummary(costerCtx *coster.CosterContext) report.Section {
+	recomendedResourceTotal := ServerlessWorkloadsResourceTotal(costerCtx.WorkloadsRecSpec)
+	return report.Section{
+		Name:  report.SectionRecommendedResourceSummary,
+		Title: "Recommended Resource Summary After Migrating to Serverless",
+		Data:  report.ResourceSummary{resourceTotal("recomendedServerlessResourceTotal", recomendedResourceTotal)},
+	}
+}
 
-		table.SetColumnColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgGreenColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-		)
+func reportRecommendedCost(name string, cost coster.RecommendedCost) report.RecommendedCost {
+	return report.RecommendedCost{Type: name, TotalCost: cost.TotalCost, WorkloadCost: cost.WorkloadCost, PlatformCost: cost.PlatformCost}
+}
 
-		table.AppendBulk(data) // Add Bulk Data
-		table.Render()
+func (c *Comparator) RecommendedCostSummary(costerCtx *coster.CosterContext) report.Section {
+	recommendedCoster := coster.NewRecommenderCoster()
+	RecommendedCost, PercentileCost, MaxCost, MaxMarginCost := recommendedCoster.TotalCost(costerCtx)
+	return report.Section{
+		Name:  report.SectionRecommendedCostSummary,
+		Title: fmt.Sprintf("Recommended Cost Summary After Migrating to Serverless(TimeSpan: %v, Discount: %v)", c.config.TimeSpanSeconds, c.config.Discount),
+		Data: report.RecommendedCostSummary{
+			reportRecommendedCost("eks-recommended-by-percentile-margin", RecommendedCost),
+			reportRecommendedCost("eks-recommended-by-percentile", PercentileCost),
+			reportRecommendedCost("eks-recommended-by-max-margin", MaxMarginCost),
+			reportRecommendedCost("eks-recommended-by-max", MaxCost),
+		},
 	}
+}
 
-	filename := filepath.Join(c.config.DataPath, c.config.ClusterId+"-direct-migrate-serverless-cost-summary"+".csv")
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeCsv {
-		csvFile, err := os.Create(filename)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		csvW := csv.NewWriter(csvFile)
-		csvW.Comma = '\t'
-		err = csvW.Write([]string{"Type", "TotalCost", "ServerfulCost", "ServerlessCost", "ServerfulPlatformCost", "ServerlessPlatformCost"})
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		err = csvW.WriteAll(data)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
+// CostBreakdown reports the serverless cost of each workload by its original and recommended spec, sorted by the original cost descending
+func (c *Comparator) CostBreakdown(costerCtx *coster.CosterContext) report.Section {
+	workloadCosts := coster.NewRecommenderCoster().WorkloadCosts(costerCtx)
+	sort.Slice(workloadCosts, func(i, j int) bool {
+		return workloadCosts[i].OriginalCost > workloadCosts[j].OriginalCost
+	})
+	var data report.CostBreakdown
+	for _, w := range workloadCosts {
+		data = append(data, report.WorkloadCost{
+			Kind:            w.Kind,
+			Namespace:       w.NamespacedName.Namespace,
+			Name:            w.NamespacedName.Name,
+			Replicas:        w.Replicas,
+			AverageReplicas: w.AverageReplicas,
+			StaticCost:      w.StaticCost,
+			OriginalCost:    w.OriginalCost,
+			RecommendedCost: w.RecommendedCost,
+		})
+	}
+	return report.Section{
+		Name:  report.SectionWorkloadsCostBreakdown,
+		Title: fmt.Sprintf("Workloads Serverless Cost Original versus Recommended(TimeSpan: %v, Discount: %v)", c.config.TimeSpanSeconds, c.config.Discount),
+		Data:  data,
 	}
-
-	fmt.Println()
 }
 
-func (c *Comparator) ReportRecommendedResourceSummary(costerCtx *coster.CosterContext) {
-	recomendedResourceTotal := ServerlessWorkloadsResourceTotal(costerCtx.WorkloadsRecSpec)
-
-	data := [][]string{
-		{"recomendedServerlessResourceTotal", Float642Str(float64(recomendedResourceTotal.Cpu().MilliValue()) / 1000.), Float642Str(float64(recomendedResourceTotal.Memory().Value()) / consts.GB)},
+// SavingsOpportunitiesSection reports the ranked savings of the workloads by the recommendation
+func (c *Comparator) SavingsOpportunitiesSection(costerCtx *coster.CosterContext) report.Section {
+	var data report.SavingsOpportunities
+	for i, o := range c.SavingsOpportunities(costerCtx) {
+		data = append(data, report.SavingsOpportunity{
+			Rank:            i + 1,
+			Kind:            o.Kind,
+			Namespace:       o.NamespacedName.Namespace,
+			Name:            o.NamespacedName.Name,
+			CurrentCost:     o.CurrentCost,
+			RecommendedCost: o.RecommendedCost,
+			Savings:         o.Savings,
+			SavingsRatio:    o.SavingsRatio,
+			Container:       o.Container,
+			Resource:        o.Resource,
+			Request:         o.Request,
+			Usage:           o.Usage,
+			Recommended:     o.Recommended,
+		})
 	}
-
-	fmt.Println("Reporting, Recommended Resource Summary After Migrating to Serverless.....................................................................")
-
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeStdOut {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeaderLine(true)
-		table.SetAutoFormatHeaders(false)
-		table.SetHeader([]string{"Type", "Cpu", "Mem"})
-		table.SetBorder(false)
-		// Set Border to false
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor})
-
-		table.SetColumnColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgGreenColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor})
-
-		table.AppendBulk(data) // Add Bulk Data
-		table.Render()
+	sortBy := c.config.Savings.SortBy
+	if sortBy == "" {
+		sortBy = config.SavingsSortBySavings
 	}
-
-	filename := filepath.Join(c.config.DataPath, c.config.ClusterId+"-recommended-serverless-resource-summary"+".csv")
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeCsv {
-		csvFile, err := os.Create(filename)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		csvW := csv.NewWriter(csvFile)
-		csvW.Comma = '\t'
-		err = csvW.Write([]string{"Type", "Cpu", "Mem"})
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		err = csvW.WriteAll(data)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
+	return report.Section{
+		Name:  report.SectionSavingsOpportunities,
+		Title: fmt.Sprintf("Workloads Savings Opportunities(TimeSpan: %v, Sort By: %v, Top: %v)", c.config.TimeSpanSeconds, sortBy, len(data)),
+		Data:  data,
 	}
-	fmt.Println()
 }
 
-func (c *Comparator) ReportRecommendedCostSummary(costerCtx *coster.CosterContext) {
-	recommendedCoster := coster.NewRecommenderCoster()
-	RecommendedCost, PercentileCost, MaxCost, MaxMarginCost := recommendedCoster.TotalCost(costerCtx)
-
-	data := [][]string{
-		{"eks-recommended-by-percentile-margin", Float642Str(RecommendedCost.TotalCost), Float642Str(RecommendedCost.WorkloadCost), Float642Str(RecommendedCost.PlatformCost)},
-		{"eks-recommended-by-percentile", Float642Str(PercentileCost.TotalCost), Float642Str(PercentileCost.WorkloadCost), Float642Str(PercentileCost.PlatformCost)},
-		{"eks-recommended-by-max-margin", Float642Str(MaxMarginCost.TotalCost), Float642Str(MaxMarginCost.WorkloadCost), Float642Str(MaxMarginCost.PlatformCost)},
-		{"eks-recommended-by-max", Float642Str(MaxCost.TotalCost), Float642Str(MaxCost.WorkloadCost), Float642Str(MaxCost.PlatformCost)},
+// ArmSavings reports the savings of the arm64 compatible workloads, it returns false if the pricing of the baseline cloud or the arm64 unit price is unknown
+func (c *Comparator) ArmSavings(costerCtx *coster.CosterContext) (report.Section, bool) {
+	pricing, err := c.baselineCloud.GetConfig()
+	if err != nil {
+		klog.Errorf("Failed to get custom pricing of baseline cloud: %v", err)
+		return report.Section{}, false
 	}
+	armCoster := coster.NewArmCoster(pricing)
+	savings, err := armCoster.Savings(costerCtx)
+	if err != nil {
+		klog.Warningf("Skip the arm savings: %v", err)
+		return report.Section{}, false
+	}
+	sort.Slice(savings, func(i, j int) bool {
+		return savings[i].Savings > savings[j].Savings
+	})
+
+	var data report.ArmSavings
+	for _, s := range savings {
+		data = append(data, report.ArmSaving{
+			Kind:           s.Kind,
+			Namespace:      s.NamespacedName.Namespace,
+			Name:           s.NamespacedName.Name,
+			Arch:           s.Arch,
+			InstanceFamily: s.InstanceFamily,
+			CurrentCost:    s.CurrentCost,
+			ArmCost:        s.ArmCost,
+			Savings:        s.Savings,
+		})
+	}
+	return report.Section{
+		Name:  report.SectionArmSavings,
+		Title: fmt.Sprintf("Savings of Migrating Arm64 Compatible Workloads to Arm Nodes(TimeSpan: %v)", c.config.TimeSpanSeconds),
+		Data:  data,
+	}, true
+}
 
-	fmt.Printf("Reporting, Recommended Cost Summary After Migrating to Serverless(TimeSpan: %v, Discount: %v).............................................\n", c.config.TimeSpanSeconds, c.config.Discount)
-
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeStdOut {
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeaderLine(true)
-		table.SetAutoFormatHeaders(false)
-		table.SetHeader([]string{"Type", "TotalCost", "WorkloadCost", "PlatformCost"})
-		table.SetBorder(false) // Set Border to false
-		table.SetHeaderColor(
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-			tablewriter.Colors{tablewriter.FgHiRedColor, tablewriter.Bold, tablewriter.BgBlackColor},
-		)
-
-		table.SetColumnColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgGreenColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgHiRedColor},
-		)
+func (c *Comparator) SensitivityMatrixSection(costerCtx *coster.CosterContext) report.Section {
+	var data report.SensitivityMatrix
+	for _, r := range c.SensitivityMatrix(costerCtx) {
+		data = append(data, report.Sensitivity{
+			Percentile:      r.Percentile,
+			MarginFraction:  r.MarginFraction,
+			TotalCost:       r.Cost.TotalCost,
+			WorkloadCost:    r.Cost.WorkloadCost,
+			Savings:         r.Savings,
+			CpuThrottleRisk: r.CpuThrottleRisk,
+			MemOOMRisk:      r.MemOOMRisk,
+		})
+	}
+	return report.Section{
+		Name:  report.SectionSensitivityMatrix,
+		Title: fmt.Sprintf("Sensitivity Matrix of Recommendation Parameters(TimeSpan: %v, Discount: %v)", c.config.TimeSpanSeconds, c.config.Discount),
+		Data:  data,
+	}
+}
 
-		table.AppendBulk(data) // Add Bulk Data
-		table.Render()
+// ProviderComparisonSection reports the cost of the workloads on the baseline cloud side by side with the target clouds
+func (c *Comparator) ProviderComparisonSection(costerCtx *coster.CosterContext) report.Section {
+	var data report.ProviderComparison
+	for _, r := range c.ProviderComparison(costerCtx) {
+		data = append(data, report.ProviderCost{
+			Target:       r.Target,
+			Provider:     r.Provider,
+			Mode:         r.Mode,
+			TotalCost:    r.Cost.TotalCost,
+			ComputeCost:  r.Cost.ServerfulCost + r.Cost.ServerlessCost,
+			PlatformCost: r.Cost.ServerfulPlatformCost + r.Cost.ServerlessPlatformCost,
+			Savings:      r.Savings,
+		})
+	}
+	return report.Section{
+		Name:  report.SectionProviderComparison,
+		Title: fmt.Sprintf("Cross Provider Cost Comparison(TimeSpan: %v, Discount: %v)", c.config.TimeSpanSeconds, c.config.Discount),
+		Data:  data,
 	}
+}
 
-	filename := filepath.Join(c.config.DataPath, c.config.ClusterId+"-recommended-cost-summary"+".csv")
-	if c.config.OutputMode == "" || c.config.OutputMode == config.OutputModeCsv {
-		csvFile, err := os.Create(filename)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		csvW := csv.NewWriter(csvFile)
-		csvW.Comma = '\t'
-		err = csvW.Write([]string{"Type", "TotalCost", "WorkloadCost", "PlatformCost"})
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
-		err = csvW.WriteAll(data)
-		if err != nil {
-			fmt.Println(err)
-			os.Exit(255)
-		}
+// NodeMixSection reports the cheapest node set found to run the recommended workloads on nodes
+func (c *Comparator) NodeMixSection(costerCtx *coster.CosterContext) report.Section {
+	candidates, prices := c.BinPackingCandidates()
+	result := coster.NewBinPackingCoster(candidates, prices).TotalCost(costerCtx)
+	data := report.NodeMix{
+		NodeCost:          result.NodeCost,
+		PlatformCost:      result.PlatformCost,
+		TotalCost:         result.TotalCost,
+		UnschedulablePods: result.UnschedulablePods,
+	}
+	for _, group := range result.Nodes {
+		data.Nodes = append(data.Nodes, report.NodeGroup{
+			InstanceType: group.InstanceType,
+			Cpu:          group.Cpu,
+			Mem:          group.Mem / consts.GB,
+			Count:        group.Count,
+			HourlyPrice:  group.HourlyPrice,
+			Cost:         group.Cost,
+		})
+	}
+	return report.Section{
+		Name:  report.SectionRecommendedNodeMix,
+		Title: fmt.Sprintf("Recommended Node Mix by Bin-Packing the Recommended Workloads(TimeSpan: %v, Unschedulable Pods: %v)", c.config.TimeSpanSeconds, result.UnschedulablePods),
+		Data:  data,
 	}
+}
 
-	fmt.Println()
+// BatchWorkloadsCostSection reports the serverful and serverless cost of the jobs and cronjobs, sorted by the monthly serverful cost descending
+func (c *Comparator) BatchWorkloadsCostSection(costerCtx *coster.CosterContext) report.Section {
+	var data report.BatchWorkloadsCost
+	for _, w := range coster.NewBatchCoster().WorkloadCosts(costerCtx) {
+		data = append(data, report.BatchWorkloadCost{
+			Kind:                  w.Kind,
+			Namespace:             w.NamespacedName.Namespace,
+			Name:                  w.NamespacedName.Name,
+			Schedule:              w.Schedule,
+			Runs:                  w.Runs,
+			PodHours:              w.PodHours,
+			ServerfulCost:         w.ServerfulCost,
+			ServerlessCost:        w.ServerlessCost,
+			MonthlyServerfulCost:  w.MonthlyServerfulCost,
+			MonthlyServerlessCost: w.MonthlyServerlessCost,
+		})
+	}
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i].MonthlyServerfulCost > data[j].MonthlyServerfulCost
+	})
+	return report.Section{
+		Name:  report.SectionBatchWorkloadsCost,
+		Title: fmt.Sprintf("Batch Workloads Cost by Pod Runtime(TimeSpan: %v, History: %v)", c.config.TimeSpanSeconds, c.config.History.Length),
+		Data:  data,
+	}
 }
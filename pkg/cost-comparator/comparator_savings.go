@@ -0,0 +1,148 @@
+package cost_comparator
+
+import (
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/consts"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/config"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/coster"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// SavingsOpportunity is the savings of a workload by applying the recommendation, the driver is the container resource
+// of the most savings, or of the most increase if the recommendation costs more. Cpu is cores and memory is GB
+type SavingsOpportunity struct {
+	Kind            string
+	NamespacedName  types.NamespacedName
+	CurrentCost     float64
+	RecommendedCost float64
+	Savings         float64
+	SavingsRatio    float64
+	Container       string
+	Resource        string
+	Request         float64
+	Usage           float64
+	Recommended     float64
+}
+
+// SavingsOpportunities returns the savings of the recommended workloads filtered, sorted and truncated by the savings config.
+// The costs are the serverless costs of the cost breakdown
+func (c *Comparator) SavingsOpportunities(costerCtx *coster.CosterContext) []SavingsOpportunity {
+	var cpuPrice, ramPrice float64
+	if pricing, err := c.baselineCloud.GetConfig(); err != nil {
+		klog.V(4).Infof("Failed to get custom pricing of baseline cloud, the savings drivers are by the relative cut: %v", err)
+	} else if pricing != nil {
+		cpuPrice, ramPrice = pricing.CpuHourlyPrice, pricing.RamGBHourlyPrice
+	}
+
+	savingsConfig := c.config.Savings
+	var results []SavingsOpportunity
+	for _, w := range coster.NewRecommenderCoster().WorkloadCosts(costerCtx) {
+		rec, ok := costerCtx.WorkloadsRecSpec[w.Kind][w.NamespacedName]
+		if !ok || rec == nil || strings.ToLower(w.Kind) == "daemonset" {
+			continue
+		}
+		o := SavingsOpportunity{
+			Kind:            w.Kind,
+			NamespacedName:  w.NamespacedName,
+			CurrentCost:     w.OriginalCost,
+			RecommendedCost: w.RecommendedCost,
+			Savings:         w.OriginalCost - w.RecommendedCost,
+		}
+		if o.CurrentCost > 0 {
+			o.SavingsRatio = o.Savings / o.CurrentCost
+		}
+		if o.Savings < savingsConfig.MinSavings || o.SavingsRatio < savingsConfig.MinSavingsRatio {
+			continue
+		}
+		if workloadSpec, ok := costerCtx.WorkloadsSpec[w.Kind][w.NamespacedName]; ok && workloadSpec.PodRef != nil {
+			setSavingsDriver(&o, workloadSpec.PodRef, rec, cpuPrice, ramPrice)
+		}
+		results = append(results, o)
+	}
+
+	sortSavingsOpportunities(results, savingsConfig.SortBy)
+	if savingsConfig.TopN > 0 && len(results) > savingsConfig.TopN {
+		results = results[:savingsConfig.TopN]
+	}
+	return results
+}
+
+// setSavingsDriver sets the container resource of the most valued cut of the request to the recommended, the cut is valued by the unit price,
+// or relative to the request if the unit prices are unknown
+func setSavingsDriver(o *SavingsOpportunity, pod *v1.Pod, rec *spec.WorkloadRecommendedData, cpuPrice, ramPrice float64) {
+	relative := cpuPrice <= 0 && ramPrice <= 0
+	found := false
+	var driverValue float64
+	for _, container := range pod.Spec.Containers {
+		containerRec := rec.Containers[container.Name]
+		if containerRec == nil {
+			continue
+		}
+		for _, r := range []struct {
+			name      string
+			statistic *spec.Statistic
+			request   float64
+			unit      float64
+			price     float64
+		}{
+			{name: v1.ResourceCPU.String(), statistic: containerRec.Cpu, request: cores(*container.Resources.Requests.Cpu()), unit: 1, price: cpuPrice},
+			{name: v1.ResourceMemory.String(), statistic: containerRec.Mem, request: gigaBytes(*container.Resources.Requests.Memory()), unit: consts.GB, price: ramPrice},
+		} {
+			if r.statistic == nil || r.statistic.Recommended == nil {
+				continue
+			}
+			recommended := *r.statistic.Recommended / r.unit
+			value := (r.request - recommended) * r.price
+			if relative {
+				value = 0
+				if r.request > 0 {
+					value = (r.request - recommended) / r.request
+				}
+			}
+			// the increase drives the workload costs more
+			if o.Savings < 0 {
+				value = -value
+			}
+			if found && value <= driverValue {
+				continue
+			}
+			found, driverValue = true, value
+			o.Container, o.Resource, o.Request, o.Recommended, o.Usage = container.Name, r.name, r.request, recommended, 0
+			if r.statistic.Percentile != nil {
+				o.Usage = *r.statistic.Percentile / r.unit
+			}
+		}
+	}
+}
+
+// sortSavingsOpportunities sorts by the column descending, the ties are sorted by the workload
+func sortSavingsOpportunities(opportunities []SavingsOpportunity, sortBy string) {
+	key := func(o SavingsOpportunity) float64 {
+		switch sortBy {
+		case config.SavingsSortBySavingsRatio:
+			return o.SavingsRatio
+		case config.SavingsSortByCurrentCost:
+			return o.CurrentCost
+		case config.SavingsSortByRecommendedCost:
+			return o.RecommendedCost
+		default:
+			return o.Savings
+		}
+	}
+	sort.Slice(opportunities, func(i, j int) bool {
+		ki, kj := key(opportunities[i]), key(opportunities[j])
+		if ki != kj {
+			return ki > kj
+		}
+		if opportunities[i].Kind != opportunities[j].Kind {
+			return opportunities[i].Kind < opportunities[j].Kind
+		}
+		return opportunities[i].NamespacedName.String() < opportunities[j].NamespacedName.String()
+	})
+}
@@ -0,0 +1,74 @@
+package cost_comparator
+
+import (
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	defaultcloud "github.com/gocrane/fadvisor/pkg/cloudproviders/default"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/config"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/coster"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+func TestSavingsOpportunities(t *testing.T) {
+	web := types.NamespacedName{Namespace: "shop", Name: "web"}
+	api := types.NamespacedName{Namespace: "shop", Name: "api"}
+	newContainer := func(name, cpu, mem string) v1.Container {
+		return v1.Container{Name: name, Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(mem)},
+		}}
+	}
+	newSpec := func(cpu, mem string, replicas uint64, containers ...v1.Container) spec.CloudPodSpec {
+		return spec.CloudPodSpec{Cpu: resource.MustParse(cpu), Mem: resource.MustParse(mem), GoodsNum: replicas, PodRef: &v1.Pod{Spec: v1.PodSpec{Containers: containers}}}
+	}
+	newRec := func(cpu, mem float64) *spec.ContainerRecommendedData {
+		return &spec.ContainerRecommendedData{Cpu: &spec.Statistic{Recommended: &cpu}, Mem: &spec.Statistic{Recommended: &mem}}
+	}
+	gi := float64(1 << 30)
+
+	c := &Comparator{
+		baselineCloud: defaultcloud.NewDefaultCloud(cloud.NewProviderConfig(&cloud.CustomPricing{CpuHourlyPrice: 1, RamGBHourlyPrice: 0.5}), &fakeCache{}),
+	}
+	costerCtx := &coster.CosterContext{
+		TimeSpanSeconds: 3600,
+		Pricer:          c.baselineCloud,
+		WorkloadsSpec: map[string]map[types.NamespacedName]spec.CloudPodSpec{"Deployment": {
+			// costs (2.1 + 6 * 0.5) * 2
+			web: newSpec("2100m", "6Gi", 2, newContainer("app", "2", "4Gi"), newContainer("sidecar", "100m", "2Gi")),
+			api: newSpec("1", "0", 1, newContainer("api", "1", "0")),
+		}},
+		WorkloadsRecSpec: map[string]map[types.NamespacedName]*spec.WorkloadRecommendedData{"Deployment": {
+			// the app cpu cut is 1.5, the sidecar memory cut is 0.5
+			web: {RecommendedSpec: newSpec("600m", "5Gi", 2), Containers: map[string]*spec.ContainerRecommendedData{
+				"app": newRec(0.5, 4*gi), "sidecar": newRec(0.1, gi),
+			}},
+			api: {RecommendedSpec: newSpec("500m", "0", 1), Containers: map[string]*spec.ContainerRecommendedData{"api": newRec(0.5, 0)}},
+		}},
+	}
+
+	results := c.SavingsOpportunities(costerCtx)
+	if len(results) != 2 || results[0].NamespacedName != web || results[1].NamespacedName != api {
+		t.Fatalf("expect web and api ranked by savings, got %+v", results)
+	}
+	if r := results[0]; math.Abs(r.Savings-4) > 1e-9 || r.Container != "app" || r.Resource != "cpu" || r.Request != 2 || r.Recommended != 0.5 {
+		t.Errorf("expect web savings 4 driven by app cpu, got %+v", r)
+	}
+
+	c.config.Savings = config.SavingsConfig{SortBy: config.SavingsSortBySavingsRatio}
+	if results := c.SavingsOpportunities(costerCtx); results[0].NamespacedName != api || results[0].SavingsRatio != 0.5 {
+		t.Errorf("expect api ranked first by savings ratio 0.5, got %+v", results)
+	}
+	c.config.Savings = config.SavingsConfig{MinSavings: 1}
+	if results := c.SavingsOpportunities(costerCtx); len(results) != 1 || results[0].NamespacedName != web {
+		t.Errorf("expect only web of savings more than 1, got %+v", results)
+	}
+	c.config.Savings = config.SavingsConfig{SortBy: config.SavingsSortByCurrentCost, TopN: 1}
+	if results := c.SavingsOpportunities(costerCtx); len(results) != 1 || results[0].NamespacedName != web {
+		t.Errorf("expect only the top web, got %+v", results)
+	}
+}
@@ -0,0 +1,104 @@
+package cost_comparator
+
+import (
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/coster"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/estimator"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// SensitivityResult is the recommended cost and risk of a recommendation setting
+type SensitivityResult struct {
+	Percentile     float64
+	MarginFraction float64
+	Cost           coster.RecommendedCost
+	// Savings is the original cost minus the recommended total cost
+	Savings float64
+	// CpuThrottleRisk and MemOOMRisk are the fraction of the samples above the recommendation
+	CpuThrottleRisk float64
+	MemOOMRisk      float64
+}
+
+// SensitivityMatrix reruns the estimation and the recommended coster over the grid of percentiles and margin fractions,
+// the cached time series are reused so it only costs computing
+func (c *Comparator) SensitivityMatrix(costerCtx *coster.CosterContext) []SensitivityResult {
+	originalCost := coster.NewServerfulCoster().TotalCost(costerCtx)
+	recommendedCoster := coster.NewRecommenderCoster()
+
+	var results []SensitivityResult
+	for _, percentile := range c.config.Sensitivity.Percentiles {
+		for _, marginFraction := range c.config.Sensitivity.MarginFractions {
+			workloadsRecs := c.getAllWorkloadRecommendedData(map[string]interface{}{
+				estimator.EstimateConfigPercentile:     percentile,
+				estimator.EstimateConfigMarginFraction: marginFraction,
+			})
+			ctx := *costerCtx
+			ctx.WorkloadsRecSpec = workloadsRecs
+			recCost, _, _, _ := recommendedCoster.TotalCost(&ctx)
+			cpuRisk, memRisk := c.recommendationRisk(workloadsRecs)
+			klog.V(4).Infof("Sensitivity of percentile %v, margin fraction %v: cost %v, cpu risk %v, mem risk %v", percentile, marginFraction, recCost.TotalCost, cpuRisk, memRisk)
+			results = append(results, SensitivityResult{
+				Percentile:      percentile,
+				MarginFraction:  marginFraction,
+				Cost:            recCost,
+				Savings:         originalCost.TotalCost - recCost.TotalCost,
+				CpuThrottleRisk: cpuRisk,
+				MemOOMRisk:      memRisk,
+			})
+		}
+	}
+	return results
+}
+
+// recommendationRisk return the fraction of the cpu and memory samples of all containers above their recommendation
+func (c *Comparator) recommendationRisk(workloadsRecs map[string]map[types.NamespacedName]*spec.WorkloadRecommendedData) (float64, float64) {
+	var cpuAbove, cpuTotal, memAbove, memTotal int
+	workloadsContainerData := c.GetWorkloadContainerData()
+	for kind, kindRecs := range workloadsRecs {
+		for nn, rec := range kindRecs {
+			for container, containerRec := range rec.Containers {
+				rawTsData, ok := workloadsContainerData[kind][nn][container]
+				if !ok || containerRec == nil {
+					continue
+				}
+				if containerRec.Cpu != nil && containerRec.Cpu.Recommended != nil {
+					above, total := samplesAbove(rawTsData.Cpu, *containerRec.Cpu.Recommended)
+					cpuAbove += above
+					cpuTotal += total
+				}
+				if containerRec.Mem != nil && containerRec.Mem.Recommended != nil {
+					above, total := samplesAbove(rawTsData.Mem, *containerRec.Mem.Recommended)
+					memAbove += above
+					memTotal += total
+				}
+			}
+		}
+	}
+	return fraction(cpuAbove, cpuTotal), fraction(memAbove, memTotal)
+}
+
+func samplesAbove(tsList []*common.TimeSeries, threshold float64) (int, int) {
+	above, total := 0, 0
+	for _, ts := range tsList {
+		if ts == nil {
+			continue
+		}
+		for _, sample := range ts.Samples {
+			total++
+			if sample.Value > threshold {
+				above++
+			}
+		}
+	}
+	return above, total
+}
+
+func fraction(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
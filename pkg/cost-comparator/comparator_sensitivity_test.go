@@ -0,0 +1,36 @@
+package cost_comparator
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/common"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+func TestRecommendationRisk(t *testing.T) {
+	nn := types.NamespacedName{Namespace: "default", Name: "nginx"}
+	cpu := common.NewTimeSeries()
+	mem := common.NewTimeSeries()
+	for i, v := range []float64{0.5, 1.0, 1.5, 2.0} {
+		cpu.AppendSample(int64(i*60), v)
+		mem.AppendSample(int64(i*60), v*1e9)
+	}
+	c := &Comparator{
+		containersTimeSeriesDataCache: map[string]map[types.NamespacedName]map[string]*RawContainerTimeSeriesData{
+			"Deployment": {nn: {"nginx": {Cpu: []*common.TimeSeries{cpu}, Mem: []*common.TimeSeries{mem}}}},
+		},
+	}
+	recCpu, recMem := 1.2, 2e9
+	recs := map[string]map[types.NamespacedName]*spec.WorkloadRecommendedData{
+		"Deployment": {nn: {Containers: map[string]*spec.ContainerRecommendedData{
+			"nginx": {Cpu: &spec.Statistic{Recommended: &recCpu}, Mem: &spec.Statistic{Recommended: &recMem}},
+		}}},
+	}
+
+	cpuRisk, memRisk := c.recommendationRisk(recs)
+	if cpuRisk != 0.5 || memRisk != 0 {
+		t.Errorf("expect cpu risk 0.5 and mem risk 0, got %v and %v", cpuRisk, memRisk)
+	}
+}
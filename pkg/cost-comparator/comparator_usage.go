@@ -0,0 +1,58 @@
+package cost_comparator
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+// workloadPodUsage returns the average usage of a pod of the workload by the fetched container time series, it is the sum of
+// the average usage of the containers of the pod. It returns nil if the usage of any container is unknown
+func (c *Comparator) workloadPodUsage(kind string, nn types.NamespacedName, pod *v1.Pod) v1.ResourceList {
+	containers := c.containersTimeSeriesDataCache[kind][nn]
+	if len(containers) == 0 || len(pod.Spec.Containers) == 0 {
+		return nil
+	}
+	var cpu, mem float64
+	for _, container := range pod.Spec.Containers {
+		data := containers[container.Name]
+		if data == nil {
+			return nil
+		}
+		cpuUsage, ok := averageUsage(data.Cpu)
+		if !ok {
+			return nil
+		}
+		memUsage, ok := averageUsage(data.Mem)
+		if !ok {
+			return nil
+		}
+		cpu += cpuUsage
+		mem += memUsage
+	}
+	return v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewMilliQuantity(int64(cpu*1000), resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity(int64(mem), resource.BinarySI),
+	}
+}
+
+// averageUsage returns the mean of the samples of the series, a series is the usage of a container of a pod of the workload
+func averageUsage(series []*common.TimeSeries) (float64, bool) {
+	var sum float64
+	var count int
+	for _, ts := range series {
+		if ts == nil {
+			continue
+		}
+		for _, sample := range ts.Samples {
+			sum += sample.Value
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
@@ -0,0 +1,81 @@
+package cost_comparator
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/crane/pkg/common"
+
+	"github.com/gocrane/fadvisor/pkg/cache"
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	defaultcloud "github.com/gocrane/fadvisor/pkg/cloudproviders/default"
+)
+
+func TestGetAllPodsSpecUsage(t *testing.T) {
+	newPod := func(name string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: v1.PodSpec{NodeName: "node", Containers: []v1.Container{{Name: "nginx", Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("2Gi")},
+			}}}},
+		}
+	}
+	pod, unknown := newPod("nginx-0"), newPod("standalone")
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status:     v1.NodeStatus{Capacity: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4"), v1.ResourceMemory: resource.MustParse("8Gi")}},
+	}
+	var clusterCache cache.Cache = &fakeCache{pods: []*v1.Pod{pod, unknown}, nodes: []*v1.Node{node}}
+	pricing := &cloud.CustomPricing{CpuHourlyPrice: 1, RamGBHourlyPrice: 0.5, PodAllocationMode: cloud.PodAllocationUsage}
+
+	owner := &unstructured.Unstructured{}
+	owner.SetKind("Deployment")
+	owner.SetNamespace("default")
+	owner.SetName("nginx")
+	// the series of two pods, the average usage is 2 cores and 1Gi
+	cpu0, cpu1 := common.NewTimeSeries(), common.NewTimeSeries()
+	cpu0.AppendSample(0, 1)
+	cpu0.AppendSample(60, 2)
+	cpu1.AppendSample(0, 3)
+	mem := common.NewTimeSeries()
+	mem.AppendSample(0, 1<<30)
+	c := &Comparator{
+		clusterCache:  clusterCache,
+		baselineCloud: defaultcloud.NewDefaultCloud(cloud.NewProviderConfig(pricing), clusterCache),
+		podWorkloads:  map[string]*unstructured.Unstructured{"default/nginx-0": owner},
+		containersTimeSeriesDataCache: map[string]map[types.NamespacedName]map[string]*RawContainerTimeSeriesData{
+			"Deployment": {{Namespace: "default", Name: "nginx"}: {"nginx": {Cpu: []*common.TimeSeries{cpu0, cpu1}, Mem: []*common.TimeSeries{mem}}}},
+		},
+	}
+
+	podsSpec := c.GetAllPodsSpec()
+	usage := podsSpec["default/nginx-0"].Usage
+	if usage.Cpu().MilliValue() != 2000 || usage.Memory().Value() != 1<<30 {
+		t.Errorf("expect usage of 2 cores and 1Gi, got %v", usage)
+	}
+	if podsSpec["default/standalone"].Usage != nil {
+		t.Errorf("expect unknown usage of the pod without workload data, got %v", podsSpec["default/standalone"].Usage)
+	}
+
+	// cpu 2 * 1 + ram 1 * 0.5 by usage, cpu 1 * 1 + ram 2 * 0.5 by requests if the usage is unknown
+	for key, expected := range map[string]float64{"default/nginx-0": 2.5, "default/standalone": 2} {
+		price, err := c.baselineCloud.PodPrice(podsSpec[key])
+		if err != nil {
+			t.Fatal(err)
+		}
+		cost, err := strconv.ParseFloat(price.Cost, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(cost-expected) > 1e-5 {
+			t.Errorf("expect pod %v cost %v, got %v", key, expected, cost)
+		}
+	}
+}
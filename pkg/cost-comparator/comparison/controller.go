@@ -0,0 +1,108 @@
+package comparison
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/apis/fadvisor/v1alpha1"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/report"
+)
+
+// DefaultInterval is the interval of the comparisons without interval
+const DefaultInterval = 24 * time.Hour
+
+// AnalyzeFunc runs a comparator analysis and reports the sections by the reporter
+type AnalyzeFunc func(ctx context.Context, reporter report.Reporter) error
+
+// Controller analyzes the cluster for the due CostComparisons periodically. The analysis is of the whole cluster,
+// so the comparisons due at the same time share one analysis.
+type Controller struct {
+	publisher       *Publisher
+	analyze         AnalyzeFunc
+	resync          time.Duration
+	timeSpanSeconds int64
+}
+
+func NewController(publisher *Publisher, analyze AnalyzeFunc, resync time.Duration, timeSpanSeconds int64) *Controller {
+	return &Controller{publisher: publisher, analyze: analyze, resync: resync, timeSpanSeconds: timeSpanSeconds}
+}
+
+// Run checks the comparisons every resync until ctx is done
+func (c *Controller) Run(ctx context.Context) {
+	klog.Infof("Starting cost comparison controller, resync %v", c.resync)
+	wait.UntilWithContext(ctx, c.reconcile, c.resync)
+}
+
+// RunOnce analyzes the cluster for the comparison regardless of its interval, it is used by a CronJob
+func (c *Controller) RunOnce(ctx context.Context, namespace, name string) error {
+	cc, err := c.publisher.Get(ctx, namespace, name)
+	if err != nil {
+		return fmt.Errorf("failed to get cost comparison %v/%v: %v", namespace, name, err)
+	}
+	return c.run(ctx, []*v1alpha1.CostComparison{cc})
+}
+
+func (c *Controller) reconcile(ctx context.Context) {
+	comparisons, err := c.publisher.List(ctx)
+	if err != nil {
+		klog.Errorf("Failed to list cost comparisons: %v", err)
+		return
+	}
+	now := time.Now()
+	var due []*v1alpha1.CostComparison
+	for _, cc := range comparisons {
+		if Due(cc, now) {
+			due = append(due, cc)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+	if err := c.run(ctx, due); err != nil {
+		klog.Errorf("Failed to run cost comparisons: %v", err)
+	}
+}
+
+// run runs an analysis and publishes the results to the comparisons
+func (c *Controller) run(ctx context.Context, comparisons []*v1alpha1.CostComparison) error {
+	for _, cc := range comparisons {
+		klog.Infof("Analyzing cost comparison %v", klog.KObj(cc))
+	}
+	collector := &Collector{}
+	runErr := c.analyze(ctx, collector)
+	if runErr != nil {
+		klog.Errorf("Failed to analyze the cluster: %v", runErr)
+	}
+	var firstErr error
+	for _, cc := range comparisons {
+		if err := c.publisher.Publish(ctx, cc, c.timeSpanSeconds, collector.Sections, runErr); err != nil {
+			klog.Errorf("Failed to publish cost comparison %v: %v", klog.KObj(cc), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if runErr != nil {
+		return runErr
+	}
+	return firstErr
+}
+
+// Due returns true if the comparison is not suspended and it is not analyzed in its interval
+func Due(cc *v1alpha1.CostComparison, now time.Time) bool {
+	if cc.Spec.Suspend {
+		return false
+	}
+	if cc.Status.LastRunTime == nil {
+		return true
+	}
+	interval := DefaultInterval
+	if cc.Spec.Interval != nil && cc.Spec.Interval.Duration > 0 {
+		interval = cc.Spec.Interval.Duration
+	}
+	return !now.Before(cc.Status.LastRunTime.Add(interval))
+}
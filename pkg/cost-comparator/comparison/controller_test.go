@@ -0,0 +1,180 @@
+package comparison
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/gocrane/fadvisor/pkg/apis/fadvisor/v1alpha1"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/report"
+)
+
+func newTestPublisher(t *testing.T, comparisons ...*v1alpha1.CostComparison) *Publisher {
+	var objs []runtime.Object
+	for _, cc := range comparisons {
+		obj, err := toUnstructured(cc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objs = append(objs, obj)
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{v1alpha1.CostComparisonGVR: "CostComparisonList"}, objs...)
+	return NewPublisher(client, kubefake.NewSimpleClientset())
+}
+
+func testSections(workloads int) []report.Section {
+	var breakdown report.CostBreakdown
+	for i := 0; i < workloads; i++ {
+		breakdown = append(breakdown, report.WorkloadCost{Kind: "Deployment", Namespace: "default", Name: fmt.Sprintf("app-%v", i), OriginalCost: 2, RecommendedCost: 1})
+	}
+	return []report.Section{
+		{Name: report.SectionOriginalCostSummary, Data: report.CostSummary{{Type: "tke", TotalCost: 10}}},
+		{Name: report.SectionServerlessCostSummary, Data: report.CostSummary{{Type: "eks", TotalCost: 8}}},
+		{Name: report.SectionRecommendedCostSummary, Data: report.RecommendedCostSummary{{Type: "eks-recommended-by-percentile-margin", TotalCost: 5}}},
+		{Name: report.SectionWorkloadsCostBreakdown, Data: breakdown},
+	}
+}
+
+func TestControllerRunOnce(t *testing.T) {
+	cc := &v1alpha1.CostComparison{ObjectMeta: metav1.ObjectMeta{Namespace: "crane-system", Name: "daily", UID: "uid"}}
+	publisher := newTestPublisher(t, cc)
+	// split the breakdown to several ConfigMaps
+	publisher.maxDataSize = 1024
+	workloads := 30
+	analyze := func(ctx context.Context, reporter report.Reporter) error {
+		for _, section := range testSections(workloads) {
+			if err := reporter.Report(section); err != nil {
+				return err
+			}
+		}
+		return reporter.Close()
+	}
+	controller := NewController(publisher, analyze, time.Minute, 3600)
+	if err := controller.RunOnce(context.Background(), "crane-system", "daily"); err != nil {
+		t.Fatal(err)
+	}
+
+	cc, err := publisher.Get(context.Background(), "crane-system", "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := cc.Status
+	if status.Phase != v1alpha1.CostComparisonSucceeded || status.LastSuccessfulTime == nil || status.Summary == nil {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.Summary.Original[0].TotalCost != 10 || status.Summary.Serverless[0].TotalCost != 8 || status.Summary.Recommended[0].TotalCost != 5 {
+		t.Errorf("unexpected summary %+v", status.Summary)
+	}
+	if len(status.Results) != 1 || len(status.Results[0].ConfigMaps) < 2 {
+		t.Fatalf("expect the breakdown split to ConfigMaps, got %+v", status.Results)
+	}
+	total := 0
+	for _, name := range status.Results[0].ConfigMaps {
+		configMap, err := publisher.kubeClient.CoreV1().ConfigMaps("crane-system").Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var items []report.WorkloadCost
+		if err := json.Unmarshal([]byte(configMap.Data[report.SectionWorkloadsCostBreakdown+".json"]), &items); err != nil {
+			t.Fatal(err)
+		}
+		if configMap.OwnerReferences[0].UID != "uid" {
+			t.Errorf("expect ConfigMap owned by the comparison")
+		}
+		total += len(items)
+	}
+	if total != workloads {
+		t.Errorf("expect %v workloads, got %v", workloads, total)
+	}
+
+	// the stale chunks are deleted when the section shrinks, the failed run keeps the last results
+	workloads = 1
+	if err := controller.RunOnce(context.Background(), "crane-system", "daily"); err != nil {
+		t.Fatal(err)
+	}
+	configMaps, _ := publisher.kubeClient.CoreV1().ConfigMaps("crane-system").List(context.Background(), metav1.ListOptions{})
+	if len(configMaps.Items) != 1 {
+		t.Errorf("expect stale ConfigMaps deleted, got %v", len(configMaps.Items))
+	}
+	controller.analyze = func(ctx context.Context, reporter report.Reporter) error {
+		return fmt.Errorf("datasource unavailable")
+	}
+	if err := controller.RunOnce(context.Background(), "crane-system", "daily"); err == nil {
+		t.Errorf("expect the analysis error")
+	}
+	cc, _ = publisher.Get(context.Background(), "crane-system", "daily")
+	if cc.Status.Phase != v1alpha1.CostComparisonFailed || cc.Status.Message != "datasource unavailable" || cc.Status.Summary == nil {
+		t.Errorf("unexpected status %+v", cc.Status)
+	}
+}
+
+func TestControllerRunOnceConflict(t *testing.T) {
+	cc := &v1alpha1.CostComparison{ObjectMeta: metav1.ObjectMeta{Namespace: "crane-system", Name: "daily", UID: "uid"}}
+	publisher := newTestPublisher(t, cc)
+	client := publisher.client.(*dynamicfake.FakeDynamicClient)
+	conflicts := 0
+	client.PrependReactor("update", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" || conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, errors.NewConflict(v1alpha1.CostComparisonGVR.GroupResource(), "daily", fmt.Errorf("modified"))
+	})
+	// the comparison is changed during the analysis
+	analyze := func(ctx context.Context, reporter report.Reporter) error {
+		obj, err := client.Resource(v1alpha1.CostComparisonGVR).Namespace("crane-system").Get(ctx, "daily", metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		obj.SetLabels(map[string]string{"team": "shop"})
+		if _, err := client.Resource(v1alpha1.CostComparisonGVR).Namespace("crane-system").Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		return reporter.Report(testSections(1)[0])
+	}
+	controller := NewController(publisher, analyze, time.Minute, 3600)
+	if err := controller.RunOnce(context.Background(), "crane-system", "daily"); err != nil {
+		t.Fatal(err)
+	}
+	cc, err := publisher.Get(context.Background(), "crane-system", "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conflicts != 1 || cc.Status.Phase != v1alpha1.CostComparisonSucceeded {
+		t.Errorf("expect the status updated after a conflict, got %v conflicts and status %+v", conflicts, cc.Status)
+	}
+	if cc.Labels["team"] != "shop" {
+		t.Errorf("expect the change during the analysis kept, got labels %v", cc.Labels)
+	}
+}
+
+func TestDue(t *testing.T) {
+	now := time.Now()
+	lastRun := metav1.NewTime(now.Add(-2 * time.Hour))
+	testCases := []struct {
+		name string
+		cc   v1alpha1.CostComparison
+		due  bool
+	}{
+		{name: "never run", due: true},
+		{name: "default interval", cc: v1alpha1.CostComparison{Status: v1alpha1.CostComparisonStatus{LastRunTime: &lastRun}}, due: false},
+		{name: "interval passed", cc: v1alpha1.CostComparison{Spec: v1alpha1.CostComparisonSpec{Interval: &metav1.Duration{Duration: time.Hour}}, Status: v1alpha1.CostComparisonStatus{LastRunTime: &lastRun}}, due: true},
+		{name: "suspended", cc: v1alpha1.CostComparison{Spec: v1alpha1.CostComparisonSpec{Suspend: true}}, due: false},
+	}
+	for _, tc := range testCases {
+		if due := Due(&tc.cc, now); due != tc.due {
+			t.Errorf("%v: expect due %v, got %v", tc.name, tc.due, due)
+		}
+	}
+}
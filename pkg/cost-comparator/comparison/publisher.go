@@ -0,0 +1,291 @@
+package comparison
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/apis/fadvisor/v1alpha1"
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/report"
+)
+
+const (
+	// LabelCostComparison is the label of the result ConfigMaps, the value is the name of the comparison
+	LabelCostComparison = "fadvisor.crane.io/cost-comparison"
+	// maxConfigMapDataSize is less than the 1MiB limit of a ConfigMap for the metadata
+	maxConfigMapDataSize = 900 * 1024
+)
+
+// Collector is a reporter collects the sections of an analysis, so they can be published to the comparisons
+type Collector struct {
+	Sections []report.Section
+}
+
+func (c *Collector) Report(section report.Section) error {
+	c.Sections = append(c.Sections, section)
+	return nil
+}
+
+func (c *Collector) Close() error {
+	return nil
+}
+
+// Publisher writes the results of an analysis to a CostComparison, the cost summary sections are stored in the status,
+// the other sections are stored in ConfigMaps owned by the comparison
+type Publisher struct {
+	client     dynamic.Interface
+	kubeClient kubernetes.Interface
+	// maxDataSize is the max size of the data of a ConfigMap, a larger section is split
+	maxDataSize int
+}
+
+func NewPublisher(client dynamic.Interface, kubeClient kubernetes.Interface) *Publisher {
+	return &Publisher{client: client, kubeClient: kubeClient, maxDataSize: maxConfigMapDataSize}
+}
+
+func (p *Publisher) Get(ctx context.Context, namespace, name string) (*v1alpha1.CostComparison, error) {
+	obj, err := p.client.Resource(v1alpha1.CostComparisonGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fromUnstructured(obj)
+}
+
+func (p *Publisher) List(ctx context.Context) ([]*v1alpha1.CostComparison, error) {
+	list, err := p.client.Resource(v1alpha1.CostComparisonGVR).Namespace(v1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var results []*v1alpha1.CostComparison
+	for i := range list.Items {
+		cc, err := fromUnstructured(&list.Items[i])
+		if err != nil {
+			klog.Errorf("Failed to convert cost comparison %v: %v", klog.KObj(&list.Items[i]), err)
+			continue
+		}
+		results = append(results, cc)
+	}
+	return results, nil
+}
+
+// Publish updates the status of the comparison by the sections, if runErr is not nil only the phase and message are updated
+// and the results of the last succeeded analysis are kept
+func (p *Publisher) Publish(ctx context.Context, cc *v1alpha1.CostComparison, timeSpanSeconds int64, sections []report.Section, runErr error) error {
+	now := metav1.NewTime(time.Now())
+	failed := func(err error) func(status *v1alpha1.CostComparisonStatus) {
+		return func(status *v1alpha1.CostComparisonStatus) {
+			status.LastRunTime = &now
+			status.Phase = v1alpha1.CostComparisonFailed
+			status.Message = err.Error()
+		}
+	}
+	if runErr != nil {
+		return p.updateStatus(ctx, cc, failed(runErr))
+	}
+
+	summary := &v1alpha1.CostSummary{TimeSpanSeconds: timeSpanSeconds}
+	var results []v1alpha1.ResultReference
+	for _, section := range sections {
+		switch section.Name {
+		case report.SectionOriginalCostSummary:
+			summary.Original = statusCosts(section.Data)
+			continue
+		case report.SectionServerlessCostSummary:
+			summary.Serverless = statusCosts(section.Data)
+			continue
+		case report.SectionRecommendedCostSummary:
+			if costs, ok := section.Data.(report.RecommendedCostSummary); ok {
+				for _, c := range costs {
+					summary.Recommended = append(summary.Recommended, v1alpha1.RecommendedCost(c))
+				}
+			}
+			continue
+		}
+		configMaps, err := p.writeSection(ctx, cc, section)
+		if err != nil {
+			if updateErr := p.updateStatus(ctx, cc, failed(err)); updateErr != nil {
+				klog.Errorf("Failed to update status of cost comparison %v: %v", klog.KObj(cc), updateErr)
+			}
+			return err
+		}
+		results = append(results, v1alpha1.ResultReference{Section: section.Name, ConfigMaps: configMaps})
+	}
+	p.deleteStaleConfigMaps(ctx, cc, results)
+
+	return p.updateStatus(ctx, cc, func(status *v1alpha1.CostComparisonStatus) {
+		status.LastRunTime = &now
+		status.Phase = v1alpha1.CostComparisonSucceeded
+		status.Message = ""
+		status.LastSuccessfulTime = &now
+		status.Summary = summary
+		status.Results = results
+	})
+}
+
+func statusCosts(data report.Table) []v1alpha1.Cost {
+	costs, ok := data.(report.CostSummary)
+	if !ok {
+		return nil
+	}
+	var results []v1alpha1.Cost
+	for _, c := range costs {
+		results = append(results, v1alpha1.Cost(c))
+	}
+	return results
+}
+
+// writeSection writes the section data as a json array to ConfigMaps, it returns the names of the ConfigMaps
+func (p *Publisher) writeSection(ctx context.Context, cc *v1alpha1.CostComparison, section report.Section) ([]string, error) {
+	data, err := json.Marshal(section.Data)
+	if err != nil {
+		return nil, err
+	}
+	var chunks [][]byte
+	if len(data) <= p.maxDataSize {
+		chunks = [][]byte{data}
+	} else {
+		chunks, err = splitJsonArray(data, p.maxDataSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split section %v: %v", section.Name, err)
+		}
+	}
+
+	var names []string
+	for i, chunk := range chunks {
+		name := cc.Name + "-" + section.Name
+		if len(chunks) > 1 {
+			name = fmt.Sprintf("%v-%v", name, i)
+		}
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cc.Namespace,
+				Labels:    map[string]string{LabelCostComparison: cc.Name},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: v1alpha1.SchemeGroupVersion.String(),
+					Kind:       v1alpha1.CostComparisonKind,
+					Name:       cc.Name,
+					UID:        cc.UID,
+				}},
+			},
+			Data: map[string]string{section.Name + ".json": string(chunk)},
+		}
+		if err := p.applyConfigMap(ctx, configMap); err != nil {
+			return nil, fmt.Errorf("failed to write ConfigMap %v: %v", klog.KObj(configMap), err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (p *Publisher) applyConfigMap(ctx context.Context, configMap *v1.ConfigMap) error {
+	configMaps := p.kubeClient.CoreV1().ConfigMaps(configMap.Namespace)
+	existing, err := configMaps.Get(ctx, configMap.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	existing = existing.DeepCopy()
+	existing.Labels = configMap.Labels
+	existing.OwnerReferences = configMap.OwnerReferences
+	existing.Data = configMap.Data
+	_, err = configMaps.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+// deleteStaleConfigMaps deletes the ConfigMaps of the last results not in the current results, such as the chunks of a shrunk section
+func (p *Publisher) deleteStaleConfigMaps(ctx context.Context, cc *v1alpha1.CostComparison, results []v1alpha1.ResultReference) {
+	current := make(map[string]bool)
+	for _, r := range results {
+		for _, name := range r.ConfigMaps {
+			current[name] = true
+		}
+	}
+	for _, r := range cc.Status.Results {
+		for _, name := range r.ConfigMaps {
+			if current[name] {
+				continue
+			}
+			err := p.kubeClient.CoreV1().ConfigMaps(cc.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				klog.Warningf("Failed to delete stale ConfigMap %v/%v: %v", cc.Namespace, name, err)
+			}
+		}
+	}
+}
+
+// updateStatus applies the mutate to the status of the latest comparison, the comparison maybe changed during the analysis,
+// so it is got again and the update is retried on conflict
+func (p *Publisher) updateStatus(ctx context.Context, cc *v1alpha1.CostComparison, mutate func(status *v1alpha1.CostComparisonStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := p.Get(ctx, cc.Namespace, cc.Name)
+		if err != nil {
+			return err
+		}
+		mutate(&latest.Status)
+		obj, err := toUnstructured(latest)
+		if err != nil {
+			return err
+		}
+		_, err = p.client.Resource(v1alpha1.CostComparisonGVR).Namespace(cc.Namespace).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// splitJsonArray splits a json array to arrays of which the size is at most maxSize, an element larger than maxSize is an error
+func splitJsonArray(data []byte, maxSize int) ([][]byte, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, err
+	}
+	var chunks [][]byte
+	chunk := []byte{'['}
+	for _, element := range elements {
+		if len(element)+2 > maxSize {
+			return nil, fmt.Errorf("element size %v exceeds %v", len(element), maxSize)
+		}
+		// one byte for the separator and one for the closing bracket
+		if len(chunk) > 1 && len(chunk)+len(element)+2 > maxSize {
+			chunks = append(chunks, append(chunk, ']'))
+			chunk = []byte{'['}
+		}
+		if len(chunk) > 1 {
+			chunk = append(chunk, ',')
+		}
+		chunk = append(chunk, element...)
+	}
+	return append(chunks, append(chunk, ']')), nil
+}
+
+func fromUnstructured(obj *unstructured.Unstructured) (*v1alpha1.CostComparison, error) {
+	cc := &v1alpha1.CostComparison{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), cc); err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+func toUnstructured(cc *v1alpha1.CostComparison) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cc)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetAPIVersion(v1alpha1.SchemeGroupVersion.String())
+	obj.SetKind(v1alpha1.CostComparisonKind)
+	return obj, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// BinPackingConfig is the config of packing the recommended workloads onto nodes
+type BinPackingConfig struct {
+	Enabled bool
+	// Candidates are the instance types besides the instance types of the cluster nodes
+	Candidates []BinPackingCandidate
+}
+
+// BinPackingCandidate is an instance type the recommended pods can be packed on
+type BinPackingCandidate struct {
+	InstanceType string            `json:"instanceType"`
+	Cpu          resource.Quantity `json:"cpu"`
+	Memory       resource.Quantity `json:"memory"`
+	// Allocatable is the resources for pods, it is the cpu and memory if empty
+	Allocatable v1.ResourceList   `json:"allocatable,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Taints      []v1.Taint        `json:"taints,omitempty"`
+	// HourlyPrice is the price of the instance type per hour, it is required because the candidate is not an instance of the cloud
+	HourlyPrice float64 `json:"hourlyPrice"`
+}
+
+// Node return a node of the candidate, so it is converted and priced same as the cluster nodes
+func (c BinPackingCandidate) Node() *v1.Node {
+	labels := map[string]string{v1.LabelInstanceTypeStable: c.InstanceType}
+	for k, v := range c.Labels {
+		labels[k] = v
+	}
+	capacity := v1.ResourceList{v1.ResourceCPU: c.Cpu, v1.ResourceMemory: c.Memory}
+	allocatable := capacity
+	if len(c.Allocatable) > 0 {
+		allocatable = c.Allocatable
+	}
+	node := &v1.Node{
+		Spec:   v1.NodeSpec{Taints: c.Taints},
+		Status: v1.NodeStatus{Capacity: capacity, Allocatable: allocatable},
+	}
+	node.Name = c.InstanceType
+	node.Labels = labels
+	return node
+}
+
+// LoadBinPackingCandidates loads the json array of the bin-packing candidates
+func LoadBinPackingCandidates(path string) ([]BinPackingCandidate, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []BinPackingCandidate
+	if err := json.Unmarshal(content, &candidates); err != nil {
+		return nil, fmt.Errorf("failed to parse bin-packing candidates %v: %v", path, err)
+	}
+	for i, c := range candidates {
+		if c.InstanceType == "" {
+			return nil, fmt.Errorf("bin-packing candidate %v has no instance type", i)
+		}
+		if c.Cpu.IsZero() || c.Memory.IsZero() {
+			return nil, fmt.Errorf("bin-packing candidate %v has no cpu or memory", c.InstanceType)
+		}
+		if c.HourlyPrice <= 0 {
+			return nil, fmt.Errorf("bin-packing candidate %v has no hourly price", c.InstanceType)
+		}
+	}
+	return candidates, nil
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"time"
+
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/estimator"
 )
 
 type Config struct {
@@ -9,12 +11,64 @@ type Config struct {
 	Discount                  float64
 	ClusterName               string
 	ClusterId                 string
+	ClusterLevel              string
 	OutputMode                string
 	History                   HistoryAnalyzeConfig
 	EnableContainerCheckpoint bool
 	EnableWorkloadTimeSeries  bool
 	EnableWorkloadCheckpoint  bool
 	DataPath                  string
+	// EnableBatch costs the jobs and cronjobs by the run time of their pods in the history window
+	EnableBatch bool
+	// DataSource is the name of the datasource, it is recorded in the checkpoints
+	DataSource string
+	// CheckpointMinCoverage is the minimal fraction of the history window a checkpoint covers to be reused
+	CheckpointMinCoverage float64
+	// CheckpointIncremental refreshes the range after the checkpoint end from the datasource instead of fetching the whole window
+	CheckpointIncremental bool
+	// Estimator is the estimator of the recommended resources, statistic or vpa
+	Estimator    string
+	VpaEstimator estimator.VpaEstimatorConfig
+	// EstimatorProfiles selects the estimator and its config of each workload, nil if no profiles
+	EstimatorProfiles *estimator.Profiles
+	Sensitivity       SensitivityConfig
+	Fetch             FetchConfig
+	BinPacking        BinPackingConfig
+	Savings           SavingsConfig
+	// Scope selects the pods and workloads analyzed. If it is not empty, the serverful cost is the node cost allocated to the pods in scope
+	Scope ScopeConfig
+	// TargetProviders are the cloud providers the workloads are compared on besides the baseline cloud
+	TargetProviders []TargetProvider
+}
+
+// FetchConfig is the config of fetching the time series from the datasource
+type FetchConfig struct {
+	// Concurrency is the number of workloads or containers fetched concurrently
+	Concurrency  int
+	QueryTimeout time.Duration
+	Retries      int
+	RetryBackoff time.Duration
+	// Deadline stops fetching and the analysis uses the partial data, no deadline if zero
+	Deadline         time.Duration
+	ProgressInterval time.Duration
+}
+
+// SensitivityConfig is the grid of the recommendation parameters of the sensitivity matrix
+type SensitivityConfig struct {
+	Enabled         bool
+	Percentiles     []float64
+	MarginFractions []float64
+}
+
+// SavingsConfig is the ranking of the workloads savings opportunities
+type SavingsConfig struct {
+	// SortBy is the column the opportunities are sorted by descending
+	SortBy string
+	// TopN is the number of the opportunities reported, all if zero
+	TopN int
+	// MinSavings and MinSavingsRatio filter out the opportunities of less absolute and relative savings
+	MinSavings      float64
+	MinSavingsRatio float64
 }
 
 type HistoryAnalyzeConfig struct {
@@ -26,4 +80,18 @@ type HistoryAnalyzeConfig struct {
 const (
 	OutputModeCsv    = "csv"
 	OutputModeStdOut = "stdout"
+	OutputModeJson   = "json"
+	OutputModeHtml   = "html"
+)
+
+const (
+	SavingsSortBySavings         = "savings"
+	SavingsSortBySavingsRatio    = "savings-ratio"
+	SavingsSortByCurrentCost     = "current-cost"
+	SavingsSortByRecommendedCost = "recommended-cost"
+)
+
+const (
+	EstimatorStatistic = "statistic"
+	EstimatorVpa       = "vpa"
 )
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ScopeConfig selects the pods and workloads the comparator analyzes, all of them are analyzed if it is empty.
+// An object is in scope if it matches any include filter and no exclude filter of each dimension
+type ScopeConfig struct {
+	// Namespaces and ExcludeNamespaces are the globs of the namespaces, such as team-*
+	Namespaces        []string
+	ExcludeNamespaces []string
+	// Selector selects the pods by labels, nil selects all. ExcludeSelector excludes the pods it selects, nil excludes none
+	Selector        labels.Selector
+	ExcludeSelector labels.Selector
+	// Kinds and ExcludeKinds are the kinds of the root workloads, such as Deployment, case insensitive
+	Kinds        []string
+	ExcludeKinds []string
+}
+
+// Empty returns true if the scope selects all the pods and workloads
+func (s *ScopeConfig) Empty() bool {
+	return len(s.Namespaces) == 0 && len(s.ExcludeNamespaces) == 0 && (s.Selector == nil || s.Selector.Empty()) &&
+		(s.ExcludeSelector == nil || s.ExcludeSelector.Empty()) && len(s.Kinds) == 0 && len(s.ExcludeKinds) == 0
+}
+
+// ValidateGlobs returns error if a namespace glob is malformed
+func (s *ScopeConfig) ValidateGlobs() error {
+	for _, pattern := range append(append([]string{}, s.Namespaces...), s.ExcludeNamespaces...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid namespace glob %v: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// NamespaceInScope returns true if the namespace matches an include glob or there are no include globs, and matches no exclude glob
+func (s *ScopeConfig) NamespaceInScope(namespace string) bool {
+	if len(s.Namespaces) > 0 && !matchAnyGlob(s.Namespaces, namespace) {
+		return false
+	}
+	return !matchAnyGlob(s.ExcludeNamespaces, namespace)
+}
+
+// LabelsInScope returns true if the labels match the selector and do not match the exclude selector
+func (s *ScopeConfig) LabelsInScope(set map[string]string) bool {
+	if s.Selector != nil && !s.Selector.Matches(labels.Set(set)) {
+		return false
+	}
+	return s.ExcludeSelector == nil || s.ExcludeSelector.Empty() || !s.ExcludeSelector.Matches(labels.Set(set))
+}
+
+// KindInScope returns true if the kind is an include kind or there are no include kinds, and is not an exclude kind
+func (s *ScopeConfig) KindInScope(kind string) bool {
+	if len(s.Kinds) > 0 && !containsFold(s.Kinds, kind) {
+		return false
+	}
+	return !containsFold(s.ExcludeKinds, kind)
+}
+
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestScopeConfig(t *testing.T) {
+	scope := ScopeConfig{
+		Namespaces:        []string{"shop-*", "payment"},
+		ExcludeNamespaces: []string{"shop-test*"},
+		Selector:          labels.SelectorFromSet(labels.Set{"team": "shop"}),
+		ExcludeSelector:   labels.SelectorFromSet(labels.Set{"tier": "canary"}),
+		ExcludeKinds:      []string{"daemonset"},
+	}
+	for namespace, expected := range map[string]bool{"shop-web": true, "payment": true, "shop-test-1": false, "kube-system": false} {
+		if scope.NamespaceInScope(namespace) != expected {
+			t.Errorf("expect namespace %v in scope %v", namespace, expected)
+		}
+	}
+	if !scope.LabelsInScope(map[string]string{"team": "shop"}) || scope.LabelsInScope(map[string]string{"team": "shop", "tier": "canary"}) ||
+		scope.LabelsInScope(map[string]string{"team": "payment"}) {
+		t.Errorf("expect only the non canary pods of team shop in scope")
+	}
+	if !scope.KindInScope("Deployment") || scope.KindInScope("DaemonSet") {
+		t.Errorf("expect the daemonsets out of scope")
+	}
+
+	// an empty scope selects all
+	empty := ScopeConfig{ExcludeSelector: labels.Everything()}
+	if !empty.Empty() || scope.Empty() || !empty.NamespaceInScope("kube-system") || !empty.LabelsInScope(nil) || !empty.KindInScope("Pod") {
+		t.Errorf("expect all in empty scope")
+	}
+	if err := (&ScopeConfig{Namespaces: []string{"shop-["}}).ValidateGlobs(); err == nil {
+		t.Errorf("expect error of malformed glob")
+	}
+}
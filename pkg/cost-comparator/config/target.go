@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/gocrane/fadvisor/pkg/cloud"
+)
+
+// TargetProvider is a cloud provider the cluster workloads are priced on besides the baseline cloud
+type TargetProvider struct {
+	// Name identifies the target in the report, such as aws-us-east-1
+	Name string `json:"name"`
+	// Provider is the registered cloud provider, default or qcloud
+	Provider        string `json:"provider"`
+	CloudConfigFile string `json:"cloudConfigFile,omitempty"`
+	// Pricing is the custom pricing of the target, the provider prices by it if it has no price api
+	Pricing cloud.CustomPricing `json:"pricing"`
+}
+
+// CloudConfig return the config to init the target cloud provider
+func (t TargetProvider) CloudConfig() cloud.CloudConfig {
+	return cloud.CloudConfig{Provider: t.Provider, CloudConfigFile: t.CloudConfigFile}
+}
+
+// LoadTargetProviders loads the json array of the target providers
+func LoadTargetProviders(path string) ([]TargetProvider, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var targets []TargetProvider
+	if err := json.Unmarshal(content, &targets); err != nil {
+		return nil, fmt.Errorf("failed to parse target providers %v: %v", path, err)
+	}
+	names := make(map[string]bool, len(targets))
+	for i := range targets {
+		t := &targets[i]
+		if t.Name == "" {
+			return nil, fmt.Errorf("target provider %v has no name", i)
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("duplicated target provider %v", t.Name)
+		}
+		names[t.Name] = true
+		if t.Provider == "" {
+			return nil, fmt.Errorf("target provider %v has no provider", t.Name)
+		}
+		if err := cloud.ValidatePodAllocationMode(t.Pricing.PodAllocationMode); err != nil {
+			return nil, fmt.Errorf("target provider %v: %v", t.Name, err)
+		}
+		mode, err := cloud.NormalizeNodeOverheadMode(t.Pricing.NodeOverheadMode)
+		if err != nil {
+			return nil, fmt.Errorf("target provider %v: %v", t.Name, err)
+		}
+		t.Pricing.NodeOverheadMode = mode
+	}
+	return targets, nil
+}
@@ -0,0 +1,126 @@
+package coster
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	"github.com/gocrane/fadvisor/pkg/consts"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// ArmSavings is the estimated cost of a workload on its current nodes and on arm64 nodes
+type ArmSavings struct {
+	Kind           string
+	NamespacedName types.NamespacedName
+	Arch           string
+	InstanceFamily string
+	CurrentCost    float64
+	ArmCost        float64
+	Savings        float64
+}
+
+// arm coster estimates the savings of moving the arm64 compatible workloads to arm nodes.
+// the current cost is priced by the cpu and ram breakdown price of the node the workload runs on, and the arm cost by the arm64 unit price.
+// a workload is arm64 compatible if the image platforms annotation of the pod or workload template includes arm64.
+type arm struct {
+	pricing *cloud.CustomPricing
+}
+
+func NewArmCoster(pricing *cloud.CustomPricing) *arm {
+	return &arm{pricing: pricing}
+}
+
+// Savings return error if the arm64 unit price is not configured, the savings are meaningless without it
+func (a *arm) Savings(costerCtx *CosterContext) ([]ArmSavings, error) {
+	var results []ArmSavings
+	if a.pricing == nil {
+		return results, fmt.Errorf("no custom pricing")
+	}
+	armPrice, ok := a.pricing.ArchUnitPrices[cloud.ArchARM64]
+	if !ok {
+		return results, fmt.Errorf("no %v unit price in archUnitPrices", cloud.ArchARM64)
+	}
+	timespanInHour := float64(costerCtx.TimeSpanSeconds) / time.Hour.Seconds()
+	nodeUnitPrices := make(map[string][2]float64)
+	for kind, workloadsSpec := range costerCtx.WorkloadsSpec {
+		for nn, workloadSpec := range workloadsSpec {
+			if workloadSpec.Serverless || workloadSpec.PodRef == nil || !ArmCompatible(workloadSpec) {
+				continue
+			}
+			nodeName := workloadSpec.PodRef.Spec.NodeName
+			nodeSpec, ok := costerCtx.NodesSpec[nodeName]
+			if !ok || nodeSpec.VirtualNode || nodeSpec.Arch == cloud.ArchARM64 {
+				continue
+			}
+			unitPrices, ok := nodeUnitPrices[nodeName]
+			if !ok {
+				unitPrices[0], unitPrices[1] = a.nodeUnitPrice(costerCtx, nodeName, nodeSpec)
+				nodeUnitPrices[nodeName] = unitPrices
+			}
+			current := workloadUnitCost(workloadSpec, unitPrices[0], unitPrices[1]) * timespanInHour
+			armCost := workloadUnitCost(workloadSpec, armPrice.CpuHourlyPrice, armPrice.RamGBHourlyPrice) * timespanInHour
+			results = append(results, ArmSavings{
+				Kind:           kind,
+				NamespacedName: nn,
+				Arch:           nodeSpec.Arch,
+				InstanceFamily: nodeSpec.InstanceFamily,
+				CurrentCost:    current,
+				ArmCost:        armCost,
+				Savings:        current - armCost,
+			})
+		}
+	}
+	return results, nil
+}
+
+// nodeUnitPrice return the cpu core and ram GB hourly price of the node by its price breakdown, the custom unit prices are used if the node is not priced
+func (a *arm) nodeUnitPrice(costerCtx *CosterContext, name string, nodeSpec spec.CloudNodeSpec) (float64, float64) {
+	if costerCtx.Pricer != nil {
+		nodePricing, err := costerCtx.Pricer.NodePrice(nodeSpec)
+		if err != nil {
+			klog.Warningf("Failed to get node %v price, use the custom unit price: %v", name, err)
+			return a.pricing.UnitPrices(nodeSpec.InstanceFamily, nodeSpec.Arch)
+		}
+		cpuPrice, cpuErr := strconv.ParseFloat(nodePricing.CpuHourlyCost, 64)
+		ramPrice, ramErr := strconv.ParseFloat(nodePricing.RamGBHourlyCost, 64)
+		if cpuErr == nil && ramErr == nil && !math.IsNaN(cpuPrice) && !math.IsNaN(ramPrice) {
+			return cpuPrice, ramPrice
+		}
+		klog.Warningf("Node %v price has no cpu and ram breakdown, use the custom unit price", name)
+	}
+	return a.pricing.UnitPrices(nodeSpec.InstanceFamily, nodeSpec.Arch)
+}
+
+// ArmCompatible return true if the images of the workload support arm64
+func ArmCompatible(podSpec spec.CloudPodSpec) bool {
+	var platforms string
+	if podSpec.PodRef != nil {
+		platforms = podSpec.PodRef.Annotations[consts.AnnotationImagePlatforms]
+	}
+	if platforms == "" && podSpec.Workload != nil {
+		annotations, _, _ := unstructured.NestedStringMap(podSpec.Workload.Object, "spec", "template", "metadata", "annotations")
+		platforms = annotations[consts.AnnotationImagePlatforms]
+	}
+	for _, platform := range strings.Split(platforms, ",") {
+		// linux/arm64 or linux/arm64/v8
+		parts := strings.Split(strings.TrimSpace(platform), "/")
+		if len(parts) >= 2 && parts[1] == cloud.ArchARM64 {
+			return true
+		}
+	}
+	return false
+}
+
+func workloadUnitCost(podSpec spec.CloudPodSpec, cpuPrice, ramPrice float64) float64 {
+	cpu := float64(podSpec.Cpu.MilliValue()) / 1000.
+	mem := float64(podSpec.Mem.Value()) / consts.GB
+	return (cpu*cpuPrice + mem*ramPrice) * float64(podSpec.GoodsNum)
+}
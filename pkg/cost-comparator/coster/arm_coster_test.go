@@ -0,0 +1,105 @@
+package coster
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	"github.com/gocrane/fadvisor/pkg/consts"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// familyPricer prices the nodes by the cpu and ram price breakdown of the instance family, the nodes of other families have the total cost only
+type familyPricer struct {
+	instanceTypePricer
+	breakdown map[string]cloud.UnitPrice
+}
+
+func (p familyPricer) NodePrice(spec spec.CloudNodeSpec) (*cloud.Node, error) {
+	price, ok := p.breakdown[spec.InstanceFamily]
+	if !ok {
+		return &cloud.Node{BaseInstancePrice: cloud.BaseInstancePrice{Cost: "1"}}, nil
+	}
+	return &cloud.Node{BaseInstancePrice: cloud.BaseInstancePrice{
+		CpuHourlyCost:   fmt.Sprintf("%v", price.CpuHourlyPrice),
+		RamGBHourlyCost: fmt.Sprintf("%v", price.RamGBHourlyPrice),
+	}}, nil
+}
+
+func TestArmCosterSavings(t *testing.T) {
+	pricing := &cloud.CustomPricing{
+		CpuHourlyPrice:   0.1,
+		RamGBHourlyPrice: 0.05,
+		ArchUnitPrices: map[string]cloud.UnitPrice{
+			"SA3":           {CpuHourlyPrice: 0.08, RamGBHourlyPrice: 0.04},
+			cloud.ArchARM64: {CpuHourlyPrice: 0.06, RamGBHourlyPrice: 0.03},
+		},
+	}
+	newWorkload := func(node string, podAnnotations map[string]string, template map[string]interface{}) spec.CloudPodSpec {
+		podSpec := spec.CloudPodSpec{
+			PodRef: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Annotations: podAnnotations},
+				Spec:       v1.PodSpec{NodeName: node},
+			},
+			Cpu:      resource.MustParse("2"),
+			Mem:      resource.MustParse("4Gi"),
+			GoodsNum: 2,
+		}
+		if template != nil {
+			podSpec.Workload = &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{"template": map[string]interface{}{"metadata": map[string]interface{}{"annotations": template}}},
+			}}
+		}
+		return podSpec
+	}
+	costerCtx := &CosterContext{
+		TimeSpanSeconds: 3600,
+		Pricer:          familyPricer{breakdown: map[string]cloud.UnitPrice{"S5": {CpuHourlyPrice: 0.12, RamGBHourlyPrice: 0.06}}},
+		NodesSpec: map[string]spec.CloudNodeSpec{
+			"intel": {Arch: cloud.ArchAMD64, InstanceFamily: "S5"},
+			"amd":   {Arch: cloud.ArchAMD64, InstanceFamily: "SA3"},
+			"arm":   {Arch: cloud.ArchARM64, InstanceFamily: "SR1"},
+		},
+		WorkloadsSpec: map[string]map[types.NamespacedName]spec.CloudPodSpec{
+			"Deployment": {
+				{Namespace: "default", Name: "intel"}:    newWorkload("intel", map[string]string{consts.AnnotationImagePlatforms: "linux/amd64,linux/arm64/v8"}, nil),
+				{Namespace: "default", Name: "amd"}:      newWorkload("amd", nil, map[string]interface{}{consts.AnnotationImagePlatforms: "linux/arm64"}),
+				{Namespace: "default", Name: "amd64"}:    newWorkload("intel", map[string]string{consts.AnnotationImagePlatforms: "linux/amd64"}, nil),
+				{Namespace: "default", Name: "arm"}:      newWorkload("arm", map[string]string{consts.AnnotationImagePlatforms: "linux/arm64"}, nil),
+				{Namespace: "default", Name: "no-annos"}: newWorkload("intel", nil, nil),
+			},
+		},
+	}
+
+	savings, err := NewArmCoster(pricing).Savings(costerCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(savings) != 2 {
+		t.Fatalf("expect 2 arm compatible workloads, got %+v", savings)
+	}
+	expected := map[string]float64{
+		// the node price breakdown, (2*0.12 + 4*0.06 - 2*0.06 - 4*0.03) * 2
+		"intel": 0.48,
+		// the node has no price breakdown, the family unit price, (2*0.08 + 4*0.04 - 2*0.06 - 4*0.03) * 2
+		"amd": 0.16,
+	}
+	for _, s := range savings {
+		if math.Abs(s.Savings-expected[s.NamespacedName.Name]) > 1e-9 {
+			t.Errorf("expect savings %v of %v, got %v", expected[s.NamespacedName.Name], s.NamespacedName, s.Savings)
+		}
+	}
+
+	// the savings are meaningless without the arm64 unit price
+	delete(pricing.ArchUnitPrices, cloud.ArchARM64)
+	if _, err := NewArmCoster(pricing).Savings(costerCtx); err == nil {
+		t.Errorf("expect error without the arm64 unit price")
+	}
+}
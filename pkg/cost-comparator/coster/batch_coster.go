@@ -0,0 +1,129 @@
+package coster
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/gocrane/fadvisor/pkg/consts"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// BatchMonth is the time span of the monthly batch costs
+const BatchMonth = 30 * 24 * time.Hour
+
+// BatchWorkload is a job or cronjob costed by the run time of its pods in the history window
+type BatchWorkload struct {
+	Kind           string
+	NamespacedName types.NamespacedName
+	// Schedule is the schedule of a cronjob, empty for a job
+	Schedule string
+	// PodSpec is the spec of a pod in the real node and ServerlessPodSpec is the spec of a serverless pod, GoodsNum is 1
+	PodSpec           spec.CloudPodSpec
+	ServerlessPodSpec spec.CloudPodSpec
+	// Runs is the number of the jobs run in the history window, PodSeconds is the run seconds of all their completed pods
+	Runs       int
+	PodSeconds float64
+	// WindowSeconds is the length of the history window the pods are observed
+	WindowSeconds float64
+	// MonthlyRuns is the runs in a month by the schedule, if it is negative the run rate of the history window is projected
+	MonthlyRuns float64
+}
+
+// BatchCost is the cost of a batch workload in the time span and in a month, serverful by the unit price of the nodes and serverless
+type BatchCost struct {
+	Kind                  string
+	NamespacedName        types.NamespacedName
+	Schedule              string
+	Runs                  int
+	PodHours              float64
+	ServerfulCost         float64
+	ServerlessCost        float64
+	MonthlyServerfulCost  float64
+	MonthlyServerlessCost float64
+}
+
+type batch struct {
+}
+
+func NewBatchCoster() *batch {
+	return &batch{}
+}
+
+// WorkloadCosts returns the costs of the batch workloads. The serverful pod hour is priced by the capacity weighted cpu and ram unit price
+// of the real nodes, the nodes without the unit prices are priced by cpu. The cost in the time span is at the pod hours rate of the history window
+func (b *batch) WorkloadCosts(costerCtx *CosterContext) []BatchCost {
+	cpuHourlyPrice, ramGBHourlyPrice := nodesUnitPrice(costerCtx)
+	timespanInHour := float64(costerCtx.TimeSpanSeconds) / time.Hour.Seconds()
+	monthInHour := BatchMonth.Hours()
+
+	var results []BatchCost
+	for _, workload := range costerCtx.BatchWorkloads {
+		cpu, mem := float64(workload.PodSpec.Cpu.MilliValue())/1000., float64(workload.PodSpec.Mem.Value())
+		serverfulHourlyPrice := cpuHourlyPrice*cpu + ramGBHourlyPrice*mem/consts.GB
+		serverlessHourlyPrice := workloadCosting(costerCtx.Pricer, 1, workload.ServerlessPodSpec, workload.NamespacedName, workload.Kind)
+
+		podHours := workload.PodSeconds / time.Hour.Seconds()
+		// the pod hours per hour of the history window
+		podHoursRate := 0.
+		if workload.WindowSeconds > 0 {
+			podHoursRate = podHours / (workload.WindowSeconds / time.Hour.Seconds())
+		}
+		monthlyPodHours := podHoursRate * monthInHour
+		if workload.MonthlyRuns >= 0 {
+			monthlyPodHours = 0
+			if workload.Runs > 0 {
+				monthlyPodHours = podHours / float64(workload.Runs) * workload.MonthlyRuns
+			} else if workload.MonthlyRuns > 0 {
+				klog.V(4).Infof("No runs of %v %v in the history window, its monthly cost is unknown", workload.Kind, workload.NamespacedName)
+			}
+		}
+
+		results = append(results, BatchCost{
+			Kind:                  workload.Kind,
+			NamespacedName:        workload.NamespacedName,
+			Schedule:              workload.Schedule,
+			Runs:                  workload.Runs,
+			PodHours:              podHours,
+			ServerfulCost:         serverfulHourlyPrice * podHoursRate * timespanInHour,
+			ServerlessCost:        serverlessHourlyPrice * podHoursRate * timespanInHour,
+			MonthlyServerfulCost:  serverfulHourlyPrice * monthlyPodHours,
+			MonthlyServerlessCost: serverlessHourlyPrice * monthlyPodHours,
+		})
+	}
+	return results
+}
+
+// nodesUnitPrice returns the cpu core and ram GB hourly price of the real nodes weighted by their capacity
+func nodesUnitPrice(costerCtx *CosterContext) (float64, float64) {
+	var cpuCost, ramCost, totalCpu, totalRam float64
+	for name, nodeSpec := range costerCtx.NodesSpec {
+		if nodeSpec.VirtualNode {
+			continue
+		}
+		nodePricing, err := costerCtx.Pricer.NodePrice(nodeSpec)
+		if err != nil {
+			klog.Errorf("Failed to get node %v price: %v", name, err)
+			continue
+		}
+		cpu, ram := float64(nodeSpec.Cpu.MilliValue())/1000., float64(nodeSpec.Mem.Value())/consts.GB
+		cpuPrice, cpuErr := strconv.ParseFloat(nodePricing.CpuHourlyCost, 64)
+		ramPrice, ramErr := strconv.ParseFloat(nodePricing.RamGBHourlyCost, 64)
+		if cpuErr != nil || ramErr != nil || math.IsNaN(cpuPrice) || math.IsNaN(ramPrice) {
+			nodePrice, err := strconv.ParseFloat(nodePricing.Cost, 64)
+			if err != nil || math.IsNaN(nodePrice) || cpu <= 0 {
+				klog.V(3).Infof("Could not parse node price, node: %v", name)
+				continue
+			}
+			cpuPrice, ramPrice = nodePrice/cpu, 0
+		}
+		cpuCost += cpuPrice * cpu
+		ramCost += ramPrice * ram
+		totalCpu += cpu
+		totalRam += ram
+	}
+	return share(cpuCost, totalCpu), share(ramCost, totalRam)
+}
@@ -0,0 +1,56 @@
+package coster
+
+import (
+	"math"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+func TestBatchWorkloadCosts(t *testing.T) {
+	newSpec := func(cpu string) spec.CloudPodSpec {
+		return spec.CloudPodSpec{Cpu: resource.MustParse(cpu), GoodsNum: 1}
+	}
+	day := float64(24 * 3600)
+	costerCtx := &CosterContext{
+		TimeSpanSeconds: 3600,
+		Pricer:          coresPricer{instanceTypePricer{"small": 1}},
+		// the node has no unit prices, it is 0.5 per core per hour
+		NodesSpec: map[string]spec.CloudNodeSpec{"node": {InstanceType: "small", Cpu: resource.MustParse("2")}},
+		BatchWorkloads: []BatchWorkload{
+			// 4 runs of 1 pod hour in a day, it runs daily
+			{Kind: "CronJob", NamespacedName: types.NamespacedName{Name: "backup"}, Schedule: "0 2 * * *", PodSpec: newSpec("2"), ServerlessPodSpec: newSpec("2"),
+				Runs: 4, PodSeconds: 4 * 3600, WindowSeconds: day, MonthlyRuns: 30},
+			// a run of 2 pod hours in a day, the rate is projected
+			{Kind: "Job", NamespacedName: types.NamespacedName{Name: "migrate"}, PodSpec: newSpec("1"), ServerlessPodSpec: newSpec("1"),
+				Runs: 1, PodSeconds: 2 * 3600, WindowSeconds: day, MonthlyRuns: -1},
+		},
+	}
+
+	expected := map[string]BatchCost{
+		"backup":  {PodHours: 4, ServerfulCost: 1. / 6, ServerlessCost: 2. / 6, MonthlyServerfulCost: 30, MonthlyServerlessCost: 60},
+		"migrate": {PodHours: 2, ServerfulCost: 0.5 / 12, ServerlessCost: 1. / 12, MonthlyServerfulCost: 30, MonthlyServerlessCost: 60},
+	}
+	costs := NewBatchCoster().WorkloadCosts(costerCtx)
+	if len(costs) != len(expected) {
+		t.Fatalf("expect %v costs, got %v", len(expected), len(costs))
+	}
+	for _, c := range costs {
+		e := expected[c.NamespacedName.Name]
+		for _, v := range [][2]float64{
+			{c.PodHours, e.PodHours},
+			{c.ServerfulCost, e.ServerfulCost},
+			{c.ServerlessCost, e.ServerlessCost},
+			{c.MonthlyServerfulCost, e.MonthlyServerfulCost},
+			{c.MonthlyServerlessCost, e.MonthlyServerlessCost},
+		} {
+			if math.Abs(v[0]-v[1]) > 1e-9 {
+				t.Errorf("expect %+v, got %+v", e, c)
+				break
+			}
+		}
+	}
+}
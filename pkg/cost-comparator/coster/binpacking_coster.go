@@ -0,0 +1,297 @@
+package coster
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// NodeGroup is the nodes of an instance type in the simulated node set
+type NodeGroup struct {
+	InstanceType string
+	// Cpu is the cores and Mem is the bytes of the instance type
+	Cpu         float64
+	Mem         float64
+	Count       int
+	HourlyPrice float64
+	Cost        float64
+}
+
+// BinPackingResult is the cheapest node set found to run the recommended workloads
+type BinPackingResult struct {
+	Nodes        []NodeGroup
+	NodeCost     float64
+	PlatformCost float64
+	TotalCost    float64
+	// UnschedulablePods is the number of the recommended pods no candidate can run
+	UnschedulablePods int
+}
+
+// binPacking packs the recommended pods onto the candidate instance types, the nodes are kept instead of migrating to serverless
+type binPacking struct {
+	candidates []spec.CloudNodeSpec
+	prices     map[string]float64
+}
+
+// NewBinPackingCoster returns a coster packs onto the candidates, the taints, labels and allocatable of a candidate are of its NodeRef.
+// prices are the hourly prices by instance type, the candidates not in it are priced by the pricer
+func NewBinPackingCoster(candidates []spec.CloudNodeSpec, prices map[string]float64) *binPacking {
+	return &binPacking{candidates: candidates, prices: prices}
+}
+
+// candidate is a priced instance type, the free resources are the allocatable minus the daemonset pods on it
+type candidate struct {
+	spec        spec.CloudNodeSpec
+	hourlyPrice float64
+	freeCpu     float64
+	freeMem     float64
+}
+
+type packItem struct {
+	workload types.NamespacedName
+	cpu      float64
+	mem      float64
+	// size is the normalized resources of the item, the cpu and mem shares of all the items
+	size float64
+	// feasible is the candidates the item can run on
+	feasible []bool
+}
+
+type packNode struct {
+	candidate int
+	freeCpu   float64
+	freeMem   float64
+}
+
+// TotalCost simulates placing the recommended pods of the workloads, GoodsNum replicas of each, onto the candidates and prices the node set.
+// The daemonset pods are the overhead of every node they can run on. A greedy heuristic is used: a pod is placed on the open node it fits best,
+// if no open node fits, the candidate of the lowest price per resources packed is opened
+func (b *binPacking) TotalCost(costerCtx *CosterContext) BinPackingResult {
+	candidates := b.priceCandidates(costerCtx)
+	items := b.packItems(costerCtx, candidates)
+
+	var result BinPackingResult
+	var nodes []*packNode
+	for i, item := range items {
+		if !anyFeasible(item.feasible) {
+			klog.Warningf("No candidate instance type can run the recommended pod of workload %v, cpu: %v, mem: %v", item.workload, item.cpu, item.mem)
+			result.UnschedulablePods++
+			continue
+		}
+		if node := bestFitNode(nodes, item); node != nil {
+			node.freeCpu -= item.cpu
+			node.freeMem -= item.mem
+			continue
+		}
+		c := cheapestCandidate(candidates, items[i:])
+		nodes = append(nodes, &packNode{candidate: c, freeCpu: candidates[c].freeCpu - item.cpu, freeMem: candidates[c].freeMem - item.mem})
+	}
+
+	timespanInHour := float64(costerCtx.TimeSpanSeconds) / time.Hour.Seconds()
+	groups := make(map[int]*NodeGroup)
+	for _, node := range nodes {
+		group, ok := groups[node.candidate]
+		if !ok {
+			c := candidates[node.candidate]
+			group = &NodeGroup{
+				InstanceType: c.spec.InstanceType,
+				Cpu:          float64(c.spec.Cpu.MilliValue()) / 1000.,
+				Mem:          float64(c.spec.Mem.Value()),
+				HourlyPrice:  c.hourlyPrice,
+			}
+			groups[node.candidate] = group
+		}
+		group.Count++
+		group.Cost += group.HourlyPrice * timespanInHour
+	}
+	for _, group := range groups {
+		result.Nodes = append(result.Nodes, *group)
+		result.NodeCost += group.Cost
+	}
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].Cost > result.Nodes[j].Cost
+	})
+	nodesNum := int32(len(nodes))
+	result.PlatformCost = costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{Nodes: &nodesNum, ClusterLevel: costerCtx.ClusterLevel, Platform: cloud.ServerfulKind}).TotalPrice
+	result.TotalCost = result.NodeCost + result.PlatformCost
+	return result
+}
+
+// priceCandidates prices the candidates and subtracts the daemonset overhead, the candidates failed to price are never opened
+func (b *binPacking) priceCandidates(costerCtx *CosterContext) []candidate {
+	var daemonSetPods []*v1.Pod
+	for kind, workloads := range costerCtx.WorkloadsRecSpec {
+		if strings.ToLower(kind) != "daemonset" {
+			continue
+		}
+		for _, workload := range workloads {
+			if workload.RecommendedSpec.PodRef != nil {
+				daemonSetPods = append(daemonSetPods, workload.RecommendedSpec.PodRef)
+			}
+		}
+	}
+
+	candidates := make([]candidate, 0, len(b.candidates))
+	for _, nodeSpec := range b.candidates {
+		c := candidate{spec: nodeSpec, hourlyPrice: math.Inf(1)}
+		if price, ok := b.prices[nodeSpec.InstanceType]; ok {
+			c.hourlyPrice = price
+		} else if nodePricing, err := costerCtx.Pricer.NodePrice(nodeSpec); err != nil {
+			klog.Errorf("Failed to get price of candidate instance type %v: %v", nodeSpec.InstanceType, err)
+		} else if price, err := strconv.ParseFloat(nodePricing.Cost, 64); err != nil || math.IsNaN(price) {
+			klog.Errorf("Could not parse price of candidate instance type %v: %v", nodeSpec.InstanceType, nodePricing.Cost)
+		} else {
+			c.hourlyPrice = price
+		}
+		c.freeCpu, c.freeMem = float64(nodeSpec.Cpu.MilliValue())/1000., float64(nodeSpec.Mem.Value())
+		if nodeSpec.NodeRef != nil && len(nodeSpec.NodeRef.Status.Allocatable) > 0 {
+			c.freeCpu = float64(nodeSpec.NodeRef.Status.Allocatable.Cpu().MilliValue()) / 1000.
+			c.freeMem = float64(nodeSpec.NodeRef.Status.Allocatable.Memory().Value())
+		}
+		for _, pod := range daemonSetPods {
+			if schedulable(pod, nodeSpec.NodeRef) {
+				cpu, mem := podRequests(pod)
+				c.freeCpu -= cpu
+				c.freeMem -= mem
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// packItems returns the recommended pods of the workloads except daemonsets, sorted by the size descending
+func (b *binPacking) packItems(costerCtx *CosterContext, candidates []candidate) []packItem {
+	var items []packItem
+	var totalCpu, totalMem float64
+	for kind, workloads := range costerCtx.WorkloadsRecSpec {
+		if strings.ToLower(kind) == "daemonset" {
+			continue
+		}
+		for nn, workload := range workloads {
+			recSpec := workload.RecommendedSpec
+			cpu, mem := float64(recSpec.Cpu.MilliValue())/1000., float64(recSpec.Mem.Value())
+			if recSpec.PodRef != nil {
+				cpu, mem = podRequests(recSpec.PodRef)
+			}
+			feasible := make([]bool, len(candidates))
+			for i, c := range candidates {
+				feasible[i] = !math.IsInf(c.hourlyPrice, 1) && cpu <= c.freeCpu && mem <= c.freeMem && schedulable(recSpec.PodRef, c.spec.NodeRef)
+			}
+			for i := uint64(0); i < recSpec.GoodsNum; i++ {
+				items = append(items, packItem{workload: nn, cpu: cpu, mem: mem, feasible: feasible})
+				totalCpu += cpu
+				totalMem += mem
+			}
+		}
+	}
+	for i := range items {
+		items[i].size = share(items[i].cpu, totalCpu) + share(items[i].mem, totalMem)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].size > items[j].size
+	})
+	return items
+}
+
+// bestFitNode returns the open node the item fits with the least free resources left, nil if it fits no open node
+func bestFitNode(nodes []*packNode, item packItem) *packNode {
+	var best *packNode
+	bestLeft := math.Inf(1)
+	for _, node := range nodes {
+		if !item.feasible[node.candidate] || item.cpu > node.freeCpu || item.mem > node.freeMem {
+			continue
+		}
+		// relative to the node, so the dimension with less left weighs more
+		left := share(node.freeCpu-item.cpu, node.freeCpu) + share(node.freeMem-item.mem, node.freeMem)
+		if left < bestLeft {
+			best, bestLeft = node, left
+		}
+	}
+	return best
+}
+
+// cheapestCandidate returns the candidate of the lowest price per size packed when opening a node for items[0],
+// the node is filled with the following items first fit to estimate the size packed
+func cheapestCandidate(candidates []candidate, items []packItem) int {
+	cheapest := -1
+	bestRatio := math.Inf(1)
+	for c := range candidates {
+		if !items[0].feasible[c] {
+			continue
+		}
+		freeCpu, freeMem := candidates[c].freeCpu, candidates[c].freeMem
+		packed := 0.
+		for _, item := range items {
+			if item.feasible[c] && item.cpu <= freeCpu && item.mem <= freeMem {
+				freeCpu -= item.cpu
+				freeMem -= item.mem
+				packed += item.size
+			}
+		}
+		ratio := candidates[c].hourlyPrice / math.Max(packed, math.SmallestNonzeroFloat64)
+		if cheapest < 0 || ratio < bestRatio {
+			cheapest, bestRatio = c, ratio
+		}
+	}
+	return cheapest
+}
+
+// schedulable returns true if the pod tolerates the NoSchedule and NoExecute taints of the node and the node matches its node selector
+func schedulable(pod *v1.Pod, node *v1.Node) bool {
+	if pod == nil || node == nil {
+		return true
+	}
+	if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+func podRequests(pod *v1.Pod) (float64, float64) {
+	reqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+	return float64(reqs.Cpu().MilliValue()) / 1000., float64(reqs.Memory().Value())
+}
+
+func anyFeasible(feasible []bool) bool {
+	for _, f := range feasible {
+		if f {
+			return true
+		}
+	}
+	return false
+}
+
+func share(a, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return a / total
+}
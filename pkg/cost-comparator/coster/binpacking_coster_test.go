@@ -0,0 +1,107 @@
+package coster
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// instanceTypePricer prices the nodes by the instance type and the platform by the nodes number
+type instanceTypePricer map[string]float64
+
+func (p instanceTypePricer) NodePrice(spec spec.CloudNodeSpec) (*cloud.Node, error) {
+	price, ok := p[spec.InstanceType]
+	if !ok {
+		return nil, fmt.Errorf("unknown instance type %v", spec.InstanceType)
+	}
+	return &cloud.Node{BaseInstancePrice: cloud.BaseInstancePrice{Cost: fmt.Sprintf("%v", price)}}, nil
+}
+
+func (p instanceTypePricer) ServerlessPodPrice(spec spec.CloudPodSpec) (*cloud.Pod, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (p instanceTypePricer) PodPrice(spec spec.CloudPodSpec) (*cloud.Pod, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func (p instanceTypePricer) PlatformPrice(cp cloud.PlatformParameter) *cloud.Prices {
+	return &cloud.Prices{TotalPrice: 0.1 * float64(*cp.Nodes)}
+}
+
+func TestBinPackingTotalCost(t *testing.T) {
+	gpuTaint := v1.Taint{Key: "gpu", Value: "true", Effect: v1.TaintEffectNoSchedule}
+	newCandidate := func(instanceType, cpu, mem string, taints ...v1.Taint) spec.CloudNodeSpec {
+		capacity := v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(mem)}
+		return spec.CloudNodeSpec{
+			InstanceType: instanceType,
+			Cpu:          capacity[v1.ResourceCPU],
+			Mem:          capacity[v1.ResourceMemory],
+			NodeRef:      &v1.Node{Spec: v1.NodeSpec{Taints: taints}, Status: v1.NodeStatus{Capacity: capacity, Allocatable: capacity}},
+		}
+	}
+	newWorkload := func(cpu, mem string, replicas uint64, tolerations ...v1.Toleration) *spec.WorkloadRecommendedData {
+		pod := &v1.Pod{Spec: v1.PodSpec{Tolerations: tolerations, Containers: []v1.Container{{Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu), v1.ResourceMemory: resource.MustParse(mem)},
+		}}}}}
+		return &spec.WorkloadRecommendedData{RecommendedSpec: spec.CloudPodSpec{PodRef: pod, GoodsNum: replicas}}
+	}
+
+	costerCtx := &CosterContext{
+		TimeSpanSeconds: 3600,
+		Pricer:          instanceTypePricer{"small": 1, "large": 3, "gpu": 2},
+		WorkloadsRecSpec: map[string]map[types.NamespacedName]*spec.WorkloadRecommendedData{
+			// the overhead of small and large, it does not tolerate the gpu taint
+			"DaemonSet": {{Name: "agent"}: newWorkload("500m", "1Gi", 3)},
+			"Deployment": {
+				{Name: "web"}:   newWorkload("1", "2Gi", 6),
+				{Name: "train"}: newWorkload("4", "8Gi", 2, v1.Toleration{Key: "gpu", Operator: v1.TolerationOpExists}),
+				{Name: "huge"}:  newWorkload("16", "32Gi", 1),
+			},
+		},
+	}
+	candidates := []spec.CloudNodeSpec{
+		newCandidate("small", "2", "4Gi"),
+		newCandidate("large", "8", "16Gi"),
+		newCandidate("gpu", "8", "16Gi", gpuTaint),
+	}
+	result := NewBinPackingCoster(candidates, nil).TotalCost(costerCtx)
+
+	// the trains fill a gpu node, the webs fit a large node but one per small node
+	counts := make(map[string]int)
+	for _, group := range result.Nodes {
+		counts[group.InstanceType] = group.Count
+	}
+	if len(counts) != 2 || counts["gpu"] != 1 || counts["large"] != 1 {
+		t.Errorf("expect a gpu and a large node, got %+v", result.Nodes)
+	}
+	if result.UnschedulablePods != 1 {
+		t.Errorf("expect the huge pod unschedulable, got %v", result.UnschedulablePods)
+	}
+	if math.Abs(result.NodeCost-5) > 1e-9 || math.Abs(result.PlatformCost-0.2) > 1e-9 || math.Abs(result.TotalCost-5.2) > 1e-9 {
+		t.Errorf("expect node cost 5 and platform cost 0.2, got %+v", result)
+	}
+
+	// the configured candidate is priced by its hourly price, the pricer can not price it
+	candidates = append(candidates, newCandidate("configured", "32", "64Gi"))
+	result = NewBinPackingCoster(candidates, map[string]float64{"configured": 4}).TotalCost(costerCtx)
+	if result.UnschedulablePods != 0 {
+		t.Errorf("expect the huge pod on the configured candidate, got %v unschedulable", result.UnschedulablePods)
+	}
+	configured := false
+	for _, group := range result.Nodes {
+		if group.InstanceType == "configured" {
+			configured = group.HourlyPrice == 4
+		}
+	}
+	if !configured {
+		t.Errorf("expect a configured node of hourly price 4, got %+v", result.Nodes)
+	}
+}
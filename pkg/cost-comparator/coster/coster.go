@@ -17,6 +17,27 @@ type CosterContext struct {
 	WorkloadsSpec    map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ spec.CloudPodSpec
 	WorkloadsRecSpec map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ *spec.WorkloadRecommendedData
 	Pricer           cloud.Pricer
+	// ClusterLevel is the level of the managed cluster, such as L5 for tke, it is used to price the platform fee
+	ClusterLevel *string
+	// WorkloadsReplicas is the time weighted average replicas of the workloads over the history window. The serverless costs of
+	// the workloads in it are the replica hours instead of the replicas at analysis time, so the hpa scaled workloads are costed right
+	WorkloadsReplicas map[string] /*kind*/ map[types.NamespacedName] /*namespace-name*/ float64
+	// BatchWorkloads are the jobs and cronjobs, they are not in WorkloadsSpec and are costed by the run time of their pods
+	BatchWorkloads []BatchWorkload
+	// NodeCostByPods prices the real nodes by the cost allocated to the pods in PodsSpec instead of the cost of all the nodes,
+	// it is set if the analysis is scoped, so the nodes of the pods out of scope are not charged
+	NodeCostByPods bool
+}
+
+// replicasScaled returns the spec of one replica and the average replicas of the workload if its replicas history is known,
+// otherwise it returns the spec and 1
+func (c *CosterContext) replicasScaled(kind string, nn types.NamespacedName, podSpec spec.CloudPodSpec) (spec.CloudPodSpec, float64) {
+	replicas, ok := c.WorkloadsReplicas[kind][nn]
+	if !ok {
+		return podSpec, 1
+	}
+	podSpec.GoodsNum = 1
+	return podSpec, replicas
 }
 
 type Cost struct {
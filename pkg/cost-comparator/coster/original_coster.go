@@ -7,6 +7,7 @@ import (
 
 	"github.com/gocrane/fadvisor/pkg/cloud"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 )
 
@@ -22,9 +23,11 @@ func NewServerfulCoster() *serverful {
 func (s *serverful) TotalCost(costerCtx *CosterContext) Cost {
 	nodeTotalCost := 0.
 	var realNodesNum int32 = 0
+	var virtualNodesNum int32 = 0
 	timespanInHour := float64(costerCtx.TimeSpanSeconds) / time.Hour.Seconds()
 	for name, nodeSpec := range costerCtx.NodesSpec {
 		if nodeSpec.VirtualNode {
+			virtualNodesNum++
 			continue
 		}
 		realNodesNum++
@@ -48,6 +51,16 @@ func (s *serverful) TotalCost(costerCtx *CosterContext) Cost {
 		nodeTotalCost += nodePrice * timespanInHour
 	}
 
+	// the platform fee is of the whole cluster, a scoped run is charged by the share of the node cost allocated to the pods in scope
+	platformShare := 1.
+	if costerCtx.NodeCostByPods {
+		clusterNodeCost := nodeTotalCost
+		nodeTotalCost = podsNodeCost(costerCtx, timespanInHour)
+		if clusterNodeCost > 0 {
+			platformShare = math.Min(nodeTotalCost/clusterNodeCost, 1)
+		}
+	}
+
 	serverlessPodsTotalCost := 0.
 	for name, podSpec := range costerCtx.PodsSpec {
 		if !podSpec.Serverless {
@@ -73,14 +86,38 @@ func (s *serverful) TotalCost(costerCtx *CosterContext) Cost {
 		}
 		serverlessPodsTotalCost += podPrice * timespanInHour
 	}
-	serverfulPlatformCost := costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{Nodes: &realNodesNum, Platform: cloud.ServerfulKind})
-	serverlessPlatformCost := costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{Nodes: &realNodesNum, Platform: cloud.ServerlessKind})
+	serverfulPlatformCost := costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{Nodes: &realNodesNum, ClusterLevel: costerCtx.ClusterLevel, Platform: cloud.ServerfulKind})
+	serverlessPlatformCost := costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{Nodes: &virtualNodesNum, ClusterLevel: costerCtx.ClusterLevel, Platform: cloud.ServerlessKind})
+	serverfulPlatformTotal := serverfulPlatformCost.TotalPrice * platformShare
 
 	return Cost{
-		TotalCost:              nodeTotalCost + serverlessPodsTotalCost + serverfulPlatformCost.TotalPrice + serverlessPlatformCost.TotalPrice,
+		TotalCost:              nodeTotalCost + serverlessPodsTotalCost + serverfulPlatformTotal + serverlessPlatformCost.TotalPrice,
 		ServerfulCost:          nodeTotalCost,
 		ServerlessCost:         serverlessPodsTotalCost,
-		ServerfulPlatformCost:  serverfulPlatformCost.TotalPrice,
+		ServerfulPlatformCost:  serverfulPlatformTotal,
 		ServerlessPlatformCost: serverlessPlatformCost.TotalPrice,
 	}
 }
+
+// podsNodeCost returns the node cost allocated to the running pods in the real nodes, the idle resources of the nodes are not charged
+func podsNodeCost(costerCtx *CosterContext, timespanInHour float64) float64 {
+	total := 0.
+	for name, podSpec := range costerCtx.PodsSpec {
+		if podSpec.Serverless || podSpec.PodRef == nil || podSpec.PodRef.Spec.NodeName == "" ||
+			podSpec.PodRef.Status.Phase == v1.PodSucceeded || podSpec.PodRef.Status.Phase == v1.PodFailed {
+			continue
+		}
+		podPricing, err := costerCtx.Pricer.PodPrice(podSpec)
+		if err != nil {
+			klog.Errorf("Failed to get pod %v price: %v", name, err)
+			continue
+		}
+		podPrice, err := strconv.ParseFloat(podPricing.Cost, 64)
+		if err != nil || math.IsNaN(podPrice) {
+			klog.V(3).Infof("Could not parse pod price, pod: %v, cost: %v", name, podPricing.Cost)
+			continue
+		}
+		total += podPrice * timespanInHour
+	}
+	return total
+}
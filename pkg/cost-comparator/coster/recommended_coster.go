@@ -30,8 +30,7 @@ func (e *recommender) TotalCost(costerCtx *CosterContext) (RecommendedCost, Reco
 	for kind, workloadsSpec := range costerCtx.WorkloadsSpec {
 		workloadKindTotalCost[kind] = 0
 		for nn, workloadSpec := range workloadsSpec {
-			workloadPrice := workloadCosting(costerCtx.Pricer, timespanInHour, workloadSpec, nn, kind)
-			workloadCost := workloadPrice * timespanInHour
+			workloadCost := replicasCosting(costerCtx, timespanInHour, workloadSpec, nn, kind)
 
 			workloadKindTotalCost[kind] += workloadCost
 			serverlessPodsTotalCost += workloadCost
@@ -50,32 +49,28 @@ func (e *recommender) TotalCost(costerCtx *CosterContext) (RecommendedCost, Reco
 			continue
 		}
 		for nn, workloadRecSpec := range workloadsRecSpec {
-			recWorkloadPrice := workloadCosting(costerCtx.Pricer, timespanInHour, workloadRecSpec.RecommendedSpec, nn, kind)
-			workloadCost := recWorkloadPrice * timespanInHour
+			workloadCost := replicasCosting(costerCtx, timespanInHour, workloadRecSpec.RecommendedSpec, nn, kind)
 			recWorkloadKindTotalCost[kind] += workloadCost
 			recServerlessPodsTotalCost += workloadCost
 
 			if workloadRecSpec.MaxRecommendedSpec != nil {
-				recMaxWorkloadPrice := workloadCosting(costerCtx.Pricer, timespanInHour, *workloadRecSpec.MaxRecommendedSpec, nn, kind)
-				recMaxWorkloadCost := recMaxWorkloadPrice * timespanInHour
+				recMaxWorkloadCost := replicasCosting(costerCtx, timespanInHour, *workloadRecSpec.MaxRecommendedSpec, nn, kind)
 				maxRecServerlessPodsTotalCost += recMaxWorkloadCost
 			}
 
 			if workloadRecSpec.MaxMarginRecommendedSpec != nil {
-				recMaxMarginWorkloadPrice := workloadCosting(costerCtx.Pricer, timespanInHour, *workloadRecSpec.MaxMarginRecommendedSpec, nn, kind)
-				recMaxMarginWorkloadCost := recMaxMarginWorkloadPrice * timespanInHour
+				recMaxMarginWorkloadCost := replicasCosting(costerCtx, timespanInHour, *workloadRecSpec.MaxMarginRecommendedSpec, nn, kind)
 				maxMarginServerlessPodsTotalCost += recMaxMarginWorkloadCost
 			}
 
 			if workloadRecSpec.PercentRecommendedSpec != nil {
-				percentWorkloadPrice := workloadCosting(costerCtx.Pricer, timespanInHour, *workloadRecSpec.PercentRecommendedSpec, nn, kind)
-				percentWorkloadPriceCost := percentWorkloadPrice * timespanInHour
+				percentWorkloadPriceCost := replicasCosting(costerCtx, timespanInHour, *workloadRecSpec.PercentRecommendedSpec, nn, kind)
 				percentServerlessPodsTotalCost += percentWorkloadPriceCost
 			}
 		}
 	}
 
-	platformCost := costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{Platform: cloud.ServerlessKind})
+	platformCost := costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{ClusterLevel: costerCtx.ClusterLevel, Platform: cloud.ServerlessKind})
 
 	recCost := RecommendedCost{
 		TotalCost:    recServerlessPodsTotalCost + platformCost.TotalPrice,
@@ -104,6 +99,50 @@ func (e *recommender) TotalCost(costerCtx *CosterContext) (RecommendedCost, Reco
 	return recCost, percentCost, maxRecCost, maxMarginCost
 }
 
+// WorkloadCost is the serverless cost of a workload by its original spec and by its recommended spec.
+// StaticCost is the original cost by the replicas at analysis time, the costs are by the average replicas if the replicas history is known
+type WorkloadCost struct {
+	Kind            string
+	NamespacedName  types.NamespacedName
+	Replicas        uint64
+	AverageReplicas float64
+	StaticCost      float64
+	OriginalCost    float64
+	RecommendedCost float64
+}
+
+// WorkloadCosts returns the original and recommended cost of each workload, they are computed the same way as TotalCost
+func (e *recommender) WorkloadCosts(costerCtx *CosterContext) []WorkloadCost {
+	timespanInHour := float64(costerCtx.TimeSpanSeconds) / time.Hour.Seconds()
+	var results []WorkloadCost
+	for kind, workloadsSpec := range costerCtx.WorkloadsSpec {
+		for nn, workloadSpec := range workloadsSpec {
+			workloadCost := WorkloadCost{
+				Kind:            kind,
+				NamespacedName:  nn,
+				Replicas:        workloadSpec.GoodsNum,
+				AverageReplicas: float64(workloadSpec.GoodsNum),
+				StaticCost:      workloadCosting(costerCtx.Pricer, timespanInHour, workloadSpec, nn, kind) * timespanInHour,
+				OriginalCost:    replicasCosting(costerCtx, timespanInHour, workloadSpec, nn, kind),
+			}
+			if replicas, ok := costerCtx.WorkloadsReplicas[kind][nn]; ok {
+				workloadCost.AverageReplicas = replicas
+			}
+			if recSpec, ok := costerCtx.WorkloadsRecSpec[kind][nn]; ok && recSpec != nil && strings.ToLower(kind) != "daemonset" {
+				workloadCost.RecommendedCost = replicasCosting(costerCtx, timespanInHour, recSpec.RecommendedSpec, nn, kind)
+			}
+			results = append(results, workloadCost)
+		}
+	}
+	return results
+}
+
+// replicasCosting returns the cost of the workload in the time span, it is by the average replicas if the replicas history is known
+func replicasCosting(costerCtx *CosterContext, timespanInHour float64, podSpec spec.CloudPodSpec, nn types.NamespacedName, kind string) float64 {
+	podSpec, replicas := costerCtx.replicasScaled(kind, nn, podSpec)
+	return workloadCosting(costerCtx.Pricer, timespanInHour, podSpec, nn, kind) * timespanInHour * replicas
+}
+
 func workloadCosting(pricer cloud.Pricer, timespanInHour float64, recommendedSpec spec.CloudPodSpec, nn types.NamespacedName, kind string) float64 {
 	workloadPricing, err := pricer.ServerlessPodPrice(recommendedSpec)
 	if err != nil {
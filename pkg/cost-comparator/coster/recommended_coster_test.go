@@ -0,0 +1,61 @@
+package coster
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/gocrane/fadvisor/pkg/cloud"
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// coresPricer prices a serverless pod 1 per core per hour
+type coresPricer struct {
+	instanceTypePricer
+}
+
+func (p coresPricer) ServerlessPodPrice(spec spec.CloudPodSpec) (*cloud.Pod, error) {
+	cost := float64(spec.Cpu.MilliValue()) / 1000. * float64(spec.GoodsNum)
+	return &cloud.Pod{BaseInstancePrice: cloud.BaseInstancePrice{Cost: fmt.Sprintf("%v", cost)}}, nil
+}
+
+func (p coresPricer) PlatformPrice(cp cloud.PlatformParameter) *cloud.Prices {
+	return &cloud.Prices{}
+}
+
+func TestWorkloadCostsByAverageReplicas(t *testing.T) {
+	hpa := types.NamespacedName{Namespace: "default", Name: "hpa"}
+	static := types.NamespacedName{Namespace: "default", Name: "static"}
+	newSpec := func(cpu string, replicas uint64) spec.CloudPodSpec {
+		return spec.CloudPodSpec{Cpu: resource.MustParse(cpu), GoodsNum: replicas}
+	}
+	costerCtx := &CosterContext{
+		TimeSpanSeconds: 3600,
+		Pricer:          coresPricer{},
+		WorkloadsSpec: map[string]map[types.NamespacedName]spec.CloudPodSpec{
+			"Deployment": {hpa: newSpec("2", 10), static: newSpec("1", 3)},
+		},
+		WorkloadsRecSpec: map[string]map[types.NamespacedName]*spec.WorkloadRecommendedData{
+			"Deployment": {hpa: {RecommendedSpec: newSpec("1", 10)}, static: {RecommendedSpec: newSpec("1", 3)}},
+		},
+		// the hpa scaled to 10 at analysis time, it runs 2.5 replicas in average
+		WorkloadsReplicas: map[string]map[types.NamespacedName]float64{"Deployment": {hpa: 2.5}},
+	}
+
+	expected := map[types.NamespacedName]WorkloadCost{
+		hpa:    {Replicas: 10, AverageReplicas: 2.5, StaticCost: 20, OriginalCost: 5, RecommendedCost: 2.5},
+		static: {Replicas: 3, AverageReplicas: 3, StaticCost: 3, OriginalCost: 3, RecommendedCost: 3},
+	}
+	for _, w := range NewRecommenderCoster().WorkloadCosts(costerCtx) {
+		e := expected[w.NamespacedName]
+		e.Kind, e.NamespacedName = w.Kind, w.NamespacedName
+		if w != e {
+			t.Errorf("expect %+v, got %+v", e, w)
+		}
+	}
+	if cost := NewServerlessCoster().TotalCost(costerCtx); cost.TotalCost != 8 {
+		t.Errorf("expect serverless cost 8, got %v", cost.TotalCost)
+	}
+}
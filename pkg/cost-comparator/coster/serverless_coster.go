@@ -30,6 +30,7 @@ func (e *serverless) TotalCost(costerCtx *CosterContext) Cost {
 			continue
 		}
 		for nn, workloadSpec := range workloadsSpec {
+			workloadSpec, replicas := costerCtx.replicasScaled(kind, nn, workloadSpec)
 			workloadPricing, err := costerCtx.Pricer.ServerlessPodPrice(workloadSpec)
 			if err != nil {
 				klog.Errorf("Failed to get ServerlessPodPrice for workload: %v, kind: %v, err: %v", nn, kind, err)
@@ -47,14 +48,14 @@ func (e *serverless) TotalCost(costerCtx *CosterContext) Cost {
 				klog.V(3).Infof("PodPrice is NaN. Setting to 0. workload: %v, kind: %v", nn, kind)
 				workloadPrice = 0
 			}
-			workloadCost := workloadPrice * timespanInHour
+			workloadCost := workloadPrice * timespanInHour * replicas
 
 			workloadKindTotalCost[kind] += workloadCost
 			serverlessPodsTotalCost += workloadCost
 		}
 	}
 
-	platformCost := costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{Platform: cloud.ServerlessKind})
+	platformCost := costerCtx.Pricer.PlatformPrice(cloud.PlatformParameter{ClusterLevel: costerCtx.ClusterLevel, Platform: cloud.ServerlessKind})
 
 	return Cost{
 		TotalCost:              serverlessPodsTotalCost + platformCost.TotalPrice,
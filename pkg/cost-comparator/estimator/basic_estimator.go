@@ -23,19 +23,13 @@ func NewStatisticEstimator() *StatisticEstimator {
 func (s *StatisticEstimator) Estimation(ts *common.TimeSeries, estimateConfig map[string]interface{}) (*spec.Statistic, error) {
 	result := &spec.Statistic{}
 	data := TimeSeries2Float64Data(ts)
-	percent := 0.95
-	if perts, ok := estimateConfig["percentile"]; ok {
-		percent, ok = perts.(float64)
-		if !ok {
-			return nil, fmt.Errorf("percentile param is not valid")
-		}
+	percent, err := floatParam(estimateConfig, EstimateConfigPercentile, 0.95)
+	if err != nil {
+		return nil, err
 	}
-	marginFraction := 1.25
-	if margin, ok := estimateConfig["marginFraction"]; ok {
-		marginFraction, ok = margin.(float64)
-		if !ok {
-			return nil, fmt.Errorf("marginFraction param is not valid")
-		}
+	marginFraction, err := floatParam(estimateConfig, EstimateConfigMarginFraction, 1.25)
+	if err != nil {
+		return nil, err
 	}
 
 	gotPertValue, err := data.Percentile(percent * 100)
@@ -54,12 +48,16 @@ func (s *StatisticEstimator) Estimation(ts *common.TimeSeries, estimateConfig ma
 	recommends := gotPertValue * marginFraction
 
 	maxRecommended := max * marginFraction
-	return &spec.Statistic{
+	result = &spec.Statistic{
 		Percentile:     &gotPertValue,
 		Max:            &max,
 		MaxRecommended: &maxRecommended,
 		Recommended:    &recommends,
-	}, nil
+	}
+	if err := boundStatistic(result, estimateConfig); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 //nolint:unused
@@ -0,0 +1,86 @@
+package estimator
+
+import (
+	"math"
+)
+
+// minSampleWeight is the minimal weight of a sample, the decayed weights smaller than it are ignored
+const minSampleWeight = 1e-6
+
+// decayingHistogram is a histogram with exponential buckets, the weight of a sample halves every halfLife before the reference time,
+// it is same as the vpa recommender, see k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/util
+type decayingHistogram struct {
+	firstBucketSize float64
+	growth          float64
+	weights         []float64
+	totalWeight     float64
+	halfLife        float64
+	// referenceTimestamp is the timestamp in seconds that the weight of the samples is 1
+	referenceTimestamp int64
+}
+
+// newDecayingHistogram return a histogram of values in [0, maxValue], the size of bucket i is firstBucketSize * (1 + growth)^i
+func newDecayingHistogram(maxValue, firstBucketSize, growth float64, halfLifeSeconds float64, referenceTimestamp int64) *decayingHistogram {
+	numBuckets := int(math.Ceil(math.Log(maxValue*growth/firstBucketSize+1)/math.Log(1+growth))) + 1
+	return &decayingHistogram{
+		firstBucketSize:    firstBucketSize,
+		growth:             growth,
+		weights:            make([]float64, numBuckets),
+		halfLife:           halfLifeSeconds,
+		referenceTimestamp: referenceTimestamp,
+	}
+}
+
+func (h *decayingHistogram) bucketStart(bucket int) float64 {
+	if bucket == 0 {
+		return 0
+	}
+	return h.firstBucketSize * (math.Pow(1+h.growth, float64(bucket)) - 1) / h.growth
+}
+
+func (h *decayingHistogram) findBucket(value float64) int {
+	if value < h.firstBucketSize {
+		return 0
+	}
+	bucket := int(math.Log(value*h.growth/h.firstBucketSize+1) / math.Log(1+h.growth))
+	if bucket >= len(h.weights) {
+		return len(h.weights) - 1
+	}
+	return bucket
+}
+
+// AddSample adds the value with the weight decayed by its timestamp
+func (h *decayingHistogram) AddSample(value, weight float64, timestamp int64) {
+	if h.halfLife > 0 {
+		weight *= math.Exp2(float64(timestamp-h.referenceTimestamp) / h.halfLife)
+	}
+	if weight < minSampleWeight {
+		return
+	}
+	h.weights[h.findBucket(value)] += weight
+	h.totalWeight += weight
+}
+
+func (h *decayingHistogram) IsEmpty() bool {
+	return h.totalWeight < minSampleWeight
+}
+
+// Percentile return the end of the bucket which the percentile falls in, 0 if the histogram is empty
+func (h *decayingHistogram) Percentile(percentile float64) float64 {
+	if h.IsEmpty() {
+		return 0
+	}
+	threshold := percentile * h.totalWeight
+	partialSum := 0.0
+	bucket := 0
+	for ; bucket < len(h.weights)-1; bucket++ {
+		partialSum += h.weights[bucket]
+		if partialSum >= threshold {
+			break
+		}
+	}
+	if bucket < len(h.weights)-1 {
+		return h.bucketStart(bucket + 1)
+	}
+	return h.bucketStart(bucket)
+}
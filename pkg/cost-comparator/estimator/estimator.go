@@ -1,6 +1,9 @@
 package estimator
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/fadvisor/pkg/spec"
 )
@@ -9,3 +12,34 @@ type Estimator interface {
 	// Given a time series, then return a statistic estimation, an estimation is all the statistic data of the time series, such as P95, P99, avg, max, min, median, variance
 	Estimation(ts *common.TimeSeries, estimateConfig map[string]interface{}) (*spec.Statistic, error)
 }
+
+// floatParam return the float param of estimateConfig, defaultValue if not specified
+func floatParam(estimateConfig map[string]interface{}, key string, defaultValue float64) (float64, error) {
+	param, ok := estimateConfig[key]
+	if !ok {
+		return defaultValue, nil
+	}
+	value, ok := param.(float64)
+	if !ok {
+		return 0, fmt.Errorf("%v param is not valid", key)
+	}
+	return value, nil
+}
+
+// boundStatistic bounds the recommended values by the min and max params of estimateConfig
+func boundStatistic(statistic *spec.Statistic, estimateConfig map[string]interface{}) error {
+	min, err := floatParam(estimateConfig, EstimateConfigMin, 0)
+	if err != nil {
+		return err
+	}
+	max, err := floatParam(estimateConfig, EstimateConfigMax, math.Inf(1))
+	if err != nil {
+		return err
+	}
+	for _, value := range []*float64{statistic.Recommended, statistic.MaxRecommended} {
+		if value != nil {
+			*value = math.Min(math.Max(*value, min), max)
+		}
+	}
+	return nil
+}
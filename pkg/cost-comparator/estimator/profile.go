@@ -0,0 +1,167 @@
+package estimator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// keys of estimateConfig
+const (
+	EstimateConfigPercentile     = "percentile"
+	EstimateConfigMarginFraction = "marginFraction"
+	EstimateConfigMin            = "min"
+	EstimateConfigMax            = "max"
+)
+
+// ResourceProfile is the estimate config of a resource, unset fields use the defaults of the estimator
+type ResourceProfile struct {
+	Percentile *float64 `json:"percentile,omitempty"`
+	// MarginFraction multiplies the percentile, such as 1.25
+	MarginFraction *float64 `json:"marginFraction,omitempty"`
+	// Min and Max bound the recommended value, cpu is in cores and memory is in bytes
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// EstimateConfig return the estimateConfig of the estimator
+func (rp ResourceProfile) EstimateConfig() map[string]interface{} {
+	estimateConfig := make(map[string]interface{})
+	if rp.Percentile != nil {
+		estimateConfig[EstimateConfigPercentile] = *rp.Percentile
+	}
+	if rp.MarginFraction != nil {
+		estimateConfig[EstimateConfigMarginFraction] = *rp.MarginFraction
+	}
+	if rp.Min != nil {
+		estimateConfig[EstimateConfigMin] = *rp.Min
+	}
+	if rp.Max != nil {
+		estimateConfig[EstimateConfigMax] = *rp.Max
+	}
+	return estimateConfig
+}
+
+// Profile is the estimator and its config of the workloads it selects
+type Profile struct {
+	Name string `json:"name"`
+	// Estimator is statistic or vpa, the estimator of the comparator is used if empty
+	Estimator string          `json:"estimator,omitempty"`
+	Cpu       ResourceProfile `json:"cpu,omitempty"`
+	Memory    ResourceProfile `json:"memory,omitempty"`
+	// Namespaces and Selector select the workloads, the profile is only used by the annotation or as the default if both are empty
+	Namespaces []string              `json:"namespaces,omitempty"`
+	Selector   *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// Resource return the profile of the resource, cpu or memory
+func (p *Profile) Resource(resourceName string) ResourceProfile {
+	if resourceName == ResourceMemory {
+		return p.Memory
+	}
+	return p.Cpu
+}
+
+// Profiles are the estimator profiles, such as
+// {"default": "standard", "profiles": [{"name": "latency", "namespaces": ["prod"], "cpu": {"percentile": 0.99, "marginFraction": 1.5}}]}
+type Profiles struct {
+	// Default is the name of the profile used if no profile selects the workload
+	Default  string    `json:"default,omitempty"`
+	Profiles []Profile `json:"profiles"`
+
+	selectors []labels.Selector
+}
+
+// LoadProfiles load the estimator profiles from the json file
+func LoadProfiles(path string) (*Profiles, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	profiles := &Profiles{}
+	if err := json.Unmarshal(content, profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse estimator profiles %v: %v", path, err)
+	}
+	if err := profiles.Complete(); err != nil {
+		return nil, fmt.Errorf("invalid estimator profiles %v: %v", path, err)
+	}
+	return profiles, nil
+}
+
+// Complete validates the profiles and parses the selectors
+func (ps *Profiles) Complete() error {
+	names := make(map[string]bool, len(ps.Profiles))
+	ps.selectors = make([]labels.Selector, len(ps.Profiles))
+	for i, p := range ps.Profiles {
+		if p.Name == "" {
+			return fmt.Errorf("profile %v has no name", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("duplicated profile %v", p.Name)
+		}
+		names[p.Name] = true
+		if p.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(p.Selector)
+			if err != nil {
+				return fmt.Errorf("invalid selector of profile %v: %v", p.Name, err)
+			}
+			ps.selectors[i] = selector
+		}
+	}
+	if ps.Default != "" && !names[ps.Default] {
+		return fmt.Errorf("default profile %v not found", ps.Default)
+	}
+	return nil
+}
+
+// Get return the profile of the name
+func (ps *Profiles) Get(name string) (*Profile, bool) {
+	for i := range ps.Profiles {
+		if ps.Profiles[i].Name == name {
+			return &ps.Profiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// Select return the profile of the workload, the profile named by the annotation has priority, then the first profile which selects the workload,
+// then the default profile. nil if no profile
+func (ps *Profiles) Select(namespace string, workloadLabels map[string]string, profileName string) *Profile {
+	if ps == nil {
+		return nil
+	}
+	if profileName != "" {
+		if p, ok := ps.Get(profileName); ok {
+			return p
+		}
+	}
+	for i := range ps.Profiles {
+		p := &ps.Profiles[i]
+		if len(p.Namespaces) == 0 && p.Selector == nil {
+			continue
+		}
+		if len(p.Namespaces) > 0 && !contains(p.Namespaces, namespace) {
+			continue
+		}
+		if i < len(ps.selectors) && ps.selectors[i] != nil && !ps.selectors[i].Matches(labels.Set(workloadLabels)) {
+			continue
+		}
+		return p
+	}
+	if p, ok := ps.Get(ps.Default); ok {
+		return p
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
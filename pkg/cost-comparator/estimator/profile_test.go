@@ -0,0 +1,96 @@
+package estimator
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testProfiles = `{
+  "default": "standard",
+  "profiles": [
+    {"name": "standard"},
+    {"name": "latency", "namespaces": ["prod"], "selector": {"matchLabels": {"tier": "frontend"}}, "cpu": {"percentile": 0.99, "marginFraction": 1.5}},
+    {"name": "batch", "namespaces": ["batch"], "cpu": {"percentile": 0.9, "marginFraction": 1.1, "max": 2}, "memory": {"min": 1073741824}}
+  ]
+}`
+
+func TestLoadProfilesSelect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := ioutil.WriteFile(path, []byte(testProfiles), 0644); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		namespace   string
+		labels      map[string]string
+		annotation  string
+		wantProfile string
+	}{
+		{namespace: "prod", labels: map[string]string{"tier": "frontend"}, wantProfile: "latency"},
+		{namespace: "prod", labels: map[string]string{"tier": "backend"}, wantProfile: "standard"},
+		{namespace: "batch", wantProfile: "batch"},
+		{namespace: "batch", annotation: "latency", wantProfile: "latency"},
+		{namespace: "default", annotation: "unknown", wantProfile: "standard"},
+	}
+	for _, tc := range testCases {
+		profile := profiles.Select(tc.namespace, tc.labels, tc.annotation)
+		if profile == nil || profile.Name != tc.wantProfile {
+			t.Errorf("expect profile %v of %v/%v/%v, got %+v", tc.wantProfile, tc.namespace, tc.labels, tc.annotation, profile)
+		}
+	}
+
+	latency, _ := profiles.Get("latency")
+	config := latency.Resource(ResourceCpu).EstimateConfig()
+	if config[EstimateConfigPercentile] != 0.99 || config[EstimateConfigMarginFraction] != 1.5 {
+		t.Errorf("unexpected estimate config %v", config)
+	}
+	if config := latency.Resource(ResourceMemory).EstimateConfig(); len(config) != 0 {
+		t.Errorf("expect empty memory estimate config, got %v", config)
+	}
+}
+
+func TestLoadProfilesInvalid(t *testing.T) {
+	for _, content := range []string{
+		`{"profiles": [{"name": "a"}, {"name": "a"}]}`,
+		`{"default": "b", "profiles": [{"name": "a"}]}`,
+		`{"profiles": [{"name": "a", "selector": {"matchExpressions": [{"key": "k", "operator": "Bad"}]}}]}`,
+	} {
+		path := filepath.Join(t.TempDir(), "profiles.json")
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadProfiles(path); err == nil {
+			t.Errorf("expect error of profiles %v", content)
+		}
+	}
+}
+
+func TestEstimationBounds(t *testing.T) {
+	ts := newTimeSeries(0, time.Minute, repeat(4.0, 60)...)
+	statistic, err := NewStatisticEstimator().Estimation(ts, map[string]interface{}{EstimateConfigMax: 2.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *statistic.Recommended != 2.0 || *statistic.MaxRecommended != 2.0 || *statistic.Percentile != 4.0 {
+		t.Errorf("expect recommended bounded by max 2, got %v %v", *statistic.Recommended, *statistic.MaxRecommended)
+	}
+
+	statistic, err = NewVpaEstimator(DefaultVpaEstimatorConfig()).Estimation(ts, map[string]interface{}{
+		EstimateConfigResource:       ResourceCpu,
+		EstimateConfigPercentile:     0.5,
+		EstimateConfigMarginFraction: 1.0,
+		EstimateConfigMin:            5.0,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *statistic.Recommended != 5.0 {
+		t.Errorf("expect recommended bounded by min 5, got %v", *statistic.Recommended)
+	}
+}
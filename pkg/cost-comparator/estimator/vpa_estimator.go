@@ -1,16 +1,176 @@
 package estimator
 
 import (
+	"fmt"
+	"math"
+	"time"
+
 	"github.com/gocrane/crane/pkg/common"
 	"github.com/gocrane/fadvisor/pkg/spec"
 )
 
 var _ Estimator = &VpaEstimator{}
 
-// VpaEstimator based on vpa decaying exponential moving window algorithm to estimate resource
+const (
+	// EstimateConfigResource is the key of estimateConfig which specifies the resource of the time series, cpu or memory
+	EstimateConfigResource = "resource"
+
+	ResourceCpu    = "cpu"
+	ResourceMemory = "memory"
+)
+
+// VpaEstimatorConfig is the parameters of the vpa recommender, the defaults are same as the vpa
+type VpaEstimatorConfig struct {
+	// HalfLife is the duration the weight of a sample halves
+	HalfLife time.Duration
+	// BucketGrowth is the growth ratio of the histogram bucket size
+	BucketGrowth float64
+	// ConfidenceInterval is the history length of confidence 1, the bounds are wider if the history is shorter
+	ConfidenceInterval time.Duration
+	// MemoryAggregationInterval is the interval the memory peak is taken in
+	MemoryAggregationInterval time.Duration
+	TargetPercentile          float64
+	UpperBoundPercentile      float64
+	SafetyMarginFraction      float64
+	// MinCpu is the minimal recommended cpu in cores
+	MinCpu float64
+	// MinMemory is the minimal recommended memory in bytes
+	MinMemory float64
+}
+
+func DefaultVpaEstimatorConfig() VpaEstimatorConfig {
+	return VpaEstimatorConfig{
+		HalfLife:                  24 * time.Hour,
+		BucketGrowth:              0.05,
+		ConfidenceInterval:        24 * time.Hour,
+		MemoryAggregationInterval: 24 * time.Hour,
+		TargetPercentile:          0.9,
+		UpperBoundPercentile:      0.95,
+		SafetyMarginFraction:      0.15,
+		MinCpu:                    0.025,
+		MinMemory:                 250 * 1024 * 1024,
+	}
+}
+
+// histogram options of the vpa, cpu is in cores and memory is in bytes
+const (
+	cpuHistogramMaxValue        = 1000.0
+	cpuHistogramFirstBucketSize = 0.01
+	memHistogramMaxValue        = 1e12
+	memHistogramFirstBucketSize = 1e7
+)
+
+// VpaEstimator based on vpa decaying exponential moving window algorithm to estimate resource.
+// The cpu samples are added to the histogram directly, and the memory peaks of each aggregation interval are added, same as the vpa recommender.
+// The samples are not weighted by the container requests, because the history requests is unknown.
+// Recommended is the target percentile with margin, MaxRecommended is the upper bound widened by the confidence of the history length.
 type VpaEstimator struct {
+	config VpaEstimatorConfig
+}
+
+func NewVpaEstimator(config VpaEstimatorConfig) *VpaEstimator {
+	return &VpaEstimator{config: config}
+}
+
+func (v *VpaEstimator) Estimation(ts *common.TimeSeries, estimateConfig map[string]interface{}) (*spec.Statistic, error) {
+	if ts == nil || len(ts.Samples) == 0 {
+		return nil, fmt.Errorf("no samples to estimate")
+	}
+	resourceName := ResourceCpu
+	if r, ok := estimateConfig[EstimateConfigResource]; ok {
+		if resourceName, ok = r.(string); !ok {
+			return nil, fmt.Errorf("resource param is not valid")
+		}
+	}
+
+	first, last := ts.Samples[0].Timestamp, ts.Samples[0].Timestamp
+	max := ts.Samples[0].Value
+	for _, sample := range ts.Samples {
+		if sample.Timestamp < first {
+			first = sample.Timestamp
+		}
+		if sample.Timestamp > last {
+			last = sample.Timestamp
+		}
+		max = math.Max(max, sample.Value)
+	}
+
+	var histogram *decayingHistogram
+	var minResource float64
+	halfLife := v.config.HalfLife.Seconds()
+	switch resourceName {
+	case ResourceCpu:
+		histogram = newDecayingHistogram(cpuHistogramMaxValue, cpuHistogramFirstBucketSize, v.config.BucketGrowth, halfLife, last)
+		minResource = v.config.MinCpu
+		for _, sample := range ts.Samples {
+			histogram.AddSample(sample.Value, 1, sample.Timestamp)
+		}
+	case ResourceMemory:
+		histogram = newDecayingHistogram(memHistogramMaxValue, memHistogramFirstBucketSize, v.config.BucketGrowth, halfLife, last)
+		minResource = v.config.MinMemory
+		for start, peak := range memoryPeaks(ts.Samples, first, int64(v.config.MemoryAggregationInterval.Seconds())) {
+			histogram.AddSample(peak, 1, start)
+		}
+	default:
+		return nil, fmt.Errorf("unknown resource %v", resourceName)
+	}
+
+	// the profile params override the config, marginFraction multiplies the percentile same as the statistic estimator
+	targetPercentile, err := floatParam(estimateConfig, EstimateConfigPercentile, v.config.TargetPercentile)
+	if err != nil {
+		return nil, err
+	}
+	margin, err := floatParam(estimateConfig, EstimateConfigMarginFraction, 1+v.config.SafetyMarginFraction)
+	if err != nil {
+		return nil, err
+	}
+	if minResource, err = floatParam(estimateConfig, EstimateConfigMin, minResource); err != nil {
+		return nil, err
+	}
+	percentile := histogram.Percentile(targetPercentile)
+	recommended := math.Max(percentile*margin, minResource)
+	upperBound := histogram.Percentile(v.config.UpperBoundPercentile) * margin * v.confidenceMultiplier(last-first, len(ts.Samples))
+	upperBound = math.Max(upperBound, recommended)
+	maxRecommended := upperBound
+	statistic := &spec.Statistic{
+		Percentile:     &percentile,
+		Max:            &max,
+		MaxRecommended: &maxRecommended,
+		Recommended:    &recommended,
+	}
+	if err := boundStatistic(statistic, estimateConfig); err != nil {
+		return nil, err
+	}
+	return statistic, nil
+}
+
+// confidenceMultiplier widens the upper bound by (1 + 1/confidence), confidence is the history length in ConfidenceInterval,
+// it is same as the vpa upper bound estimator whose confidence is the days of the history
+func (v *VpaEstimator) confidenceMultiplier(lifespanSeconds int64, samples int) float64 {
+	interval := v.config.ConfidenceInterval.Seconds()
+	if interval <= 0 {
+		return 1
+	}
+	// the vpa assumes a sample per minute, so the history with missing samples is less confident
+	confidence := math.Min(float64(lifespanSeconds)/interval, float64(samples)*60/interval)
+	// a single sample has no lifespan, do not widen the bound infinitely
+	if confidence <= 0 {
+		return 2
+	}
+	return 1 + 1/confidence
 }
 
-func (v VpaEstimator) Estimation(ts *common.TimeSeries, estimateConfig map[string]interface{}) (*spec.Statistic, error) {
-	panic("implement me")
+// memoryPeaks return the peak of each aggregation interval, key is the start of the interval
+func memoryPeaks(samples []common.Sample, first, interval int64) map[int64]float64 {
+	peaks := make(map[int64]float64)
+	for _, sample := range samples {
+		start := first
+		if interval > 0 {
+			start = first + (sample.Timestamp-first)/interval*interval
+		}
+		if peak, ok := peaks[start]; !ok || sample.Value > peak {
+			peaks[start] = sample.Value
+		}
+	}
+	return peaks
 }
@@ -0,0 +1,110 @@
+package estimator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gocrane/crane/pkg/common"
+)
+
+func newTimeSeries(start int64, step time.Duration, values ...float64) *common.TimeSeries {
+	ts := common.NewTimeSeries()
+	for i, value := range values {
+		ts.AppendSample(start+int64(i)*int64(step.Seconds()), value)
+	}
+	return ts
+}
+
+func repeat(value float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func TestVpaEstimatorCpu(t *testing.T) {
+	v := NewVpaEstimator(DefaultVpaEstimatorConfig())
+	// a day of samples per minute, so confidence is 1
+	ts := newTimeSeries(0, time.Minute, repeat(1.0, 24*60+1)...)
+	statistic, err := v.Estimation(ts, map[string]interface{}{EstimateConfigResource: ResourceCpu})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the percentile is the end of the bucket which holds 1.0, the bucket size is 5%
+	if p := *statistic.Percentile; p < 1.0 || p > 1.06 {
+		t.Errorf("expect percentile in [1, 1.06], got %v", p)
+	}
+	if r := *statistic.Recommended; math.Abs(r-*statistic.Percentile*1.15) > 1e-9 {
+		t.Errorf("expect recommended with 15%% margin, got %v", r)
+	}
+	if u := *statistic.MaxRecommended; math.Abs(u-*statistic.Percentile*1.15*2) > 1e-9 {
+		t.Errorf("expect upper bound doubled by confidence 1, got %v", u)
+	}
+	if *statistic.Max != 1.0 {
+		t.Errorf("expect max 1, got %v", *statistic.Max)
+	}
+}
+
+func TestVpaEstimatorDecay(t *testing.T) {
+	config := DefaultVpaEstimatorConfig()
+	config.HalfLife = time.Hour
+	v := NewVpaEstimator(config)
+	// the spike is 10 half lives before the recent samples, its weight is negligible
+	values := append(repeat(4.0, 60), repeat(0.5, 60)...)
+	ts := newTimeSeries(0, 10*time.Minute, values...)
+	statistic, err := v.Estimation(ts, map[string]interface{}{EstimateConfigResource: ResourceCpu})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := *statistic.Percentile; p > 0.6 {
+		t.Errorf("expect the old samples decayed, got percentile %v", p)
+	}
+
+	// without decay the spike is half of the samples
+	config.HalfLife = 0
+	statistic, err = NewVpaEstimator(config).Estimation(ts, map[string]interface{}{EstimateConfigResource: ResourceCpu})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := *statistic.Percentile; p < 4.0 {
+		t.Errorf("expect percentile of the spike, got %v", p)
+	}
+}
+
+func TestVpaEstimatorMemory(t *testing.T) {
+	v := NewVpaEstimator(DefaultVpaEstimatorConfig())
+	// peaks of the two days are 1GB and 2GB
+	values := append(repeat(0.5e9, 23), 1e9)
+	values = append(values, repeat(0.5e9, 23)...)
+	values = append(values, 2e9)
+	ts := newTimeSeries(0, time.Hour, values...)
+	statistic, err := v.Estimation(ts, map[string]interface{}{EstimateConfigResource: ResourceMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := *statistic.Percentile; p < 2e9 || p > 2.1e9 {
+		t.Errorf("expect percentile of the recent peak 2GB, got %v", p)
+	}
+
+	// the minimal memory is recommended for the tiny usage
+	ts = newTimeSeries(0, time.Minute, repeat(1e6, 60)...)
+	statistic, err = v.Estimation(ts, map[string]interface{}{EstimateConfigResource: ResourceMemory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := *statistic.Recommended; r != 250*1024*1024 {
+		t.Errorf("expect min memory recommended, got %v", r)
+	}
+}
+
+func TestVpaEstimatorInvalid(t *testing.T) {
+	v := NewVpaEstimator(DefaultVpaEstimatorConfig())
+	if _, err := v.Estimation(common.NewTimeSeries(), nil); err == nil {
+		t.Errorf("expect error of empty time series")
+	}
+	if _, err := v.Estimation(newTimeSeries(0, time.Minute, 1), map[string]interface{}{EstimateConfigResource: "gpu"}); err == nil {
+		t.Errorf("expect error of unknown resource")
+	}
+}
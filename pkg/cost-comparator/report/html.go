@@ -0,0 +1,181 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+const (
+	chartLabelWidth = 220
+	chartBarWidth   = 520
+	chartBarHeight  = 14
+	chartGroupGap   = 12
+	// chartMaxGroups limits the bars of a chart, such as the namespaces of a large cluster
+	chartMaxGroups = 30
+)
+
+// htmlReporter collects the sections and writes a self-contained html document when closed, the cost breakdown
+// sections are also rendered as charts of the original versus recommended cost per kind and namespace
+type htmlReporter struct {
+	path     string
+	document Document
+}
+
+func NewHtmlReporter(path string, clusterId string) Reporter {
+	return &htmlReporter{path: path, document: Document{ClusterId: clusterId}}
+}
+
+func (h *htmlReporter) Report(section Section) error {
+	h.document.Sections = append(h.document.Sections, section)
+	return nil
+}
+
+func (h *htmlReporter) Close() error {
+	h.document.GeneratedAt = time.Now()
+	return writeFile(h.path, func(w io.Writer) error {
+		return RenderHtml(w, h.document)
+	})
+}
+
+type htmlSection struct {
+	Section
+	Charts []chart
+}
+
+type chart struct {
+	Title  string
+	Width  int
+	Height int
+	Bars   []chartBar
+}
+
+// chartBar is a group of the chart, the positions are computed so the template only renders them
+type chartBar struct {
+	Label            string
+	LabelY           int
+	X                int
+	Height           int
+	OriginalY        int
+	RecommendedY     int
+	OriginalCost     float64
+	RecommendedCost  float64
+	OriginalWidth    float64
+	RecommendedWidth float64
+}
+
+// RenderHtml renders the document as a html page without external resources
+func RenderHtml(w io.Writer, document Document) error {
+	var sections []htmlSection
+	for _, section := range document.Sections {
+		s := htmlSection{Section: section}
+		if breakdown, ok := section.Data.(CostBreakdown); ok {
+			s.Charts = []chart{
+				newChart("Cost per Kind", breakdown.ByKind()),
+				newChart("Cost per Namespace", breakdown.ByNamespace()),
+			}
+		}
+		sections = append(sections, s)
+	}
+	return htmlTemplate.Execute(w, struct {
+		ClusterId   string
+		GeneratedAt string
+		Sections    []htmlSection
+	}{
+		ClusterId:   document.ClusterId,
+		GeneratedAt: document.GeneratedAt.Format(time.RFC3339),
+		Sections:    sections,
+	})
+}
+
+// newChart returns a horizontal bar chart, each group has a bar of the original cost and a bar of the recommended cost
+func newChart(title string, groups []CostGroup) chart {
+	if len(groups) > chartMaxGroups {
+		title = fmt.Sprintf("%v (Top %v)", title, chartMaxGroups)
+		groups = groups[:chartMaxGroups]
+	}
+	max := 0.
+	for _, g := range groups {
+		if g.OriginalCost > max {
+			max = g.OriginalCost
+		}
+		if g.RecommendedCost > max {
+			max = g.RecommendedCost
+		}
+	}
+	groupHeight := 2*chartBarHeight + chartGroupGap
+	c := chart{
+		Title:  title,
+		Width:  chartLabelWidth + chartBarWidth + 120,
+		Height: len(groups)*groupHeight + chartGroupGap,
+	}
+	for i, g := range groups {
+		y := chartGroupGap + i*groupHeight
+		bar := chartBar{
+			Label:           g.Name,
+			LabelY:          y + chartBarHeight + 4,
+			X:               chartLabelWidth,
+			Height:          chartBarHeight,
+			OriginalY:       y,
+			RecommendedY:    y + chartBarHeight,
+			OriginalCost:    g.OriginalCost,
+			RecommendedCost: g.RecommendedCost,
+		}
+		if max > 0 {
+			bar.OriginalWidth = g.OriginalCost / max * chartBarWidth
+			bar.RecommendedWidth = g.RecommendedCost / max * chartBarWidth
+		}
+		c.Bars = append(c.Bars, bar)
+	}
+	return c
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	// textX is the x of the value text after a bar
+	"textX": func(bar chartBar, width float64) float64 { return float64(bar.X) + width + 4 },
+	"textY": func(y int) int { return y + chartBarHeight - 3 },
+	"cost":  formatFloat,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>fadvisor cost comparator report - {{.ClusterId}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 24px; color: #222; }
+h1 { font-size: 22px; }
+h2 { font-size: 17px; margin-top: 32px; border-bottom: 1px solid #ddd; padding-bottom: 4px; }
+table { border-collapse: collapse; font-size: 12px; margin-top: 8px; }
+th, td { border: 1px solid #ddd; padding: 3px 8px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+td { max-width: 480px; overflow-wrap: anywhere; }
+.legend span { display: inline-block; width: 12px; height: 12px; margin: 0 4px 0 12px; vertical-align: middle; }
+.original { fill: #e07b39; background: #e07b39; }
+.recommended { fill: #3b7dd8; background: #3b7dd8; }
+svg text { font-size: 11px; fill: #222; }
+</style>
+</head>
+<body>
+<h1>Cost Comparator Report of Cluster {{.ClusterId}}</h1>
+<p>Generated at {{.GeneratedAt}}</p>
+{{range .Sections}}
+<h2 id="{{.Name}}">{{.Title}}</h2>
+{{range .Charts}}
+<h3>{{.Title}}</h3>
+<div class="legend"><span class="original"></span>Original<span class="recommended"></span>Recommended</div>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}">
+{{range .Bars}}<text x="0" y="{{.LabelY}}">{{.Label}}</text>
+<rect class="original" x="{{.X}}" y="{{.OriginalY}}" width="{{.OriginalWidth}}" height="{{.Height}}"></rect>
+<text x="{{textX . .OriginalWidth}}" y="{{textY .OriginalY}}">{{cost .OriginalCost}}</text>
+<rect class="recommended" x="{{.X}}" y="{{.RecommendedY}}" width="{{.RecommendedWidth}}" height="{{.Height}}"></rect>
+<text x="{{textX . .RecommendedWidth}}" y="{{textY .RecommendedY}}">{{cost .RecommendedCost}}</text>
+{{end}}</svg>
+{{end}}
+<table>
+<tr>{{range .Data.Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Data.Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,202 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/gocrane/fadvisor/pkg/cost-comparator/config"
+)
+
+// Reporter outputs the sections of a comparator report. Some reporters write the sections once they are reported,
+// others collect the sections and write a single report when closed, so Close must be called after the last section.
+type Reporter interface {
+	Report(section Section) error
+	Close() error
+}
+
+// NewReporter returns the reporter of the output mode, the output mode is a comma separated list of stdout, csv, json and html.
+// If the output mode is empty, both the stdout tables and csv files are output. The files are named by the cluster id under dataPath.
+func NewReporter(outputMode string, dataPath string, clusterId string) (Reporter, error) {
+	modes, err := ParseOutputMode(outputMode)
+	if err != nil {
+		return nil, err
+	}
+	var reporters multiReporter
+	for _, mode := range modes {
+		switch mode {
+		case config.OutputModeStdOut:
+			reporters = append(reporters, NewTableReporter(os.Stdout))
+		case config.OutputModeCsv:
+			reporters = append(reporters, NewCsvReporter(dataPath, clusterId))
+		case config.OutputModeJson:
+			reporters = append(reporters, NewJsonReporter(filepath.Join(dataPath, clusterId+"-report.json"), clusterId))
+		case config.OutputModeHtml:
+			reporters = append(reporters, NewHtmlReporter(filepath.Join(dataPath, clusterId+"-report.html"), clusterId))
+		}
+	}
+	return reporters, nil
+}
+
+// ParseOutputMode returns the output modes, it returns an error if any mode is unknown
+func ParseOutputMode(outputMode string) ([]string, error) {
+	if outputMode == "" {
+		return []string{config.OutputModeStdOut, config.OutputModeCsv}, nil
+	}
+	var modes []string
+	for _, mode := range strings.Split(outputMode, ",") {
+		mode = strings.TrimSpace(mode)
+		switch mode {
+		case config.OutputModeStdOut, config.OutputModeCsv, config.OutputModeJson, config.OutputModeHtml:
+			modes = append(modes, mode)
+		default:
+			return nil, fmt.Errorf("unknown output mode %v", mode)
+		}
+	}
+	return modes, nil
+}
+
+// Multi returns a reporter reports the sections to all the reporters
+func Multi(reporters ...Reporter) Reporter {
+	return multiReporter(reporters)
+}
+
+// multiReporter reports the sections to all the reporters
+type multiReporter []Reporter
+
+func (m multiReporter) Report(section Section) error {
+	for _, r := range m {
+		if err := r.Report(section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes all the reporters and returns the first error
+func (m multiReporter) Close() error {
+	var firstErr error
+	for _, r := range m {
+		if err := r.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// tableReporter prints the sections as tables
+type tableReporter struct {
+	w io.Writer
+}
+
+func NewTableReporter(w io.Writer) Reporter {
+	return &tableReporter{w: w}
+}
+
+func (t *tableReporter) Report(section Section) error {
+	if _, err := fmt.Fprintf(t.w, "Reporting, %v%v\n", section.Title, strings.Repeat(".", 80)); err != nil {
+		return err
+	}
+	table := tablewriter.NewWriter(t.w)
+	table.SetHeaderLine(true)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader(section.Data.Header())
+	table.SetBorder(false)
+	table.AppendBulk(section.Data.Rows())
+	table.Render()
+	_, err := fmt.Fprintln(t.w)
+	return err
+}
+
+func (t *tableReporter) Close() error {
+	return nil
+}
+
+// csvReporter writes each section to a tab separated csv file
+type csvReporter struct {
+	dataPath  string
+	clusterId string
+}
+
+func NewCsvReporter(dataPath string, clusterId string) Reporter {
+	return &csvReporter{dataPath: dataPath, clusterId: clusterId}
+}
+
+func (c *csvReporter) Report(section Section) error {
+	filename := filepath.Join(c.dataPath, c.clusterId+"-"+section.Name+".csv")
+	csvFile, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	csvW := csv.NewWriter(csvFile)
+	csvW.Comma = '\t'
+	if err = csvW.Write(section.Data.Header()); err == nil {
+		err = csvW.WriteAll(section.Data.Rows())
+	}
+	if closeErr := csvFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %v: %v", filename, err)
+	}
+	return nil
+}
+
+func (c *csvReporter) Close() error {
+	return nil
+}
+
+// Document is the whole report written by the json and html reporters
+type Document struct {
+	ClusterId   string    `json:"clusterId"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Sections    []Section `json:"sections"`
+}
+
+// jsonReporter collects the sections and writes a json document when closed
+type jsonReporter struct {
+	path     string
+	document Document
+}
+
+func NewJsonReporter(path string, clusterId string) Reporter {
+	return &jsonReporter{path: path, document: Document{ClusterId: clusterId}}
+}
+
+func (j *jsonReporter) Report(section Section) error {
+	j.document.Sections = append(j.document.Sections, section)
+	return nil
+}
+
+func (j *jsonReporter) Close() error {
+	j.document.GeneratedAt = time.Now()
+	return writeFile(j.path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(j.document)
+	})
+}
+
+// writeFile writes the file by write, the file is removed if write fails
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write %v: %v", path, err)
+	}
+	return nil
+}
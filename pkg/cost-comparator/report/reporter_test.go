@@ -0,0 +1,116 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testBreakdown = CostBreakdown{
+	{Kind: "Deployment", Namespace: "default", Name: "nginx", OriginalCost: 10, RecommendedCost: 4},
+	{Kind: "Deployment", Namespace: "kube-system", Name: "coredns", OriginalCost: 2, RecommendedCost: 1},
+	{Kind: "StatefulSet", Namespace: "default", Name: "mysql", OriginalCost: 5, RecommendedCost: 5},
+}
+
+func TestCostBreakdownGroups(t *testing.T) {
+	expected := []CostGroup{
+		{Name: "Deployment", OriginalCost: 12, RecommendedCost: 5},
+		{Name: "StatefulSet", OriginalCost: 5, RecommendedCost: 5},
+	}
+	if groups := testBreakdown.ByKind(); !reflect.DeepEqual(groups, expected) {
+		t.Errorf("expect %v, got %v", expected, groups)
+	}
+	expected = []CostGroup{
+		{Name: "default", OriginalCost: 15, RecommendedCost: 9},
+		{Name: "kube-system", OriginalCost: 2, RecommendedCost: 1},
+	}
+	if groups := testBreakdown.ByNamespace(); !reflect.DeepEqual(groups, expected) {
+		t.Errorf("expect %v, got %v", expected, groups)
+	}
+}
+
+func TestParseOutputMode(t *testing.T) {
+	modes, err := ParseOutputMode("")
+	if err != nil || !reflect.DeepEqual(modes, []string{"stdout", "csv"}) {
+		t.Errorf("unexpected default modes %v, %v", modes, err)
+	}
+	modes, err = ParseOutputMode("json, html")
+	if err != nil || !reflect.DeepEqual(modes, []string{"json", "html"}) {
+		t.Errorf("unexpected modes %v, %v", modes, err)
+	}
+	if _, err = ParseOutputMode("csv,pdf"); err == nil {
+		t.Errorf("expect unknown mode refused")
+	}
+}
+
+func TestReporters(t *testing.T) {
+	dir := t.TempDir()
+	reporter, err := NewReporter("csv,json,html", dir, "cls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections := []Section{
+		{Name: "original-resource-summary", Title: "Original Resource Summary", Data: ResourceSummary{{Type: "clusterRequestsTotal", Cpu: 1.5, Mem: 2}}},
+		{Name: "workloads-cost-breakdown", Title: "Workloads Cost", Data: testBreakdown},
+	}
+	for _, section := range sections {
+		if err := reporter.Report(section); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := reporter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	csvBytes, err := ioutil.ReadFile(filepath.Join(dir, "cls-original-resource-summary.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Type\tCpu\tMem\nclusterRequestsTotal\t1.50000\t2.00000\n"; string(csvBytes) != expected {
+		t.Errorf("expect csv %q, got %q", expected, string(csvBytes))
+	}
+
+	jsonBytes, err := ioutil.ReadFile(filepath.Join(dir, "cls-report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document struct {
+		ClusterId string
+		Sections  []struct {
+			Name string
+			Data []map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(jsonBytes, &document); err != nil {
+		t.Fatal(err)
+	}
+	if document.ClusterId != "cls" || len(document.Sections) != 2 || document.Sections[1].Data[0]["originalCost"] != 10. {
+		t.Errorf("unexpected json report %s", string(jsonBytes))
+	}
+
+	htmlBytes, err := ioutil.ReadFile(filepath.Join(dir, "cls-report.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(htmlBytes)
+	for _, expected := range []string{"Cost per Kind", "Cost per Namespace", "<svg", "StatefulSet", "clusterRequestsTotal"} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expect html report contains %v", expected)
+		}
+	}
+}
+
+func TestTableReporter(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewTableReporter(&buf)
+	if err := reporter.Report(Section{Name: "arm-savings", Title: "Arm Savings", Data: ArmSavings{{Kind: "Deployment", Name: "nginx", Savings: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Reporting, Arm Savings") || !strings.Contains(out, "total") {
+		t.Errorf("unexpected table %v", out)
+	}
+}
@@ -0,0 +1,430 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/gocrane/fadvisor/pkg/spec"
+)
+
+// names of the comparator report sections
+const (
+	SectionOriginalResourceSummary          = "original-resource-summary"
+	SectionOriginalCostSummary              = "original-cost-summary"
+	SectionServerlessCostSummary            = "direct-migrate-serverless-cost-summary"
+	SectionRecommendedResourceSummary       = "recommended-serverless-resource-summary"
+	SectionRecommendedCostSummary           = "recommended-cost-summary"
+	SectionWorkloadsCostBreakdown           = "workloads-cost-breakdown"
+	SectionSavingsOpportunities             = "savings-opportunities"
+	SectionArmSavings                       = "arm-savings"
+	SectionSensitivityMatrix                = "sensitivity-matrix"
+	SectionProviderComparison               = "provider-comparison"
+	SectionRecommendedNodeMix               = "recommended-node-mix"
+	SectionBatchWorkloadsCost               = "batch-workloads-cost"
+	SectionOriginalWorkloadsDistribution    = "original-workloads-distribution"
+	SectionRecommendedWorkloadsDistribution = "recommended-workloads-distribution"
+)
+
+// Table is the typed data of a report section, it is rendered as rows by the table and csv reporters
+type Table interface {
+	Header() []string
+	Rows() [][]string
+}
+
+// Section is a titled table of the report
+type Section struct {
+	// Name is unique in a report, it is the suffix of the csv file name and the key of the json report
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Data  Table  `json:"data"`
+}
+
+// ResourceTotal is the total cpu cores and memory GB of a type of resource
+type ResourceTotal struct {
+	Type string  `json:"type"`
+	Cpu  float64 `json:"cpu"`
+	Mem  float64 `json:"mem"`
+}
+
+type ResourceSummary []ResourceTotal
+
+func (s ResourceSummary) Header() []string {
+	return []string{"Type", "Cpu", "Mem"}
+}
+
+func (s ResourceSummary) Rows() [][]string {
+	var rows [][]string
+	for _, r := range s {
+		rows = append(rows, []string{r.Type, formatFloat(r.Cpu), formatFloat(r.Mem)})
+	}
+	return rows
+}
+
+// Cost is the cost of a cluster, such as the tke cost or the cost of migrating to eks directly
+type Cost struct {
+	Type                   string  `json:"type"`
+	TotalCost              float64 `json:"totalCost"`
+	ServerfulCost          float64 `json:"serverfulCost"`
+	ServerlessCost         float64 `json:"serverlessCost"`
+	ServerfulPlatformCost  float64 `json:"serverfulPlatformCost"`
+	ServerlessPlatformCost float64 `json:"serverlessPlatformCost"`
+}
+
+type CostSummary []Cost
+
+func (s CostSummary) Header() []string {
+	return []string{"Type", "TotalCost", "ServerfulCost", "ServerlessCost", "ServerfulPlatformCost", "ServerlessPlatformCost"}
+}
+
+func (s CostSummary) Rows() [][]string {
+	var rows [][]string
+	for _, c := range s {
+		rows = append(rows, []string{c.Type, formatFloat(c.TotalCost), formatFloat(c.ServerfulCost), formatFloat(c.ServerlessCost), formatFloat(c.ServerfulPlatformCost), formatFloat(c.ServerlessPlatformCost)})
+	}
+	return rows
+}
+
+// RecommendedCost is the serverless cost of the workloads by a kind of recommendation
+type RecommendedCost struct {
+	Type         string  `json:"type"`
+	TotalCost    float64 `json:"totalCost"`
+	WorkloadCost float64 `json:"workloadCost"`
+	PlatformCost float64 `json:"platformCost"`
+}
+
+type RecommendedCostSummary []RecommendedCost
+
+func (s RecommendedCostSummary) Header() []string {
+	return []string{"Type", "TotalCost", "WorkloadCost", "PlatformCost"}
+}
+
+func (s RecommendedCostSummary) Rows() [][]string {
+	var rows [][]string
+	for _, c := range s {
+		rows = append(rows, []string{c.Type, formatFloat(c.TotalCost), formatFloat(c.WorkloadCost), formatFloat(c.PlatformCost)})
+	}
+	return rows
+}
+
+// WorkloadResource is the resource requirements of a workload, cpu is in cores and memory is in GB
+type WorkloadResource struct {
+	Kind       string  `json:"kind"`
+	Namespace  string  `json:"namespace"`
+	Name       string  `json:"name"`
+	CpuReq     float64 `json:"cpuReq"`
+	MemReq     float64 `json:"memReq"`
+	CpuLim     float64 `json:"cpuLim"`
+	MemLim     float64 `json:"memLim"`
+	Replicas   uint64  `json:"replicas"`
+	Serverless bool    `json:"serverless"`
+	QoSClass   string  `json:"qosClass"`
+}
+
+func (w WorkloadResource) row() []string {
+	return []string{w.Kind, w.Namespace, w.Name, formatFloat(w.CpuReq), formatFloat(w.MemReq), formatFloat(w.CpuLim), formatFloat(w.MemLim), fmt.Sprintf("%v", w.Replicas), fmt.Sprintf("%v", w.Serverless), w.QoSClass}
+}
+
+type OriginalWorkload struct {
+	WorkloadResource
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type OriginalWorkloadsDistribution []OriginalWorkload
+
+func (d OriginalWorkloadsDistribution) Header() []string {
+	return []string{"Kind", "Namespace", "Name", "CpuReq", "MemReq", "CpuLim", "MemLim", "Replicas", "Serverless", "K8SQoS", "Labels"}
+}
+
+func (d OriginalWorkloadsDistribution) Rows() [][]string {
+	var rows [][]string
+	for _, w := range d {
+		labels := ""
+		if w.Labels != nil {
+			labelsBytes, _ := json.Marshal(w.Labels)
+			labels = string(labelsBytes)
+		}
+		rows = append(rows, append(w.row(), labels))
+	}
+	return rows
+}
+
+type RecommendedWorkload struct {
+	WorkloadResource
+	Containers map[string]*spec.ContainerRecommendedData `json:"containers,omitempty"`
+}
+
+type RecommendedWorkloadsDistribution []RecommendedWorkload
+
+func (d RecommendedWorkloadsDistribution) Header() []string {
+	return []string{"Kind", "Namespace", "Name", "CpuReq", "MemReq", "CpuLim", "MemLim", "Replicas", "Serverless", "K8SQoS", "ContainerStats"}
+}
+
+func (d RecommendedWorkloadsDistribution) Rows() [][]string {
+	var rows [][]string
+	for _, w := range d {
+		containerStats, _ := json.Marshal(w.Containers)
+		rows = append(rows, append(w.row(), string(containerStats)))
+	}
+	return rows
+}
+
+// ArmSaving is the estimated cost of a workload on its current nodes and on arm64 nodes
+type ArmSaving struct {
+	Kind           string  `json:"kind"`
+	Namespace      string  `json:"namespace"`
+	Name           string  `json:"name"`
+	Arch           string  `json:"arch"`
+	InstanceFamily string  `json:"instanceFamily"`
+	CurrentCost    float64 `json:"currentCost"`
+	ArmCost        float64 `json:"armCost"`
+	Savings        float64 `json:"savings"`
+}
+
+type ArmSavings []ArmSaving
+
+func (s ArmSavings) Header() []string {
+	return []string{"Kind", "Namespace", "Name", "Arch", "InstanceFamily", "CurrentCost", "ArmCost", "Savings"}
+}
+
+// Rows returns the savings and a total row at last
+func (s ArmSavings) Rows() [][]string {
+	var rows [][]string
+	total := 0.
+	for _, saving := range s {
+		total += saving.Savings
+		rows = append(rows, []string{saving.Kind, saving.Namespace, saving.Name, saving.Arch, saving.InstanceFamily, formatFloat(saving.CurrentCost), formatFloat(saving.ArmCost), formatFloat(saving.Savings)})
+	}
+	return append(rows, []string{"total", "", "", "", "", "", "", formatFloat(total)})
+}
+
+// Sensitivity is the recommended cost and the risks by a percentile and margin fraction
+type Sensitivity struct {
+	Percentile      float64 `json:"percentile"`
+	MarginFraction  float64 `json:"marginFraction"`
+	TotalCost       float64 `json:"totalCost"`
+	WorkloadCost    float64 `json:"workloadCost"`
+	Savings         float64 `json:"savings"`
+	CpuThrottleRisk float64 `json:"cpuThrottleRisk"`
+	MemOOMRisk      float64 `json:"memOOMRisk"`
+}
+
+type SensitivityMatrix []Sensitivity
+
+func (m SensitivityMatrix) Header() []string {
+	return []string{"Percentile", "MarginFraction", "TotalCost", "WorkloadCost", "Savings", "CpuThrottleRisk", "MemOOMRisk"}
+}
+
+func (m SensitivityMatrix) Rows() [][]string {
+	var rows [][]string
+	for _, r := range m {
+		rows = append(rows, []string{formatFloat(r.Percentile), formatFloat(r.MarginFraction), formatFloat(r.TotalCost), formatFloat(r.WorkloadCost), formatFloat(r.Savings), formatFloat(r.CpuThrottleRisk), formatFloat(r.MemOOMRisk)})
+	}
+	return rows
+}
+
+// ProviderCost is the cost of the cluster workloads on a cloud provider, serverful on the nodes or serverless.
+// Savings is the baseline current cost minus the cost
+type ProviderCost struct {
+	Target       string  `json:"target"`
+	Provider     string  `json:"provider"`
+	Mode         string  `json:"mode"`
+	TotalCost    float64 `json:"totalCost"`
+	ComputeCost  float64 `json:"computeCost"`
+	PlatformCost float64 `json:"platformCost"`
+	Savings      float64 `json:"savings"`
+}
+
+type ProviderComparison []ProviderCost
+
+func (c ProviderComparison) Header() []string {
+	return []string{"Target", "Provider", "Mode", "TotalCost", "ComputeCost", "PlatformCost", "Savings"}
+}
+
+func (c ProviderComparison) Rows() [][]string {
+	var rows [][]string
+	for _, p := range c {
+		rows = append(rows, []string{p.Target, p.Provider, p.Mode, formatFloat(p.TotalCost), formatFloat(p.ComputeCost), formatFloat(p.PlatformCost), formatFloat(p.Savings)})
+	}
+	return rows
+}
+
+// NodeGroup is the nodes of an instance type, Mem is GB
+type NodeGroup struct {
+	InstanceType string  `json:"instanceType"`
+	Cpu          float64 `json:"cpu"`
+	Mem          float64 `json:"mem"`
+	Count        int     `json:"count"`
+	HourlyPrice  float64 `json:"hourlyPrice"`
+	Cost         float64 `json:"cost"`
+}
+
+// NodeMix is the node set of packing the recommended workloads onto nodes, the platform and total cost are added as rows
+type NodeMix struct {
+	Nodes             []NodeGroup `json:"nodes"`
+	NodeCost          float64     `json:"nodeCost"`
+	PlatformCost      float64     `json:"platformCost"`
+	TotalCost         float64     `json:"totalCost"`
+	UnschedulablePods int         `json:"unschedulablePods"`
+}
+
+func (m NodeMix) Header() []string {
+	return []string{"InstanceType", "Cpu", "Mem", "Count", "HourlyPrice", "Cost"}
+}
+
+func (m NodeMix) Rows() [][]string {
+	var rows [][]string
+	count := 0
+	for _, n := range m.Nodes {
+		count += n.Count
+		rows = append(rows, []string{n.InstanceType, formatFloat(n.Cpu), formatFloat(n.Mem), strconv.Itoa(n.Count), formatFloat(n.HourlyPrice), formatFloat(n.Cost)})
+	}
+	rows = append(rows, []string{"platform", "", "", "", "", formatFloat(m.PlatformCost)})
+	return append(rows, []string{"total", "", "", strconv.Itoa(count), "", formatFloat(m.TotalCost)})
+}
+
+// SavingsOpportunity is the savings of a workload by the recommendation, ranked by the savings. The container resource is the one
+// drives the savings, its request, usage percentile and recommended value are cores for cpu and GB for memory
+type SavingsOpportunity struct {
+	Rank            int     `json:"rank"`
+	Kind            string  `json:"kind"`
+	Namespace       string  `json:"namespace"`
+	Name            string  `json:"name"`
+	CurrentCost     float64 `json:"currentCost"`
+	RecommendedCost float64 `json:"recommendedCost"`
+	Savings         float64 `json:"savings"`
+	SavingsRatio    float64 `json:"savingsRatio"`
+	Container       string  `json:"container"`
+	Resource        string  `json:"resource"`
+	Request         float64 `json:"request"`
+	Usage           float64 `json:"usage"`
+	Recommended     float64 `json:"recommended"`
+}
+
+type SavingsOpportunities []SavingsOpportunity
+
+func (s SavingsOpportunities) Header() []string {
+	return []string{"Rank", "Kind", "Namespace", "Name", "CurrentCost", "RecommendedCost", "Savings", "SavingsRatio", "Container", "Resource", "Request", "Usage", "Recommended"}
+}
+
+func (s SavingsOpportunities) Rows() [][]string {
+	var rows [][]string
+	for _, o := range s {
+		rows = append(rows, []string{strconv.Itoa(o.Rank), o.Kind, o.Namespace, o.Name, formatFloat(o.CurrentCost), formatFloat(o.RecommendedCost), formatFloat(o.Savings),
+			formatFloat(o.SavingsRatio), o.Container, o.Resource, formatFloat(o.Request), formatFloat(o.Usage), formatFloat(o.Recommended)})
+	}
+	return rows
+}
+
+// BatchWorkloadCost is the cost of a job or cronjob by the run time of its pods, PodHours is of the history window.
+// The monthly costs of a cronjob are by its schedule, of a job are at the rate of the history window
+type BatchWorkloadCost struct {
+	Kind                  string  `json:"kind"`
+	Namespace             string  `json:"namespace"`
+	Name                  string  `json:"name"`
+	Schedule              string  `json:"schedule"`
+	Runs                  int     `json:"runs"`
+	PodHours              float64 `json:"podHours"`
+	ServerfulCost         float64 `json:"serverfulCost"`
+	ServerlessCost        float64 `json:"serverlessCost"`
+	MonthlyServerfulCost  float64 `json:"monthlyServerfulCost"`
+	MonthlyServerlessCost float64 `json:"monthlyServerlessCost"`
+}
+
+// BatchWorkloadsCost is the costs of the batch workloads, the total is added as a row
+type BatchWorkloadsCost []BatchWorkloadCost
+
+func (b BatchWorkloadsCost) Header() []string {
+	return []string{"Kind", "Namespace", "Name", "Schedule", "Runs", "PodHours", "ServerfulCost", "ServerlessCost", "MonthlyServerfulCost", "MonthlyServerlessCost"}
+}
+
+func (b BatchWorkloadsCost) Rows() [][]string {
+	var rows [][]string
+	var total BatchWorkloadCost
+	for _, w := range b {
+		total.Runs += w.Runs
+		total.PodHours += w.PodHours
+		total.ServerfulCost += w.ServerfulCost
+		total.ServerlessCost += w.ServerlessCost
+		total.MonthlyServerfulCost += w.MonthlyServerfulCost
+		total.MonthlyServerlessCost += w.MonthlyServerlessCost
+		rows = append(rows, []string{w.Kind, w.Namespace, w.Name, w.Schedule, strconv.Itoa(w.Runs), formatFloat(w.PodHours), formatFloat(w.ServerfulCost),
+			formatFloat(w.ServerlessCost), formatFloat(w.MonthlyServerfulCost), formatFloat(w.MonthlyServerlessCost)})
+	}
+	return append(rows, []string{"total", "", "", "", strconv.Itoa(total.Runs), formatFloat(total.PodHours), formatFloat(total.ServerfulCost),
+		formatFloat(total.ServerlessCost), formatFloat(total.MonthlyServerfulCost), formatFloat(total.MonthlyServerlessCost)})
+}
+
+// WorkloadCost is the serverless cost of a workload by its original spec and by its recommended spec.
+// StaticCost is the original cost by the replicas at analysis time, the other costs are by the average replicas over the history if known
+type WorkloadCost struct {
+	Kind            string  `json:"kind"`
+	Namespace       string  `json:"namespace"`
+	Name            string  `json:"name"`
+	Replicas        uint64  `json:"replicas"`
+	AverageReplicas float64 `json:"averageReplicas"`
+	StaticCost      float64 `json:"staticCost"`
+	OriginalCost    float64 `json:"originalCost"`
+	RecommendedCost float64 `json:"recommendedCost"`
+}
+
+// CostGroup is the total original and recommended cost of a group of workloads
+type CostGroup struct {
+	Name            string  `json:"name"`
+	OriginalCost    float64 `json:"originalCost"`
+	RecommendedCost float64 `json:"recommendedCost"`
+}
+
+type CostBreakdown []WorkloadCost
+
+func (b CostBreakdown) Header() []string {
+	return []string{"Kind", "Namespace", "Name", "Replicas", "AverageReplicas", "StaticCost", "OriginalCost", "RecommendedCost"}
+}
+
+func (b CostBreakdown) Rows() [][]string {
+	var rows [][]string
+	for _, w := range b {
+		rows = append(rows, []string{w.Kind, w.Namespace, w.Name, strconv.FormatUint(w.Replicas, 10), formatFloat(w.AverageReplicas), formatFloat(w.StaticCost), formatFloat(w.OriginalCost), formatFloat(w.RecommendedCost)})
+	}
+	return rows
+}
+
+// ByKind returns the costs grouped by the workload kind, sorted by the original cost descending
+func (b CostBreakdown) ByKind() []CostGroup {
+	return b.groupBy(func(w WorkloadCost) string { return w.Kind })
+}
+
+// ByNamespace returns the costs grouped by the namespace, sorted by the original cost descending
+func (b CostBreakdown) ByNamespace() []CostGroup {
+	return b.groupBy(func(w WorkloadCost) string { return w.Namespace })
+}
+
+func (b CostBreakdown) groupBy(key func(w WorkloadCost) string) []CostGroup {
+	groups := make(map[string]*CostGroup)
+	for _, w := range b {
+		name := key(w)
+		group, ok := groups[name]
+		if !ok {
+			group = &CostGroup{Name: name}
+			groups[name] = group
+		}
+		group.OriginalCost += w.OriginalCost
+		group.RecommendedCost += w.RecommendedCost
+	}
+	results := make([]CostGroup, 0, len(groups))
+	for _, group := range groups {
+		results = append(results, *group)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].OriginalCost != results[j].OriginalCost {
+			return results[i].OriginalCost > results[j].OriginalCost
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+func formatFloat(a float64) string {
+	return fmt.Sprintf("%.5f", a)
+}
@@ -20,6 +20,10 @@ var (
 	nodeCpuCostGv   *prometheus.GaugeVec
 	nodeRamCostGv   *prometheus.GaugeVec
 	nodeTotalCostGv *prometheus.GaugeVec
+	// os license cost
+	nodeLicenseCostGv *prometheus.GaugeVec
+	// cost of the reserved resources
+	nodeOverheadCostGv *prometheus.GaugeVec
 
 	//containerRamAllocGv *prometheus.GaugeVec
 	//containerCpuAllocGv *prometheus.GaugeVec
@@ -42,6 +46,16 @@ func init() {
 			Help: "node_total_hourly_cost total node cost per hour",
 		}, []string{"instance", "node", "instance_type", "region", "provider_id"})
 
+		nodeLicenseCostGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "node_license_hourly_cost",
+			Help: "node_license_hourly_cost operating system license cost per hour of the node, it is included in node_total_hourly_cost",
+		}, []string{"instance", "node", "instance_type", "region", "provider_id", "os"})
+
+		nodeOverheadCostGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "node_overhead_hourly_cost",
+			Help: "node_overhead_hourly_cost cost per hour of the node resources reserved for system, kubelet and eviction, it is included in node_total_hourly_cost. It is not exported in the allocatable node overhead mode, the pods are charged for it",
+		}, []string{"instance", "node", "instance_type", "region", "provider_id"})
+
 		//containerCpuAllocGv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		//	Name: "container_cpu_allocation",
 		//	Help: "container_cpu_allocation of container CPU used in a minute",
@@ -52,7 +66,7 @@ func init() {
 		//	Help: "container_memory_allocation_bytes Bytes of container RAM used",
 		//}, []string{"namespace", "pod", "container", "instance", "node"})
 
-		prometheus.MustRegister(nodeCpuCostGv, nodeRamCostGv, nodeTotalCostGv)
+		prometheus.MustRegister(nodeCpuCostGv, nodeRamCostGv, nodeTotalCostGv, nodeLicenseCostGv, nodeOverheadCostGv)
 		//prometheus.MustRegister(containerCpuAllocGv, containerRamAllocGv)
 
 	})
@@ -62,9 +76,11 @@ func init() {
 type CostMetricEmitter struct {
 	costModel cloudcost.CostModel
 
-	nodeCpuCostGv   *prometheus.GaugeVec
-	nodeRamCostGv   *prometheus.GaugeVec
-	nodeTotalCostGv *prometheus.GaugeVec
+	nodeCpuCostGv      *prometheus.GaugeVec
+	nodeRamCostGv      *prometheus.GaugeVec
+	nodeTotalCostGv    *prometheus.GaugeVec
+	nodeLicenseCostGv  *prometheus.GaugeVec
+	nodeOverheadCostGv *prometheus.GaugeVec
 
 	//containerRamAllocGv *prometheus.GaugeVec
 	//containerCpuAllocGv *prometheus.GaugeVec
@@ -75,12 +91,14 @@ type CostMetricEmitter struct {
 
 func NewCostMetricEmitter(costModel cloudcost.CostModel, updateInterval time.Duration, stopCh <-chan struct{}) *CostMetricEmitter {
 	return &CostMetricEmitter{
-		costModel:       costModel,
-		updateInterval:  updateInterval,
-		stopCh:          stopCh,
-		nodeCpuCostGv:   nodeCpuCostGv,
-		nodeRamCostGv:   nodeRamCostGv,
-		nodeTotalCostGv: nodeTotalCostGv,
+		costModel:          costModel,
+		updateInterval:     updateInterval,
+		stopCh:             stopCh,
+		nodeCpuCostGv:      nodeCpuCostGv,
+		nodeRamCostGv:      nodeRamCostGv,
+		nodeTotalCostGv:    nodeTotalCostGv,
+		nodeLicenseCostGv:  nodeLicenseCostGv,
+		nodeOverheadCostGv: nodeOverheadCostGv,
 	}
 }
 
@@ -89,6 +107,8 @@ func (cme *CostMetricEmitter) Start() {
 	defer ticker.Stop()
 
 	nodesLastSeen := make(map[string]bool)
+	// os of the node license cost metric, key is label key of the node
+	nodesOS := make(map[string]string)
 	getKeyFromLabelStrings := func(labels ...string) string {
 		return strings.Join(labels, ",")
 	}
@@ -132,13 +152,34 @@ func (cme *CostMetricEmitter) Start() {
 			nodeType := node.InstanceType
 			nodeRegion := node.Region
 
-			totalCost := cpu*cpuCost + ramCost*ram
+			licenseCost, _ := strconv.ParseFloat(node.LicenseHourlyCost, 64)
+			if math.IsNaN(licenseCost) || math.IsInf(licenseCost, 0) {
+				licenseCost = 0
+			}
+
+			totalCost := cpu*cpuCost + ramCost*ram + licenseCost
+
+			overheadCost, _ := strconv.ParseFloat(node.OverheadHourlyCost, 64)
+			if math.IsNaN(overheadCost) || math.IsInf(overheadCost, 0) {
+				overheadCost = 0
+			}
 
 			cme.nodeCpuCostGv.WithLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID).Set(cpuCost)
 			cme.nodeRamCostGv.WithLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID).Set(ramCost)
 			cme.nodeTotalCostGv.WithLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID).Set(totalCost)
+			// the overhead is already in the pod costs if it is charged to the pods, exporting it would count it twice
+			if cfg.NodeOverheadChargedToPods() {
+				cme.nodeOverheadCostGv.DeleteLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID)
+			} else {
+				cme.nodeOverheadCostGv.WithLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID).Set(overheadCost)
+			}
 
 			labelKey := getKeyFromLabelStrings(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID)
+			if os, ok := nodesOS[labelKey]; ok && os != node.OS {
+				cme.nodeLicenseCostGv.DeleteLabelValues(append(getLabelStringsFromKey(labelKey), os)...)
+			}
+			cme.nodeLicenseCostGv.WithLabelValues(nodeName, nodeName, nodeType, nodeRegion, node.ProviderID, node.OS).Set(licenseCost)
+			nodesOS[labelKey] = node.OS
 			nodesLastSeen[labelKey] = true
 		}
 
@@ -158,6 +199,13 @@ func (cme *CostMetricEmitter) Start() {
 				if !ok {
 					klog.Errorf("Failed to remove ramcost, labelString: %v", labelString)
 				}
+				// the overhead cost is not exported in the allocatable node overhead mode
+				cme.nodeOverheadCostGv.DeleteLabelValues(labels...)
+				ok = cme.nodeLicenseCostGv.DeleteLabelValues(append(labels, nodesOS[labelString])...)
+				if !ok {
+					klog.Errorf("Failed to remove licensecost, labelString: %v", labelString)
+				}
+				delete(nodesOS, labelString)
 				delete(nodesLastSeen, labelString)
 			} else {
 				// reset to false to be used in next loop, if node still exists, it will be set to true
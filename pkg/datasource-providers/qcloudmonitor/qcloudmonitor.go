@@ -10,7 +10,6 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/klog/v2"
 
 	"github.com/gocrane/crane/pkg/common"
@@ -38,7 +37,25 @@ type qcloudmonitor struct {
 // NewProvider return a QCloud Monitor data provider
 func NewProvider(config *datasource.QCloudMonitorConfig) (datasource.Interface, error) {
 	cm := &qcloudmonitor{}
-	cred := credential.NewQCloudCredential(config.ClusterId, config.AppId, config.SecretId, config.SecretKey, 1*time.Hour)
+	cred, err := credential.NewCredentialFromConfig(credential.Config{
+		ClusterId:           config.ClusterId,
+		AppId:               config.AppId,
+		SecretId:            config.SecretId,
+		SecretKey:           config.SecretKey,
+		Source:              config.Source,
+		SecretDir:           config.SecretDir,
+		RoleArn:             config.RoleArn,
+		RoleSessionName:     config.RoleSessionName,
+		RoleDurationSeconds: config.RoleDurationSeconds,
+		InstanceRoleName:    config.InstanceRoleName,
+		MetadataEndpoint:    config.MetadataEndpoint,
+		Region:              config.Region,
+		DomainSuffix:        config.DomainSuffix,
+		Scheme:              config.Scheme,
+	})
+	if err != nil {
+		return nil, err
+	}
 	qcp := qcloud.QCloudClientProfile{
 		Region:          config.Region,
 		DomainSuffix:    config.DomainSuffix,
@@ -47,13 +64,14 @@ func NewProvider(config *datasource.QCloudMonitorConfig) (datasource.Interface,
 		DefaultLanguage: config.DefaultLanguage,
 		DefaultTimeout:  time.Duration(config.DefaultTimeoutSeconds) * time.Second,
 		Debug:           config.Debug,
+		Endpoint:        config.Endpoint,
 	}
 	klog.Infof("%+v", qcp)
 	qclouClientConf := &qcloud.QCloudClientConfig{
 		DefaultRetryCnt:     qconsts.MAXRETRY,
 		Credential:          cred,
 		QCloudClientProfile: qcp,
-		RateLimiter:         flowcontrol.NewTokenBucketRateLimiter(10, 20),
+		Governor:            qcloud.SharedRequestGovernor(),
 	}
 	cm.cmClient = qmonitor.NewQCloudMonitorClient(qclouClientConf)
 	cm.step = DefaultStep
@@ -0,0 +1,87 @@
+package qcloudmonitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/fake"
+	"github.com/gocrane/fadvisor/pkg/cloudsdk/qcloud/qmonitor"
+	"github.com/gocrane/fadvisor/pkg/consts"
+	"github.com/gocrane/fadvisor/pkg/datasource"
+	"github.com/gocrane/fadvisor/pkg/metricquery"
+)
+
+func containerCpuMetric() *metricquery.Metric {
+	return &metricquery.Metric{
+		Type:       metricquery.ContainerMetricType,
+		MetricName: "cpu",
+		Container: &metricquery.ContainerNamerInfo{
+			Namespace:     "default",
+			WorkloadName:  "nginx",
+			Kind:          "Deployment",
+			ContainerName: "nginx",
+			Selector:      labels.SelectorFromSet(labels.Set{consts.LabelClusterId: "cls-fake"}),
+		},
+	}
+}
+
+func TestQCloudMonitorQuery(t *testing.T) {
+	server, err := fake.NewServerWithFixtures("testdata/fixtures.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	config := &datasource.QCloudMonitorConfig{}
+	config.ClusterId = "cls-fake"
+	config.SecretId = "fake-secret-id"
+	config.SecretKey = "fake-secret-key"
+	config.Region = "ap-guangzhou"
+	config.DefaultLimit = 100
+	config.DefaultTimeoutSeconds = 10
+	config.Scheme = "http"
+	config.Endpoint = server.Endpoint()
+	provider, err := NewProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qm := provider.(*qcloudmonitor)
+
+	start := time.Unix(1640966400, 0)
+	series, err := qm.query(context.TODO(), containerCpuMetric(), start, start.Add(2*time.Minute), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 1 || len(series[0].Samples) != 3 {
+		t.Fatalf("expect 1 series with 3 samples, got %+v", series)
+	}
+	if s := series[0].Samples[2]; s.Timestamp != 1640966520 || s.Value != 1.0 {
+		t.Errorf("unexpected sample %+v", s)
+	}
+
+	calls := server.Calls("DescribeStatisticData")
+	if len(calls) != 1 {
+		t.Fatalf("expect 1 DescribeStatisticData call, got %v", len(calls))
+	}
+	if calls[0].Params["Module"] != qmonitor.MetricsModule || calls[0].Params["Period"] != 60.0 {
+		t.Errorf("unexpected DescribeStatisticData params %+v", calls[0].Params)
+	}
+}
+
+func TestQCloudMonitorQueryError(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.ScriptError("DescribeStatisticData", "LimitExceeded", "too many series")
+
+	qm := &qcloudmonitor{
+		cmClient: qmonitor.NewQCloudMonitorClient(server.ClientConfig("ap-guangzhou")),
+		step:     DefaultStep,
+	}
+	start := time.Unix(1640966400, 0)
+	if _, err := qm.query(context.TODO(), containerCpuMetric(), start, start.Add(2*time.Minute), time.Minute); err == nil {
+		t.Errorf("expect error of DescribeStatisticData")
+	}
+}
@@ -55,12 +55,20 @@ type QCloudMonitorConfig struct {
 	ClientProfile `name:"clientProfile" value:"optional"`
 }
 
-// Credentials use user defined SecretId and SecretKey
+// Credentials use user defined SecretId and SecretKey, or other credential Source, see credential.Config
 type Credentials struct {
 	ClusterId string
 	AppId     string
 	SecretId  string
 	SecretKey string
+	// Source is static, env, file, sts or instancerole, default is static which use SecretId and SecretKey
+	Source              string
+	SecretDir           string
+	RoleArn             string
+	RoleSessionName     string
+	RoleDurationSeconds int64
+	InstanceRoleName    string
+	MetadataEndpoint    string
 }
 
 type ClientProfile struct {
@@ -71,6 +79,8 @@ type ClientProfile struct {
 	Region                string
 	DomainSuffix          string
 	Scheme                string
+	// Endpoint overrides the domain of all the cloud api services
+	Endpoint string
 }
 
 type DataSourceType string
@@ -115,6 +115,21 @@ const (
 
 	// DaemonSetReplicasExprTemplate replicas, param is namespace, name,common condition
 	DaemonSetReplicasExprTemplate = `label_replace(label_replace(max(kube_daemonset_status_number_ready{namespace="%s",daemonset="%s",%s}) without (instance, job),  "owner_name", "$1", "daemonset", "(.*)"),"owner_kind", "DaemonSet", "", "")`
+
+	// JobPodRuntimeExprTemplate is the run seconds of the completed pods of a job, param is namespace, common condition, namespace, common condition, name, namespace, common condition
+	JobPodRuntimeExprTemplate = `max(
+    (kube_pod_completion_time{namespace="%s",%s} - kube_pod_start_time{namespace="%s",%s})
+    * on (namespace, pod) group_left(owner_name)
+    max(kube_pod_owner{owner_kind="Job",owner_name="%s",namespace="%s",%s}) by (namespace, pod, owner_name)
+) by (namespace, pod, owner_name)`
+
+	// CronJobPodRuntimeExprTemplate is the run seconds of the completed pods of the jobs of a cronjob, the jobs are named by the cronjob and the schedule time,
+	// param is namespace, common condition, namespace, common condition, name, namespace, common condition
+	CronJobPodRuntimeExprTemplate = `max(
+    (kube_pod_completion_time{namespace="%s",%s} - kube_pod_start_time{namespace="%s",%s})
+    * on (namespace, pod) group_left(owner_name)
+    max(kube_pod_owner{owner_kind="Job",owner_name=~"%s-[0-9]+",namespace="%s",%s}) by (namespace, pod, owner_name)
+) by (namespace, pod, owner_name)`
 )
 
 var supportedResources = sets.NewString(v1.ResourceCPU.String(), v1.ResourceMemory.String())
@@ -229,6 +244,19 @@ func (b *builder) workloadQuery(metric *metricquery.Metric, behavior querybuilde
 		} else {
 			return nil, fmt.Errorf("metric type %v do not support workload kind %v", metric.Type, metric.Workload.Kind)
 		}
+	case consts.MetricWorkloadPodRuntime:
+		workloadkind := strings.ToLower(metric.Workload.Kind)
+		if workloadkind == "job" {
+			return promQuery(&metricquery.PrometheusQuery{
+				Query: fmt.Sprintf(JobPodRuntimeExprTemplate, metric.Workload.Namespace, clusterCond, metric.Workload.Namespace, clusterCond, metric.Workload.Name, metric.Workload.Namespace, clusterCond),
+			}), nil
+		} else if workloadkind == "cronjob" {
+			return promQuery(&metricquery.PrometheusQuery{
+				Query: fmt.Sprintf(CronJobPodRuntimeExprTemplate, metric.Workload.Namespace, clusterCond, metric.Workload.Namespace, clusterCond, metric.Workload.Name, metric.Workload.Namespace, clusterCond),
+			}), nil
+		} else {
+			return nil, fmt.Errorf("metric type %v do not support workload kind %v", metric.Type, metric.Workload.Kind)
+		}
 	default:
 		return nil, fmt.Errorf("metric type %v do not support resource metric %v. only support %v now", metric.Type, metric.MetricName, supportedResources.List())
 	}
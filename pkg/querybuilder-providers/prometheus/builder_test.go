@@ -7,6 +7,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 
+	"github.com/gocrane/fadvisor/pkg/consts"
 	"github.com/gocrane/fadvisor/pkg/metricquery"
 	"github.com/gocrane/fadvisor/pkg/querybuilder"
 )
@@ -146,6 +147,20 @@ func TestBuildQuery(t *testing.T) {
 			},
 			want: "irate(http_requests{}[3m])",
 		},
+		{
+			desc: "tc10-cronjob-pod-runtime",
+			metric: &metricquery.Metric{
+				MetricName: consts.MetricWorkloadPodRuntime,
+				Type:       metricquery.WorkloadMetricType,
+				Workload: &metricquery.WorkloadNamerInfo{
+					Namespace:  "default",
+					Name:       "backup",
+					Kind:       "CronJob",
+					APIVersion: "batch/v1",
+				},
+			},
+			want: fmt.Sprintf(CronJobPodRuntimeExprTemplate, "default", "", "default", "", "backup", "default", ""),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -39,6 +39,8 @@ type CloudPodSpec struct {
 	Serverless bool
 
 	QoSClass v1.PodQOSClass
+	// Usage is the resource usage of the pod, nil if unknown
+	Usage v1.ResourceList
 }
 
 type CloudNodeSpec struct {
@@ -51,6 +53,11 @@ type CloudNodeSpec struct {
 	ChargeType   string
 	Zone         string
 	Region       string
+	// OS is the operating system of the node, such as linux or windows
+	OS string
+	// Arch is the cpu architecture of the node, such as amd64 or arm64
+	Arch           string
+	InstanceFamily string
 	// virtual node or not
 	VirtualNode bool
 }
@@ -1,6 +1,8 @@
 package util
 
 import (
+	"strings"
+
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -34,6 +36,24 @@ func GetInstanceType(labels map[string]string) (string, bool) {
 	}
 }
 
+func GetArch(labels map[string]string) (string, bool) {
+	if _, ok := labels[v1.LabelArchStable]; ok {
+		return labels[v1.LabelArchStable], true
+	} else if _, ok := labels["beta.kubernetes.io/arch"]; ok {
+		return labels["beta.kubernetes.io/arch"], true
+	} else {
+		return "", false
+	}
+}
+
+// GetInstanceFamily return the family of the instance type, such as S5 of S5.LARGE8, or m5 of m5.large
+func GetInstanceFamily(instanceType string) string {
+	if i := strings.Index(instanceType, "."); i > 0 {
+		return instanceType[:i]
+	}
+	return instanceType
+}
+
 func GetOperatingSystem(labels map[string]string) (string, bool) {
 	if _, ok := labels[v1.LabelOSStable]; ok {
 		return labels[v1.LabelOSStable], true